@@ -11,6 +11,8 @@ genAccFrom(){
 }
 
 var_amount=0
+# schedules run 1 minute later
+var_execute_at=$(date -u -d '+1 minute' +%Y-%m-%dT%H:%M:%SZ)
 genAmount(){
     var_amount=$(($RANDOM%($max_amount-$min_amount+1)+$min_amount))
 }
@@ -28,8 +30,8 @@ for (( x=0; x<=10; x++ ))
 do
     genAcc
     genAmount
-    echo curl -X POST $domain -H 'Content-Type: application/json' -d '{"currency": "BRL","amount": '$var_amount',"account_id_to": "ACC-'$var_acc'"}'
-    #curl -X POST $domain -H 'Content-Type: application/json' -d '{"currency": "BRL","amount": '$var_amount',"account_id_to": "ACC-'$var_acc'"}'
+    echo curl -X POST $domain -H 'Content-Type: application/json' -d '{"account_from":{"account_id":"ACC-'$var_acc'"},"type_charge":"CREDIT","currency":"BRL","amount": '$var_amount',"execute_at":"'$var_execute_at'"}'
+    #curl -X POST $domain -H 'Content-Type: application/json' -d '{"account_from":{"account_id":"ACC-'$var_acc'"},"type_charge":"CREDIT","currency":"BRL","amount": '$var_amount',"execute_at":"'$var_execute_at'"}'
 done
 
 # -------------------transfer-------------------------
@@ -66,8 +68,8 @@ for (( x=0; x<=10; x++ ))
 do
     genAcc
     genAmount
-    echo curl -X POST $domain -H 'Content-Type: application/json' -d '{"currency": "BRL","amount":'$var_amount',"account_id_to":"ACC-'$var_acc'"}'
-    curl -X POST $domain -H 'Content-Type: application/json' -d '{"currency": "BRL","amount":'$var_amount',"account_id_to":"ACC-'$var_acc'"}'
+    echo curl -X POST $domain -H 'Content-Type: application/json' -d '{"account_from":{"account_id":"ACC-'$var_acc'"},"type_charge":"DEBIT","currency":"BRL","amount": '$var_amount',"execute_at":"'$var_execute_at'"}'
+    curl -X POST $domain -H 'Content-Type: application/json' -d '{"account_from":{"account_id":"ACC-'$var_acc'"},"type_charge":"DEBIT","currency":"BRL","amount": '$var_amount',"execute_at":"'$var_execute_at'"}'
 done
 
 
@@ -0,0 +1,19 @@
+-- Fx quotes (POST /quotes), a locked rate redeemable by one transfer until expires_at
+CREATE TABLE IF NOT EXISTS fx_quote (
+    id                  VARCHAR(100) PRIMARY KEY,
+    currency            VARCHAR(10) NOT NULL,
+    amount              NUMERIC NOT NULL,
+    currency_to         VARCHAR(10) NOT NULL,
+    rate                NUMERIC NOT NULL,
+    fee                 NUMERIC NOT NULL,
+    destination_amount  NUMERIC NOT NULL,
+    status              VARCHAR(20) NOT NULL,
+    created_at          TIMESTAMP NOT NULL,
+    expires_at          TIMESTAMP NOT NULL,
+    used_at             TIMESTAMP NULL,
+    transaction_id      VARCHAR(100) NULL
+);
+
+-- Fee and quote of the fx conversion of a transfer
+ALTER TABLE transfer_moviment ADD COLUMN IF NOT EXISTS fx_fee NUMERIC NULL;
+ALTER TABLE transfer_moviment ADD COLUMN IF NOT EXISTS quote_id VARCHAR(100) NULL;
@@ -0,0 +1,19 @@
+-- Events stored in the same transaction of transfer_moviment (EVENT_MODE=outbox), published by the relay
+CREATE TABLE IF NOT EXISTS outbox (
+    id                  SERIAL PRIMARY KEY,
+    topic               VARCHAR(255) NOT NULL,
+    event_type          VARCHAR(50) NOT NULL DEFAULT '',
+    key                 VARCHAR(255) NOT NULL,
+    trace_id            VARCHAR(255) NOT NULL DEFAULT '',
+    payload             BYTEA NOT NULL,
+    status              VARCHAR(20) NOT NULL,
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    last_error          VARCHAR(255) NOT NULL DEFAULT '',
+    created_at          TIMESTAMP NOT NULL,
+    sent_at             TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (status, id);
+
+-- the event_type header of the published event
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS event_type VARCHAR(50) NOT NULL DEFAULT '';
@@ -0,0 +1,39 @@
+-- Recurring transfers (standing orders), materialized by the scheduler into /add/transferEvent
+CREATE TABLE IF NOT EXISTS standing_order (
+    id                  SERIAL PRIMARY KEY,
+    payload             JSONB NOT NULL,
+    frequency           VARCHAR(20) NOT NULL,
+    interval            INTEGER NOT NULL DEFAULT 1,
+    start_at            TIMESTAMP NOT NULL,
+    end_at              TIMESTAMP NULL,
+    max_occurrences     INTEGER NOT NULL DEFAULT 0,
+    failure_policy      VARCHAR(20) NOT NULL,
+    occurrences         INTEGER NOT NULL DEFAULT 0,
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    next_execute_at     TIMESTAMP NOT NULL,
+    status              VARCHAR(20) NOT NULL,
+    last_error          TEXT NOT NULL DEFAULT '',
+    created_at          TIMESTAMP NOT NULL,
+    updated_at          TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_standing_order_due ON standing_order (status, next_execute_at);
+
+-- History of the occurrences (transfers done or skipped) of a standing order
+CREATE TABLE IF NOT EXISTS standing_order_occurrence (
+    id                      SERIAL PRIMARY KEY,
+    fk_standing_order_id    INTEGER NOT NULL REFERENCES standing_order(id),
+    sequence                INTEGER NOT NULL,
+    scheduled_at            TIMESTAMP NOT NULL,
+    status                  VARCHAR(20) NOT NULL,
+    attempts                INTEGER NOT NULL DEFAULT 0,
+    last_error              TEXT NOT NULL DEFAULT '',
+    fk_transfer_id          INTEGER NULL REFERENCES transfer_moviment(id),
+    executed_at             TIMESTAMP NOT NULL,
+    UNIQUE (fk_standing_order_id, sequence)
+);
+
+CREATE INDEX IF NOT EXISTS idx_standing_order_occurrence_transfer ON standing_order_occurrence (fk_transfer_id);
+
+-- The lease of a standing order claimed by the runner, claimed again once expired
+ALTER TABLE standing_order ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NULL;
@@ -0,0 +1,20 @@
+-- Amount above which a transfer needs a second user to approve it (maker-checker), 0 means no approval
+ALTER TABLE transfer_limit ADD COLUMN IF NOT EXISTS approval_threshold NUMERIC NOT NULL DEFAULT 0;
+
+-- Transfers awaiting the approval of a second user (checker), the maker is the user who submitted it
+CREATE TABLE IF NOT EXISTS transfer_approval (
+    id                  SERIAL PRIMARY KEY,
+    fk_transfer_id      INTEGER NOT NULL REFERENCES transfer_moviment(id),
+    transaction_id      VARCHAR(100) NOT NULL,
+    flow                VARCHAR(20) NOT NULL,
+    payload             JSONB NOT NULL,
+    maker               VARCHAR(100) NOT NULL DEFAULT '',
+    status              VARCHAR(20) NOT NULL,
+    checker             VARCHAR(100) NOT NULL DEFAULT '',
+    reason              VARCHAR(500) NOT NULL DEFAULT '',
+    decided_at          TIMESTAMP NULL,
+    created_at          TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transfer_approval_transfer ON transfer_approval (fk_transfer_id);
+CREATE INDEX IF NOT EXISTS idx_transfer_approval_status ON transfer_approval (status, created_at);
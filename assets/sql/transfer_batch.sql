@@ -0,0 +1,26 @@
+-- Batch of transfers (POST /transfers/batch) and the result of each item
+CREATE TABLE IF NOT EXISTS transfer_batch (
+    id                  SERIAL PRIMARY KEY,
+    status              VARCHAR(20) NOT NULL,
+    total               INTEGER NOT NULL,
+    succeeded           INTEGER NOT NULL DEFAULT 0,
+    held                INTEGER NOT NULL DEFAULT 0,
+    failed              INTEGER NOT NULL DEFAULT 0,
+    created_at          TIMESTAMP NOT NULL,
+    finished_at         TIMESTAMP NULL
+);
+
+ALTER TABLE transfer_batch ADD COLUMN IF NOT EXISTS held INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS transfer_batch_item (
+    id                  SERIAL PRIMARY KEY,
+    fk_batch_id         INTEGER NOT NULL REFERENCES transfer_batch(id),
+    sequence            INTEGER NOT NULL,
+    payload             JSONB NOT NULL,
+    status              VARCHAR(20) NOT NULL,
+    fk_transfer_id      INTEGER NULL REFERENCES transfer_moviment(id),
+    transaction_id      VARCHAR(255) NULL,
+    error_code          VARCHAR(50) NOT NULL DEFAULT '',
+    error_message       TEXT NOT NULL DEFAULT '',
+    UNIQUE (fk_batch_id, sequence)
+);
@@ -0,0 +1,7 @@
+-- Completion events already applied (dedupe of the consumer)
+CREATE TABLE IF NOT EXISTS transfer_event_processed (
+    transaction_id      VARCHAR(100) NOT NULL,
+    event_type          VARCHAR(50) NOT NULL,
+    processed_at        TIMESTAMP NOT NULL,
+    PRIMARY KEY (transaction_id, event_type)
+);
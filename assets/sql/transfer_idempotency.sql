@@ -0,0 +1,13 @@
+-- Idempotency keys of the POST transfer endpoints
+-- The key is reserved and the response stored in the same transaction of transfer_moviment
+CREATE TABLE IF NOT EXISTS transfer_idempotency (
+    idempotency_key     VARCHAR(255) NOT NULL PRIMARY KEY,
+    request_hash        VARCHAR(64) NOT NULL,
+    response            JSONB NULL,
+    created_at          TIMESTAMP NOT NULL
+);
+
+-- The keys of the scheduler and of the standing orders moved under the internal prefix
+UPDATE transfer_idempotency SET idempotency_key = 'internal:' || idempotency_key
+ WHERE request_hash = 'schedule'
+   AND (idempotency_key LIKE 'schedule-%' OR idempotency_key LIKE 'standing-order-%');
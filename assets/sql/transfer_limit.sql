@@ -0,0 +1,23 @@
+-- Limits of the outgoing transfers (transfer and debit) per account or tenant and currency, 0 means no limit
+CREATE TABLE IF NOT EXISTS transfer_limit (
+    id                  SERIAL PRIMARY KEY,
+    scope               VARCHAR(20) NOT NULL,
+    scope_id            VARCHAR(100) NOT NULL,
+    currency            VARCHAR(10) NOT NULL,
+    max_per_transaction NUMERIC NOT NULL DEFAULT 0,
+    daily_amount        NUMERIC NOT NULL DEFAULT 0,
+    monthly_amount      NUMERIC NOT NULL DEFAULT 0,
+    daily_count         INTEGER NOT NULL DEFAULT 0,
+    monthly_count       INTEGER NOT NULL DEFAULT 0,
+    updated_at          TIMESTAMP NOT NULL,
+    UNIQUE (scope, scope_id, currency)
+);
+
+-- Usage of the limits (outgoing movements of an account since the start of the month)
+CREATE INDEX IF NOT EXISTS idx_transfer_moviment_limit ON transfer_moviment (fk_account_id_from, currency, transfer_at);
+
+-- The usage excludes the credits by type_charge, the credits stored before it was set by the service
+UPDATE transfer_moviment SET type_charge = 'CREDIT'
+ WHERE fk_account_id_from = fk_account_id_to
+   AND status IN ('CREDIT_EVENT_CREATED', 'CREDIT_DONE')
+   AND type_charge IS DISTINCT FROM 'CREDIT';
@@ -0,0 +1,5 @@
+-- Fx conversion applied to the credit leg (null when both legs have the same currency)
+ALTER TABLE transfer_moviment ADD COLUMN IF NOT EXISTS fx_rate NUMERIC NULL;
+ALTER TABLE transfer_moviment ADD COLUMN IF NOT EXISTS currency_to VARCHAR(10) NULL;
+ALTER TABLE transfer_moviment ADD COLUMN IF NOT EXISTS amount_to NUMERIC NULL;
+ALTER TABLE transfer_moviment ADD COLUMN IF NOT EXISTS fx_rate_at TIMESTAMP NULL;
@@ -0,0 +1,4 @@
+-- Keyset pagination of the transfer search (GET /transfers)
+CREATE INDEX IF NOT EXISTS idx_transfer_moviment_transfer_at ON transfer_moviment (transfer_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_transfer_moviment_account_from ON transfer_moviment (fk_account_id_from, transfer_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_transfer_moviment_account_to ON transfer_moviment (fk_account_id_to, transfer_at DESC, id DESC);
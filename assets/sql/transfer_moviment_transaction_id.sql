@@ -0,0 +1,2 @@
+-- Lookup of a transfer by transaction_id (GET /transfer/transaction/{transaction_id} and the completion events)
+CREATE INDEX IF NOT EXISTS idx_transfer_moviment_transaction_id ON transfer_moviment (transaction_id);
@@ -0,0 +1,17 @@
+-- Transfers held (PENDING_REVIEW) by the risk rules, decided by a reviewer or expired at expires_at
+CREATE TABLE IF NOT EXISTS transfer_review (
+    id                  SERIAL PRIMARY KEY,
+    fk_transfer_id      INTEGER NOT NULL REFERENCES transfer_moviment(id),
+    transaction_id      VARCHAR(100) NOT NULL,
+    flow                VARCHAR(20) NOT NULL,
+    payload             JSONB NOT NULL,
+    reasons             JSONB NOT NULL,
+    status              VARCHAR(20) NOT NULL,
+    reviewer            VARCHAR(100) NOT NULL DEFAULT '',
+    reason              VARCHAR(500) NOT NULL DEFAULT '',
+    expires_at          TIMESTAMP NOT NULL,
+    decided_at          TIMESTAMP NULL,
+    created_at          TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_review_status ON transfer_review (status, expires_at);
@@ -0,0 +1,16 @@
+-- Decisions of the risk rules per transfer (transaction_id), kept also for the denied transfers
+CREATE TABLE IF NOT EXISTS transfer_risk_decision (
+    id                  SERIAL PRIMARY KEY,
+    transaction_id      VARCHAR(100) NOT NULL,
+    operation           VARCHAR(20) NOT NULL,
+    account_from        VARCHAR(100) NOT NULL,
+    account_to          VARCHAR(100) NOT NULL DEFAULT '',
+    currency            VARCHAR(10) NOT NULL,
+    amount              NUMERIC NOT NULL,
+    decision            VARCHAR(20) NOT NULL,
+    reasons             JSONB NOT NULL,
+    created_at          TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_risk_decision_transaction_id ON transfer_risk_decision (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transfer_risk_decision_decision ON transfer_risk_decision (decision, created_at);
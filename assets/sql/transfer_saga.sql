@@ -0,0 +1,27 @@
+-- Saga of the REST transfer (debit on go-debit then credit on go-credit)
+-- payload keeps the transfer used to rebuild the steps on recovery
+CREATE TABLE IF NOT EXISTS transfer_saga (
+    id                  SERIAL PRIMARY KEY,
+    transaction_id      VARCHAR(100) NULL,
+    type                VARCHAR(50) NOT NULL,
+    status              VARCHAR(50) NOT NULL,
+    payload             JSONB NOT NULL,
+    created_at          TIMESTAMP NOT NULL,
+    updated_at          TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_saga_status ON transfer_saga (status, updated_at);
+
+-- Each execution or compensation of a saga step
+CREATE TABLE IF NOT EXISTS transfer_saga_step (
+    id                  SERIAL PRIMARY KEY,
+    fk_saga_id          INTEGER NOT NULL REFERENCES transfer_saga(id),
+    name                VARCHAR(50) NOT NULL,
+    action              VARCHAR(20) NOT NULL,
+    status              VARCHAR(20) NOT NULL,
+    error               VARCHAR(255) NOT NULL DEFAULT '',
+    created_at          TIMESTAMP NOT NULL,
+    updated_at          TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_saga_step_saga ON transfer_saga_step (fk_saga_id);
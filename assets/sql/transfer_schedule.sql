@@ -0,0 +1,18 @@
+-- Future-dated credits, debits and transfers, executed by the scheduler at execute_at
+CREATE TABLE IF NOT EXISTS transfer_schedule (
+    id                  SERIAL PRIMARY KEY,
+    type                VARCHAR(20) NOT NULL,
+    payload             JSONB NOT NULL,
+    execute_at          TIMESTAMP NOT NULL,
+    status              VARCHAR(20) NOT NULL,
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    last_error          TEXT NOT NULL DEFAULT '',
+    fk_transfer_id      INTEGER NULL REFERENCES transfer_moviment(id),
+    created_at          TIMESTAMP NOT NULL,
+    updated_at          TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_schedule_due ON transfer_schedule (status, execute_at);
+
+-- The lease of a schedule claimed by the scheduler, claimed again once expired
+ALTER TABLE transfer_schedule ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NULL;
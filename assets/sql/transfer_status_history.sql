@@ -0,0 +1,17 @@
+-- Every status transition of transfer_moviment (status_from is empty on creation)
+CREATE TABLE IF NOT EXISTS transfer_status_history (
+    id                  SERIAL PRIMARY KEY,
+    fk_transfer_id      INTEGER NOT NULL REFERENCES transfer_moviment(id),
+    status_from         VARCHAR(50) NOT NULL DEFAULT '',
+    status_to           VARCHAR(50) NOT NULL,
+    reason              VARCHAR(255) NOT NULL DEFAULT '',
+    changed_at          TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_status_history_transfer ON transfer_status_history (fk_transfer_id);
+
+-- The transitions depend on type_charge, the debits stored before it was set by the service
+UPDATE transfer_moviment SET type_charge = 'DEBIT'
+ WHERE fk_account_id_from = fk_account_id_to
+   AND status IN ('DEBIT_EVENT_CREATED', 'DEBIT_DONE')
+   AND type_charge IS DISTINCT FROM 'DEBIT';
@@ -1,93 +1,218 @@
-package main
-
-import(
-	"time"
-	"context"
-	
-	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
-
-	"github.com/go-fund-transfer/internal/infra/configuration"
-	"github.com/go-fund-transfer/internal/core/model"
-	"github.com/go-fund-transfer/internal/core/service"
-	"github.com/go-fund-transfer/internal/infra/server"
-	"github.com/go-fund-transfer/internal/adapter/api"
-	"github.com/go-fund-transfer/internal/adapter/database"
-	"github.com/go-fund-transfer/internal/adapter/event"
-	go_core_pg "github.com/eliezerraj/go-core/database/pg"  
-)
-
-var(
-	logLevel = 	zerolog.InfoLevel // zerolog.InfoLevel zerolog.DebugLevel
-	appServer	model.AppServer
-	databaseConfig go_core_pg.DatabaseConfig
-	databasePGServer go_core_pg.DatabasePGServer
-	childLogger = log.With().Str("component","go-fund-transfer").Str("package", "main").Logger()
-)
-
-// About initialize the enviroment var
-func init(){
-	childLogger.Info().Str("func","init").Send()
-
-	zerolog.SetGlobalLevel(logLevel)
-
-	infoPod, server := configuration.GetInfoPod()
-	configOTEL 		:= configuration.GetOtelEnv()
-	databaseConfig 	:= configuration.GetDatabaseEnv()
-	apiService 	:= configuration.GetEndpointEnv() 
-	kafkaConfigurations, topics := configuration.GetKafkaEnv() 
-
-	appServer.InfoPod = &infoPod
-	appServer.Server = &server
-	appServer.ConfigOTEL = &configOTEL
-	appServer.DatabaseConfig = &databaseConfig
-	appServer.ApiService = apiService
-	appServer.KafkaConfigurations = &kafkaConfigurations
-	appServer.Topics = topics
-}
-
-// About main
-func main (){
-	childLogger.Info().Str("func","main").Interface("appServer",appServer).Send()
-
-	ctx, cancel := context.WithTimeout(	context.Background(), 
-										time.Duration( appServer.Server.ReadTimeout ) * time.Second)
-	defer cancel()
-
-	// Open Database
-	count := 1
-	var err error
-	for {
-		databasePGServer, err = databasePGServer.NewDatabasePGServer(ctx, *appServer.DatabaseConfig)
-		if err != nil {
-			if count < 3 {
-				childLogger.Error().Err(err).Msg("error open database... trying again !!")
-			} else {
-				childLogger.Error().Err(err).Msg("fatal error open Database aborting")
-				panic(err)
-			}
-			time.Sleep(3 * time.Second) //backoff
-			count = count + 1
-			continue
-		}
-		break
-	}
-
-	// Database
-	database := database.NewWorkerRepository(&databasePGServer)
-
-	// Kafka
-	workerEvent, err := event.NewWorkerEventTX(ctx, appServer.Topics, appServer.KafkaConfigurations)
-	if err != nil {
-		childLogger.Error().Err(err).Send()
-		panic(err)
-	}
-	
-	// wire
-	workerService := service.NewWorkerService(database, appServer.ApiService, workerEvent)
-	httpRouters := api.NewHttpRouters(workerService)
-	httpServer := server.NewHttpAppServer(appServer.Server)
-
-	// start server
-	httpServer.StartHttpAppServer(ctx, &httpRouters, &appServer)
+package main
+
+import(
+	"time"
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/go-fund-transfer/internal/infra/configuration"
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/service"
+	"github.com/go-fund-transfer/internal/infra/server"
+	"github.com/go-fund-transfer/internal/adapter/api"
+	"github.com/go-fund-transfer/internal/adapter/account"
+	"github.com/go-fund-transfer/internal/adapter/database"
+	"github.com/go-fund-transfer/internal/adapter/event"
+	"github.com/go-fund-transfer/internal/adapter/fx"
+	"github.com/go-fund-transfer/internal/adapter/risk"
+	go_core_pg "github.com/eliezerraj/go-core/database/pg"  
+)
+
+var(
+	logLevel = 	zerolog.InfoLevel // zerolog.InfoLevel zerolog.DebugLevel
+	appServer	model.AppServer
+	databaseConfig go_core_pg.DatabaseConfig
+	databasePGServer go_core_pg.DatabasePGServer
+	childLogger = log.With().Str("component","go-fund-transfer").Str("package", "main").Logger()
+)
+
+// About initialize the enviroment var
+func init(){
+	childLogger.Info().Str("func","init").Send()
+
+	zerolog.SetGlobalLevel(logLevel)
+
+	infoPod, server := configuration.GetInfoPod()
+	configOTEL 		:= configuration.GetOtelEnv()
+	databaseConfig 	:= configuration.GetDatabaseEnv()
+	endpoints 	:= configuration.GetEndpointEnv() 
+	kafkaConfigurations, eventRouting := configuration.GetKafkaEnv() 
+	sagaConfig := configuration.GetSagaEnv()
+	outboxConfig := configuration.GetOutboxEnv()
+	consumerConfig := configuration.GetConsumerEnv()
+	schedulerConfig := configuration.GetSchedulerEnv()
+	batchConfig := configuration.GetBatchEnv()
+	fxConfig := configuration.GetFxEnv()
+	riskConfig := configuration.GetRiskEnv()
+	reviewConfig := configuration.GetReviewEnv()
+	approvalConfig := configuration.GetApprovalEnv()
+	identityConfig := configuration.GetIdentityEnv()
+
+	appServer.InfoPod = &infoPod
+	appServer.Server = &server
+	appServer.ConfigOTEL = &configOTEL
+	appServer.DatabaseConfig = &databaseConfig
+	appServer.Endpoints = endpoints
+	appServer.KafkaConfigurations = &kafkaConfigurations
+	appServer.EventRouting = eventRouting
+	appServer.SagaConfig = &sagaConfig
+	appServer.OutboxConfig = &outboxConfig
+	appServer.ConsumerConfig = &consumerConfig
+	appServer.SchedulerConfig = &schedulerConfig
+	appServer.BatchConfig = &batchConfig
+	appServer.FxConfig = &fxConfig
+	appServer.RiskConfig = &riskConfig
+	appServer.ReviewConfig = &reviewConfig
+	appServer.ApprovalConfig = &approvalConfig
+	appServer.IdentityConfig = &identityConfig
+}
+
+// About main
+func main (){
+	childLogger.Info().Str("func","main").Interface("appServer",appServer).Send()
+
+	ctx, cancel := context.WithTimeout(	context.Background(), 
+										time.Duration( appServer.Server.ReadTimeout ) * time.Second)
+	defer cancel()
+
+	// Open Database
+	count := 1
+	var err error
+	for {
+		databasePGServer, err = databasePGServer.NewDatabasePGServer(ctx, *appServer.DatabaseConfig)
+		if err != nil {
+			if count < 3 {
+				childLogger.Error().Err(err).Msg("error open database... trying again !!")
+			} else {
+				childLogger.Error().Err(err).Msg("fatal error open Database aborting")
+				panic(err)
+			}
+			time.Sleep(3 * time.Second) //backoff
+			count = count + 1
+			continue
+		}
+		break
+	}
+
+	// Database
+	database := database.NewWorkerRepository(&databasePGServer)
+
+	// Worker settings, a zero interval or batch would panic or spin the workers
+	err = appServer.SagaConfig.Validate()
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		panic(err)
+	}
+	err = appServer.OutboxConfig.Validate()
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		panic(err)
+	}
+	err = appServer.SchedulerConfig.Validate()
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		panic(err)
+	}
+	err = appServer.ReviewConfig.Validate()
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		panic(err)
+	}
+	err = appServer.ConsumerConfig.Validate()
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		panic(err)
+	}
+
+	// Identity, the user headers are trusted only when signed by the gateway
+	err = appServer.IdentityConfig.Validate()
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		panic(err)
+	}
+
+	// Event routing, every published event type needs a topic
+	err = appServer.EventRouting.Validate()
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		panic(err)
+	}
+
+	// Kafka, the outbox mode does not use kafka transactions. The memory publisher runs without kafka (local)
+	var eventPublisher port.EventPublisher
+	switch {
+	case appServer.OutboxConfig.Publisher == "memory":
+		eventPublisher = event.NewMemoryPublisher()
+	case appServer.OutboxConfig.Enabled:
+		eventPublisher, err = event.NewWorkerEvent(ctx, appServer.KafkaConfigurations)
+	default:
+		eventPublisher, err = event.NewWorkerEventTX(ctx, appServer.KafkaConfigurations)
+	}
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		panic(err)
+	}
+	
+	// Fx rates
+	rateProvider, err := fx.NewStaticRateProvider(appServer.FxConfig.RateFile)
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		panic(err)
+	}
+
+	// Risk rules
+	riskRules, err := risk.LoadRuleFile(appServer.RiskConfig.RuleFile)
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		panic(err)
+	}
+
+	// Account services (go-account, go-debit, go-credit)
+	accountClient, err := account.NewAccountClient(appServer.Endpoints)
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		panic(err)
+	}
+
+	// wire
+	workerService := service.NewWorkerService(service.NewRepositories(database), accountClient, eventPublisher, appServer.EventRouting, appServer.OutboxConfig, appServer.BatchConfig, rateProvider, appServer.FxConfig, riskRules, appServer.ReviewConfig, appServer.ApprovalConfig, time.Duration(appServer.Server.CtxTimeout) * time.Second)
+	httpRouters := api.NewHttpRouters(workerService)
+
+	// resume the sagas left unfinished (ex: crash)
+	go workerService.SagaRecoveryWorker(context.Background(), appServer.SagaConfig)
+
+	// execute the due schedules
+	go workerService.SchedulerWorker(context.Background(), appServer.SchedulerConfig)
+
+	// expire the reviews left pending
+	go workerService.ReviewExpiryWorker(context.Background(), appServer.ReviewConfig)
+
+	// relay the outbox events
+	if appServer.OutboxConfig.Enabled {
+		outboxRelay := service.NewOutboxRelay(database, database, eventPublisher, appServer.OutboxConfig)
+		go outboxRelay.OutboxRelayWorker(context.Background())
+	}
+
+	httpServer := server.NewHttpAppServer(appServer.Server)
+
+	// consume the completion events (credit done, debit done, transfer failed)
+	if appServer.ConsumerConfig.Enabled {
+		consumerEvent, err := event.NewConsumerEvent(ctx, appServer.ConsumerConfig, appServer.KafkaConfigurations)
+		if err != nil {
+			childLogger.Error().Err(err).Send()
+			panic(err)
+		}
+		ctxConsumer, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go consumerEvent.Consume(ctxConsumer, workerService.ApplyTransferEvent)
+	}
+
+	// start server
+	httpServer.StartHttpAppServer(ctx, &httpRouters, &appServer)
 }
\ No newline at end of file
@@ -0,0 +1,287 @@
+// About a fake of go-account, go-debit and go-credit over httptest, for the integration tests
+// of the account client and of the use cases. The services answer like the real ones until an
+// answer is forced with Respond, Delay or Hangup
+package accounttest
+
+import (
+	"sync"
+	"time"
+	"net/http"
+	"net/http/httptest"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/adapter/account"
+
+	"github.com/gorilla/mux"
+)
+
+// About the services of the fake
+const (
+	ServiceAccount	= "go-account"
+	ServiceDebit	= "go-debit"
+	ServiceCredit	= "go-credit"
+)
+
+// About the api id checked by the fake, a request without it is answered 403
+const ApiID = "accounttest"
+
+// About an answer forced for a service
+type response struct {
+	status		int
+	body		string
+	delay		time.Duration
+	hangup		bool
+}
+
+// About the fake services
+type Server struct {
+	mu			sync.Mutex
+	server		*httptest.Server
+	accounts	map[string]model.Account
+	responses	map[string]response
+	debits		[]model.AccountStatement
+	credits		[]model.AccountStatement
+	idempotent	map[string]model.AccountStatement
+	calls		map[string]int
+	sequence	int
+}
+
+// About start the fake, Close stops it
+func NewServer() *Server {
+	s := &Server{	accounts: map[string]model.Account{},
+					responses: map[string]response{},
+					idempotent: map[string]model.AccountStatement{},
+					calls: map[string]int{} }
+
+	router := mux.NewRouter()
+	router.HandleFunc("/account/{id}", s.getAccount).Methods(http.MethodGet)
+	router.HandleFunc("/debit", s.postStatement(ServiceDebit, "DEBIT")).Methods(http.MethodPost)
+	router.HandleFunc("/credit", s.postStatement(ServiceCredit, "CREDIT")).Methods(http.MethodPost)
+	s.server = httptest.NewServer(router)
+
+	return s
+}
+
+// About stop the fake
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// About the endpoints of the three services keyed by logical name (account, debit, credit), they retry
+// with the default policy and a short backoff, the breaker is disabled and the bulkhead unlimited
+func (s *Server) Endpoints() map[string]model.ApiService {
+	retry := model.RetryConfig{ Max: model.DefaultRetryMax, Backoff: 1, BackoffMax: 10, Status: model.DefaultRetryStatus }
+	return map[string]model.ApiService{
+		model.EndpointAccount: { Name: ServiceAccount, Url: s.server.URL + "/account", Method: http.MethodGet, Header_x_apigw_api_id: ApiID, Timeout: model.DefaultEndpointTimeout, Retry: retry },
+		model.EndpointDebit: { Name: ServiceDebit, Url: s.server.URL + "/debit", Method: http.MethodPost, Header_x_apigw_api_id: ApiID, Timeout: model.DefaultEndpointTimeout, Idempotent: true, Retry: retry },
+		model.EndpointCredit: { Name: ServiceCredit, Url: s.server.URL + "/credit", Method: http.MethodPost, Header_x_apigw_api_id: ApiID, Timeout: model.DefaultEndpointTimeout, Idempotent: true, Retry: retry },
+	}
+}
+
+// About an account client pointed at the fake (the endpoints of the fake are always valid)
+func (s *Server) Client() *account.AccountClient {
+	accountClient, err := account.NewAccountClient(s.Endpoints())
+	if err != nil {
+		panic(err)
+	}
+	return accountClient
+}
+
+// About add (or replace) an account, the statements are accepted only for the known accounts
+func (s *Server) AddAccount(acc model.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sequence = s.sequence + 1
+	if acc.ID == 0 {
+		acc.ID = s.sequence
+	}
+	s.accounts[acc.AccountID] = acc
+}
+
+// About force the answer of a service (status and raw body), until Reset
+func (s *Server) Respond(service string, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[service] = response{ status: status, body: body }
+}
+
+// About delay the answers of a service, to reach the timeout of the caller
+func (s *Server) Delay(service string, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res_response := s.responses[service]
+	res_response.delay = delay
+	s.responses[service] = res_response
+}
+
+// About close the connection without an answer, the service looks unavailable
+func (s *Server) Hangup(service string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[service] = response{ hangup: true }
+}
+
+// About drop the forced answer of a service, it answers like the real one again (the statements are kept)
+func (s *Server) Restore(service string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.responses, service)
+}
+
+// About drop the forced answers, the calls and the recorded statements, the accounts are kept
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses = map[string]response{}
+	s.calls = map[string]int{}
+	s.debits = nil
+	s.credits = nil
+	s.idempotent = map[string]model.AccountStatement{}
+}
+
+// About the statements accepted by go-debit
+func (s *Server) Debits() []model.AccountStatement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]model.AccountStatement(nil), s.debits...)
+}
+
+// About the statements accepted by go-credit
+func (s *Server) Credits() []model.AccountStatement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]model.AccountStatement(nil), s.credits...)
+}
+
+// About the requests received by a service, the forced answers included
+func (s *Server) Calls(service string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[service]
+}
+
+// About count the request and answer a forced response, false when the service answers normally
+func (s *Server) forced(service string, w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	s.calls[service] = s.calls[service] + 1
+	res_response, ok := s.responses[service]
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	if res_response.delay > 0 {
+		select {
+		case <-time.After(res_response.delay):
+		case <-r.Context().Done():
+			return true
+		}
+	}
+	if res_response.hangup {
+		hijacker, ok := w.(http.Hijacker)
+		if ok {
+			conn, _, err := hijacker.Hijack()
+			if err == nil {
+				conn.Close()
+				return true
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+	if res_response.status == 0 {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(res_response.status)
+	w.Write([]byte(res_response.body))
+	return true
+}
+
+// About check the api id like the gateway does
+func authorized(w http.ResponseWriter, r *http.Request) bool {
+	if r.Header.Get("x-apigw-api-id") != ApiID {
+		w.WriteHeader(http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// About go-account, get an account by its account_id
+func (s *Server) getAccount(w http.ResponseWriter, r *http.Request) {
+	if s.forced(ServiceAccount, w, r) || !authorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	res_account, ok := s.accounts[mux.Vars(r)["id"]]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"msg": "account not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, res_account)
+}
+
+// About go-debit and go-credit, add a statement of a type into a known account. A statement posted again
+// with the same Idempotency-Key is answered with the stored one, it is not added twice
+func (s *Server) postStatement(service string, typeCharge string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.forced(service, w, r) || !authorized(w, r) {
+			return
+		}
+
+		var accountStatement model.AccountStatement
+		err := json.NewDecoder(r.Body).Decode(&accountStatement)
+		if err != nil || accountStatement.Type != typeCharge {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"msg": "invalid statement"})
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		idempotencyKey := r.Header.Get("Idempotency-Key")
+		if res_statement, ok := s.idempotent[service + ":" + idempotencyKey]; ok && idempotencyKey != "" {
+			writeJSON(w, http.StatusOK, res_statement)
+			return
+		}
+
+		res_account, ok := s.accounts[accountStatement.AccountID]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"msg": "account not found"})
+			return
+		}
+
+		s.sequence = s.sequence + 1
+		accountStatement.ID = s.sequence
+		accountStatement.FkAccountID = res_account.ID
+		if service == ServiceDebit {
+			s.debits = append(s.debits, accountStatement)
+		} else {
+			s.credits = append(s.credits, accountStatement)
+		}
+		if idempotencyKey != "" {
+			s.idempotent[service + ":" + idempotencyKey] = accountStatement
+		}
+
+		writeJSON(w, http.StatusOK, accountStatement)
+	}
+}
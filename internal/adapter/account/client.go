@@ -0,0 +1,377 @@
+package account
+
+import (
+	"io"
+	"fmt"
+	"net"
+	"time"
+	"bytes"
+	"errors"
+	"context"
+	"sort"
+	"strings"
+	"net/url"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/adapter/resilience"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+)
+
+var childLogger = log.With().Str("component","go-fund-transfer").Str("package","internal.adapter.account").Logger()
+
+var tracerProvider go_core_observ.TracerProvider
+
+// About the limit of an answer body, an account or a statement is a few hundred bytes
+const maxBodySize = 1 << 20
+
+var _ port.AccountClient = (*AccountClient)(nil)
+
+// About the http client of go-account, go-debit and go-credit
+type AccountClient struct {
+	account			*dependency
+	debit			*dependency
+	credit			*dependency
+	dependencies	map[string]*dependency
+	httpClient		*http.Client
+}
+
+// About an endpoint with its own breaker, bulkhead and retry policy, a failing service does not hold the calls to the others
+type dependency struct {
+	name		string
+	endpoint	model.ApiService
+	breaker		*resilience.CircuitBreaker
+	bulkhead	*resilience.Bulkhead
+	retry		*resilience.RetryPolicy
+}
+
+// About the endpoints required by the client and the method of each one
+var requiredEndpoints = []struct{
+	name	string
+	method	string
+}{	{ model.EndpointAccount, http.MethodGet },
+	{ model.EndpointDebit, http.MethodPost },
+	{ model.EndpointCredit, http.MethodPost } }
+
+// About create the client from the endpoints keyed by logical name (account, debit, credit).
+// A missing or misconfigured endpoint is an error, the service must not boot with it.
+// The account endpoint is the lookup url, the account id is appended to it
+func NewAccountClient(endpoints map[string]model.ApiService) (*AccountClient, error) {
+	childLogger.Info().Str("func","NewAccountClient").Send()
+
+	dependencies := map[string]*dependency{}
+	for _, required := range requiredEndpoints {
+		endpoint, ok := endpoints[required.name]
+		if !ok {
+			return nil, fmt.Errorf("endpoint %s missing (ENDPOINT_%s_URL)", required.name, strings.ToUpper(required.name))
+		}
+		if endpoint.Method == "" {
+			endpoint.Method = required.method
+		}
+		if endpoint.Method != required.method {
+			return nil, fmt.Errorf("endpoint %s: method must be %s, not %s", required.name, required.method, endpoint.Method)
+		}
+		err := endpoint.Validate()
+		if err != nil {
+			return nil, err
+		}
+		dependencies[required.name] = &dependency{	name: required.name,
+													endpoint: endpoint,
+													breaker: resilience.NewCircuitBreaker(endpoint.Name, endpoint.Breaker),
+													bulkhead: resilience.NewBulkhead(endpoint.MaxConcurrent, time.Duration(endpoint.BulkheadWait) * time.Millisecond),
+													retry: resilience.NewRetryPolicy(endpoint.Retry) }
+	}
+
+	return &AccountClient{	account: dependencies[model.EndpointAccount],
+							debit: dependencies[model.EndpointDebit],
+							credit: dependencies[model.EndpointCredit],
+							dependencies: dependencies,
+							httpClient: &http.Client{ Transport: otelhttp.NewTransport(http.DefaultTransport) },
+	}, nil
+}
+
+// About the breaker and the bulkhead of each dependency, sorted by logical name
+func (c *AccountClient) Status() []model.DependencyStatus {
+	names := make([]string, 0, len(c.dependencies))
+	for name := range c.dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list_status := make([]model.DependencyStatus, 0, len(names))
+	for _, name := range names {
+		dep := c.dependencies[name]
+		dependencyStatus := model.DependencyStatus{ Name: name, Service: dep.endpoint.Name }
+		dep.breaker.Status(&dependencyStatus)
+		dep.bulkhead.Status(&dependencyStatus)
+		list_status = append(list_status, dependencyStatus)
+	}
+	return list_status
+}
+
+// About get an account from go-account
+func (c *AccountClient) GetAccount(ctx context.Context, accountID string) (*model.Account, error) {
+	childLogger.Info().Str("func","GetAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("account_id", accountID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "adapter.GetAccount")
+	defer span.End()
+
+	if accountID == "" {
+		return nil, erro.ErrAccountNotFound
+	}
+
+	var res_account model.Account
+	err := c.call(ctx, c.account, c.account.endpoint.Url + "/" + url.PathEscape(accountID), "", nil, &res_account)
+	if err != nil {
+		return nil, err
+	}
+
+	// Contract
+	if res_account.ID <= 0 {
+		return nil, contractError(c.account.endpoint, "id missing")
+	}
+	if res_account.AccountID != accountID {
+		return nil, contractError(c.account.endpoint, "account_id does not match the requested one").WithDetail("account_id", res_account.AccountID)
+	}
+	if res_account.Currency != "" {
+		if _, err := model.CurrencyExponent(res_account.Currency); err != nil {
+			return nil, contractError(c.account.endpoint, "currency invalid").WithDetail("currency", res_account.Currency)
+		}
+	}
+
+	return &res_account, nil
+}
+
+// About post a debit statement into go-debit
+func (c *AccountClient) PostDebit(ctx context.Context, accountStatement *model.AccountStatement) (*model.AccountStatement, error) {
+	childLogger.Info().Str("func","PostDebit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "adapter.PostDebit")
+	defer span.End()
+
+	return c.postStatement(ctx, c.debit, "DEBIT", accountStatement)
+}
+
+// About post a credit statement into go-credit
+func (c *AccountClient) PostCredit(ctx context.Context, accountStatement *model.AccountStatement) (*model.AccountStatement, error) {
+	childLogger.Info().Str("func","PostCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "adapter.PostCredit")
+	defer span.End()
+
+	return c.postStatement(ctx, c.credit, "CREDIT", accountStatement)
+}
+
+// About post a statement of a type, the answer is the stored statement. It is retried only when it carries
+// an idempotency key, the service must not add the statement twice.
+// A 2xx answer means the statement is applied: a body breaking the contract (empty, malformed, another account
+// or transaction) is logged and the posted statement is returned, the saga must count it as executed
+func (c *AccountClient) postStatement(ctx context.Context, dep *dependency, typeCharge string, accountStatement *model.AccountStatement) (*model.AccountStatement, error) {
+	if accountStatement == nil || accountStatement.AccountID == "" {
+		return nil, erro.ErrInvalid
+	}
+	if accountStatement.Type != typeCharge {
+		return nil, erro.ErrInvalid.WithDetail("type_charge", accountStatement.Type)
+	}
+
+	var res_statement model.AccountStatement
+	err := c.call(ctx, dep, dep.endpoint.Url, accountStatement.IdempotencyKey, accountStatement, &res_statement)
+	if errors.Is(err, erro.ErrUpstreamContract) {
+		childLogger.Warn().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("service", dep.endpoint.Name).Err(err).Msg("statement applied, answer outside the contract")
+		res_statement = model.AccountStatement{}
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Contract, the fields the services leave out are the posted ones
+	if (res_statement.AccountID != "" && res_statement.AccountID != accountStatement.AccountID) ||
+		(res_statement.TransactionID != nil && accountStatement.TransactionID != nil && *res_statement.TransactionID != *accountStatement.TransactionID) {
+		childLogger.Warn().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("service", dep.endpoint.Name).Interface("answer", res_statement).Msg("statement applied, answer does not match the posted one")
+		res_statement = model.AccountStatement{}
+	}
+	if res_statement.AccountID == "" {
+		res_statement.AccountID = accountStatement.AccountID
+	}
+	if res_statement.TransactionID == nil {
+		res_statement.TransactionID = accountStatement.TransactionID
+	}
+
+	return &res_statement, nil
+}
+
+// About call a service and decode its answer into result. A call failing with a transient error is tried
+// again by the retry policy of the endpoint, a GET always and a POST only with an idempotency key (sent in
+// the Idempotency-Key header) to an endpoint that honors it (Idempotent). The retries stop at the deadline
+// of the context (the caller sets it), the last error is returned
+func (c *AccountClient) call(ctx context.Context, dep *dependency, target string, idempotencyKey string, body interface{}, result interface{}) error {
+	retries := dep.retry.Max()
+	if dep.endpoint.Method != http.MethodGet && (idempotencyKey == "" || !dep.endpoint.Idempotent) {
+		retries = 0
+	}
+
+	for retry := 0; ; retry++ {
+		err := c.attempt(ctx, dep, target, idempotencyKey, body, result)
+		if err == nil || retry >= retries || !dep.retry.Retryable(err) || ctx.Err() != nil {
+			return err
+		}
+		childLogger.Info().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("service", dep.endpoint.Name).Int("attempt", retry + 1).Err(err).Msg("retryable failure")
+		if !dep.retry.Wait(ctx, retry) {
+			return err
+		}
+	}
+}
+
+// About one call of a service through its bulkhead and its breaker. A retryable error (timeout,
+// unavailable, 5xx) is a failure of the service, a call the caller gave up on counts neither way
+func (c *AccountClient) attempt(ctx context.Context, dep *dependency, target string, idempotencyKey string, body interface{}, result interface{}) error {
+	release, err := dep.bulkhead.Acquire(ctx)
+	if err != nil {
+		if erro.AsDomain(err) == nil {
+			return erro.ErrUpstreamTimeout.Wrap(err).WithDetail("service", dep.endpoint.Name)
+		}
+		return erro.AsDomain(err).WithDetail("service", dep.endpoint.Name)
+	}
+	defer release()
+
+	done, err := dep.breaker.Allow()
+	if err != nil {
+		childLogger.Warn().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("service", dep.endpoint.Name).Err(err).Send()
+		return erro.AsDomain(err).WithDetail("service", dep.endpoint.Name)
+	}
+
+	err = c.send(ctx, dep.endpoint, target, idempotencyKey, body, result)
+	domainError := erro.AsDomain(err)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		done(resilience.OutcomeCanceled)
+	case domainError != nil && domainError.Retryable:
+		done(resilience.OutcomeFailure)
+	default:
+		done(resilience.OutcomeSuccess)
+	}
+
+	return err
+}
+
+// About one request to a service, bounded by the timeout of the endpoint
+func (c *AccountClient) send(ctx context.Context, endpoint model.ApiService, target string, idempotencyKey string, body interface{}, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(endpoint.Timeout) * time.Millisecond)
+	defer cancel()
+
+	var payload io.Reader
+	if body != nil {
+		payload_bytes, err := json.Marshal(body)
+		if err != nil {
+			return erro.ErrInvalid.Wrap(err)
+		}
+		payload = bytes.NewReader(payload_bytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, target, payload)
+	if err != nil {
+		return erro.ErrServer.Wrap(err).WithDetail("service", endpoint.Name)
+	}
+	for header_name, header_value := range endpoint.Headers {
+		req.Header.Set(header_name, header_value)
+	}
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	req.Header.Set("X-Request-Id", fmt.Sprintf("%v", ctx.Value("trace-request-id")))
+	if endpoint.Header_x_apigw_api_id != "" {
+		req.Header.Set("x-apigw-api-id", endpoint.Header_x_apigw_api_id)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("service", endpoint.Name).Err(err).Send()
+		if isTimeout(err) {
+			return erro.ErrUpstreamTimeout.Wrap(err).WithDetail("service", endpoint.Name)
+		}
+		return erro.ErrUpstreamUnavailable.Wrap(err).WithDetail("service", endpoint.Name)
+	}
+	defer resp.Body.Close()
+
+	err = errorStatusCode(endpoint, resp.StatusCode)
+	if err != nil {
+		return err
+	}
+
+	return decode(endpoint, io.LimitReader(resp.Body, maxBodySize), result)
+}
+
+// About handle/convert the status code of an answer, per endpoint: a 400 of go-account (GET) is an account
+// not found, a 400 of go-debit/go-credit (POST) is the statement rejected. A 404 is always an account not found
+func errorStatusCode(endpoint model.ApiService, statusCode int) error {
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return nil
+	case statusCode == http.StatusUnauthorized:
+		return erro.ErrUnauthorized
+	case statusCode == http.StatusForbidden:
+		return erro.ErrHTTPForbiden
+	case statusCode == http.StatusBadRequest && endpoint.Method == http.MethodPost:
+		return erro.ErrStatementRejected.WithDetail("service", endpoint.Name).
+										WithDetail("upstream_status", statusCode)
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusNotFound:
+		return erro.ErrAccountNotFound
+	default:
+		return erro.ErrServer.Wrap(fmt.Errorf("%s answered %d", endpoint.Name, statusCode)).
+								WithDetail("service", endpoint.Name).
+								WithDetail("upstream_status", statusCode)
+	}
+}
+
+// About decode exactly one json object, an empty body, a malformed one, another json value or trailing data
+// break the contract. Unknown fields are accepted, the services may add fields
+func decode(endpoint model.ApiService, body io.Reader, result interface{}) error {
+	decoder := json.NewDecoder(body)
+
+	var raw json.RawMessage
+	err := decoder.Decode(&raw)
+	if errors.Is(err, io.EOF) {
+		return contractError(endpoint, "empty body")
+	}
+	if err != nil {
+		return erro.ErrUpstreamContract.Wrap(err).WithDetail("service", endpoint.Name)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return contractError(endpoint, "trailing data after the json object")
+	}
+	if raw[0] != '{' {
+		return contractError(endpoint, "body is not a json object")
+	}
+
+	err = json.Unmarshal(raw, result)
+	if err != nil {
+		return erro.ErrUpstreamContract.Wrap(err).WithDetail("service", endpoint.Name)
+	}
+
+	return nil
+}
+
+// About a contract error of a service
+func contractError(endpoint model.ApiService, reason string) *erro.DomainError {
+	return erro.ErrUpstreamContract.Wrap(errors.New(reason)).WithDetail("service", endpoint.Name)
+}
+
+// About a timeout of the http client or of the context
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,355 @@
+package account_test
+
+import (
+	"time"
+	"errors"
+	"context"
+	"testing"
+	"net/http"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/adapter/account"
+	"github.com/go-fund-transfer/internal/adapter/account/accounttest"
+)
+
+func newServer(t *testing.T) *accounttest.Server {
+	t.Helper()
+
+	srv := accounttest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddAccount(model.Account{ AccountID: "ACC-1", Currency: "BRL" })
+	return srv
+}
+
+// About a client on the fake with the endpoints changed by change
+func newClient(t *testing.T, srv *accounttest.Server, change func(endpoint *model.ApiService)) *account.AccountClient {
+	t.Helper()
+
+	endpoints := srv.Endpoints()
+	for name, endpoint := range endpoints {
+		change(&endpoint)
+		endpoints[name] = endpoint
+	}
+	accountClient, err := account.NewAccountClient(endpoints)
+	if err != nil {
+		t.Fatalf("NewAccountClient: %v", err)
+	}
+	return accountClient
+}
+
+func newStatement(t *testing.T, accountID string, typeCharge string, idempotencyKey string) *model.AccountStatement {
+	t.Helper()
+
+	amount, err := model.NewMoney(100, "BRL")
+	if err != nil {
+		t.Fatalf("NewMoney: %v", err)
+	}
+	return &model.AccountStatement{ AccountID: accountID, Type: typeCharge, Currency: "BRL", Amount: amount, IdempotencyKey: idempotencyKey }
+}
+
+func TestAccountClientSuccess(t *testing.T) {
+	srv := newServer(t)
+	accountClient := srv.Client()
+	ctx := context.Background()
+
+	res_account, err := accountClient.GetAccount(ctx, "ACC-1")
+	if err != nil {
+		t.Fatalf("GetAccount: %v", err)
+	}
+	if res_account.AccountID != "ACC-1" || res_account.ID <= 0 {
+		t.Errorf("account = %+v, want ACC-1 with an id", res_account)
+	}
+
+	res_debit, err := accountClient.PostDebit(ctx, newStatement(t, "ACC-1", "DEBIT", "key-debit"))
+	if err != nil {
+		t.Fatalf("PostDebit: %v", err)
+	}
+	if res_debit.ID <= 0 || res_debit.FkAccountID != res_account.ID {
+		t.Errorf("debit = %+v, want an id and fk_account_id %d", res_debit, res_account.ID)
+	}
+
+	_, err = accountClient.PostCredit(ctx, newStatement(t, "ACC-1", "CREDIT", "key-credit"))
+	if err != nil {
+		t.Fatalf("PostCredit: %v", err)
+	}
+
+	if len(srv.Debits()) != 1 || len(srv.Credits()) != 1 {
+		t.Errorf("debits = %d, credits = %d, want 1 and 1", len(srv.Debits()), len(srv.Credits()))
+	}
+}
+
+func TestAccountClientErrorStatus(t *testing.T) {
+	tests := []struct {
+		name		string
+		service		string
+		status		int
+		call		func(ctx context.Context, accountClient *account.AccountClient) error
+		want		*erro.DomainError
+	}{
+		{ "account unknown", "", 0,
+			func(ctx context.Context, accountClient *account.AccountClient) error {
+				_, err := accountClient.GetAccount(ctx, "ACC-404")
+				return err
+			}, erro.ErrAccountNotFound },
+		{ "debit into an unknown account", "", 0,
+			func(ctx context.Context, accountClient *account.AccountClient) error {
+				_, err := accountClient.PostDebit(ctx, newStatement(t, "ACC-404", "DEBIT", "key-1"))
+				return err
+			}, erro.ErrAccountNotFound },
+		{ "account 400", accounttest.ServiceAccount, http.StatusBadRequest,
+			func(ctx context.Context, accountClient *account.AccountClient) error {
+				_, err := accountClient.GetAccount(ctx, "ACC-1")
+				return err
+			}, erro.ErrAccountNotFound },
+		{ "debit 400", accounttest.ServiceDebit, http.StatusBadRequest,
+			func(ctx context.Context, accountClient *account.AccountClient) error {
+				_, err := accountClient.PostDebit(ctx, newStatement(t, "ACC-1", "DEBIT", "key-1"))
+				return err
+			}, erro.ErrStatementRejected },
+		{ "credit 400", accounttest.ServiceCredit, http.StatusBadRequest,
+			func(ctx context.Context, accountClient *account.AccountClient) error {
+				_, err := accountClient.PostCredit(ctx, newStatement(t, "ACC-1", "CREDIT", "key-1"))
+				return err
+			}, erro.ErrStatementRejected },
+		{ "account 401", accounttest.ServiceAccount, http.StatusUnauthorized,
+			func(ctx context.Context, accountClient *account.AccountClient) error {
+				_, err := accountClient.GetAccount(ctx, "ACC-1")
+				return err
+			}, erro.ErrUnauthorized },
+		{ "account 500", accounttest.ServiceAccount, http.StatusInternalServerError,
+			func(ctx context.Context, accountClient *account.AccountClient) error {
+				_, err := accountClient.GetAccount(ctx, "ACC-1")
+				return err
+			}, erro.ErrServer },
+		{ "debit 502", accounttest.ServiceDebit, http.StatusBadGateway,
+			func(ctx context.Context, accountClient *account.AccountClient) error {
+				_, err := accountClient.PostDebit(ctx, newStatement(t, "ACC-1", "DEBIT", "key-1"))
+				return err
+			}, erro.ErrServer },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t)
+			if tt.service != "" {
+				srv.Respond(tt.service, tt.status, `{"msg":"forced"}`)
+			}
+
+			err := tt.call(context.Background(), srv.Client())
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %s", err, tt.want.Code)
+			}
+			if tt.want == erro.ErrServer && erro.AsDomain(err).Details["upstream_status"] != tt.status {
+				t.Errorf("upstream_status = %v, want %d", erro.AsDomain(err).Details["upstream_status"], tt.status)
+			}
+		})
+	}
+}
+
+func TestAccountClientPostApplied(t *testing.T) {
+	tests := []struct {
+		name		string
+		body		string
+	}{
+		{ "empty body", "" },
+		{ "not an object", `["statement"]` },
+		{ "malformed", `{"id":` },
+		{ "another account", `{"id":7,"account_id":"ACC-9"}` },
+		{ "another transaction", `{"id":7,"transaction_id":"tx-9"}` },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t)
+			srv.Respond(accounttest.ServiceDebit, http.StatusOK, tt.body)
+
+			// a 2xx is an applied statement, the answer outside the contract does not make it an error
+			statement := newStatement(t, "ACC-1", "DEBIT", "key-1")
+			transactionID := "tx-1"
+			statement.TransactionID = &transactionID
+			res_statement, err := srv.Client().PostDebit(context.Background(), statement)
+			if err != nil {
+				t.Fatalf("err = %v, want none", err)
+			}
+			if res_statement.AccountID != "ACC-1" || res_statement.TransactionID == nil || *res_statement.TransactionID != "tx-1" {
+				t.Errorf("statement = %+v, want the posted ACC-1 tx-1", res_statement)
+			}
+			if srv.Calls(accounttest.ServiceDebit) != 1 {
+				t.Errorf("calls = %d, want 1", srv.Calls(accounttest.ServiceDebit))
+			}
+		})
+	}
+}
+
+func TestAccountClientRetry(t *testing.T) {
+	tests := []struct {
+		name			string
+		service			string
+		call			func(ctx context.Context, accountClient *account.AccountClient) error
+		calls			int
+	}{
+		{ "get retried", accounttest.ServiceAccount,
+			func(ctx context.Context, accountClient *account.AccountClient) error {
+				_, err := accountClient.GetAccount(ctx, "ACC-1")
+				return err
+			}, model.DefaultRetryMax + 1 },
+		{ "post with an idempotency key retried", accounttest.ServiceDebit,
+			func(ctx context.Context, accountClient *account.AccountClient) error {
+				_, err := accountClient.PostDebit(ctx, newStatement(t, "ACC-1", "DEBIT", "key-1"))
+				return err
+			}, model.DefaultRetryMax + 1 },
+		{ "post without an idempotency key not retried", accounttest.ServiceCredit,
+			func(ctx context.Context, accountClient *account.AccountClient) error {
+				_, err := accountClient.PostCredit(ctx, newStatement(t, "ACC-1", "CREDIT", ""))
+				return err
+			}, 1 },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t)
+			srv.Respond(tt.service, http.StatusServiceUnavailable, `{"msg":"unavailable"}`)
+
+			err := tt.call(context.Background(), srv.Client())
+			if !errors.Is(err, erro.ErrServer) {
+				t.Fatalf("err = %v, want %s", err, erro.ErrServer.Code)
+			}
+			if srv.Calls(tt.service) != tt.calls {
+				t.Errorf("calls = %d, want %d", srv.Calls(tt.service), tt.calls)
+			}
+		})
+	}
+}
+
+func TestAccountClientRetryNotIdempotent(t *testing.T) {
+	srv := newServer(t)
+	srv.Respond(accounttest.ServiceDebit, http.StatusServiceUnavailable, `{"msg":"unavailable"}`)
+	accountClient := newClient(t, srv, func(endpoint *model.ApiService) {
+		endpoint.Idempotent = false
+	})
+
+	// the key is sent, but the endpoint does not honor it: the post is not retried
+	_, err := accountClient.PostDebit(context.Background(), newStatement(t, "ACC-1", "DEBIT", "key-1"))
+	if !errors.Is(err, erro.ErrServer) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrServer.Code)
+	}
+	if srv.Calls(accounttest.ServiceDebit) != 1 {
+		t.Errorf("calls = %d, want 1", srv.Calls(accounttest.ServiceDebit))
+	}
+}
+
+func TestAccountClientRetryIdempotent(t *testing.T) {
+	srv := newServer(t)
+	accountClient := srv.Client()
+	ctx := context.Background()
+
+	// The same key posted again is answered with the stored statement, it is added once
+	first, err := accountClient.PostDebit(ctx, newStatement(t, "ACC-1", "DEBIT", "key-1"))
+	if err != nil {
+		t.Fatalf("PostDebit: %v", err)
+	}
+	again, err := accountClient.PostDebit(ctx, newStatement(t, "ACC-1", "DEBIT", "key-1"))
+	if err != nil {
+		t.Fatalf("PostDebit again: %v", err)
+	}
+	if first.ID != again.ID || len(srv.Debits()) != 1 {
+		t.Errorf("ids = %d and %d, debits = %d, want the same id and 1 debit", first.ID, again.ID, len(srv.Debits()))
+	}
+}
+
+func TestAccountClientUnavailable(t *testing.T) {
+	srv := newServer(t)
+	srv.Hangup(accounttest.ServiceAccount)
+
+	_, err := srv.Client().GetAccount(context.Background(), "ACC-1")
+	if !errors.Is(err, erro.ErrUpstreamUnavailable) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrUpstreamUnavailable.Code)
+	}
+	// The transport may replay a GET on a closed connection, the client retries at least Max times
+	if srv.Calls(accounttest.ServiceAccount) < model.DefaultRetryMax + 1 {
+		t.Errorf("calls = %d, want at least %d", srv.Calls(accounttest.ServiceAccount), model.DefaultRetryMax + 1)
+	}
+}
+
+func TestAccountClientTimeout(t *testing.T) {
+	srv := newServer(t)
+	srv.Delay(accounttest.ServiceAccount, 2 * time.Second)
+	accountClient := newClient(t, srv, func(endpoint *model.ApiService) {
+		endpoint.Timeout = 50
+		endpoint.Retry.Max = 0
+	})
+
+	start := time.Now()
+	_, err := accountClient.GetAccount(context.Background(), "ACC-1")
+	if !errors.Is(err, erro.ErrUpstreamTimeout) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrUpstreamTimeout.Code)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("took %s, want the endpoint timeout", time.Since(start))
+	}
+}
+
+func TestAccountClientContextDeadline(t *testing.T) {
+	srv := newServer(t)
+	srv.Delay(accounttest.ServiceAccount, 2 * time.Second)
+	accountClient := srv.Client()
+
+	// The deadline of the caller stops the call and its retries
+	ctx, cancel := context.WithTimeout(context.Background(), 100 * time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := accountClient.GetAccount(ctx, "ACC-1")
+	if !errors.Is(err, erro.ErrUpstreamTimeout) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrUpstreamTimeout.Code)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("took %s, want the deadline of the context", time.Since(start))
+	}
+	if srv.Calls(accounttest.ServiceAccount) != 1 {
+		t.Errorf("calls = %d, want 1", srv.Calls(accounttest.ServiceAccount))
+	}
+}
+
+func TestAccountClientBreaker(t *testing.T) {
+	srv := newServer(t)
+	srv.Respond(accounttest.ServiceAccount, http.StatusServiceUnavailable, `{"msg":"unavailable"}`)
+	accountClient := newClient(t, srv, func(endpoint *model.ApiService) {
+		endpoint.Retry.Max = 0
+		endpoint.Breaker = model.BreakerConfig{ Failures: 2, OpenTimeout: 60000, HalfOpenCalls: 1 }
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := accountClient.GetAccount(ctx, "ACC-1")
+		if !errors.Is(err, erro.ErrServer) {
+			t.Fatalf("call %d: err = %v, want %s", i, err, erro.ErrServer.Code)
+		}
+	}
+
+	// Open, the service is not called
+	_, err := accountClient.GetAccount(ctx, "ACC-1")
+	if !errors.Is(err, erro.ErrCircuitOpen) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrCircuitOpen.Code)
+	}
+	if srv.Calls(accounttest.ServiceAccount) != 2 {
+		t.Errorf("calls = %d, want 2", srv.Calls(accounttest.ServiceAccount))
+	}
+
+	for _, dependencyStatus := range accountClient.Status() {
+		want := model.BreakerClosed
+		if dependencyStatus.Name == model.EndpointAccount {
+			want = model.BreakerOpen
+		}
+		if dependencyStatus.State != want {
+			t.Errorf("%s state = %s, want %s", dependencyStatus.Name, dependencyStatus.State, want)
+		}
+	}
+
+	// The breaker of a dependency does not stop the others
+	_, err = accountClient.PostDebit(ctx, newStatement(t, "ACC-1", "DEBIT", "key-1"))
+	if err != nil {
+		t.Errorf("PostDebit: %v", err)
+	}
+}
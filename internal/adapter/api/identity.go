@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About the signature of the identity headers, hex(HMAC-SHA256(secret, X-User-Id + "\n" + X-User-Roles))
+func SignIdentity(secret string, userID string, roles string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(userID + "\n" + roles))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// About accept the identity headers (X-User-Id, X-User-Roles) only when signed by the api gateway (X-User-Signature),
+// otherwise the request is refused (401). A request without identity headers goes on anonymous
+func IdentityMiddleware(identityConfig *model.IdentityConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			userID := req.Header.Get("X-User-Id")
+			roles := req.Header.Get("X-User-Roles")
+			signature := req.Header.Get("X-User-Signature")
+
+			if userID != "" || roles != "" || signature != "" {
+				expected := SignIdentity(identityConfig.Secret, userID, roles)
+				if !hmac.Equal([]byte(signature), []byte(expected)) {
+					childLogger.Error().Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Str("user", userID).Msg("identity headers not signed by the gateway")
+					errWrite := WriteProblem(rw, req, erro.ErrUnauthorized.WithDetail("reason", "identity headers not signed by the gateway"))
+					if errWrite != nil {
+						childLogger.Error().Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Err(errWrite).Msg("failed to write the problem")
+					}
+					return
+				}
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
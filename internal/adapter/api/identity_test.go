@@ -0,0 +1,59 @@
+package api
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+func TestIdentityMiddleware(t *testing.T) {
+	identityConfig := model.IdentityConfig{Secret: "secret-test"}
+
+	tests := []struct {
+		name		string
+		userID		string
+		roles		string
+		signature	string
+		status		int
+	}{
+		{ "anonymous", "", "", "", http.StatusOK },
+		{ "signed", "manager-01", "TRANSFER_APPROVER", SignIdentity("secret-test", "manager-01", "TRANSFER_APPROVER"), http.StatusOK },
+		{ "signed without roles", "user-01", "", SignIdentity("secret-test", "user-01", ""), http.StatusOK },
+		{ "unsigned", "manager-01", "TRANSFER_APPROVER", "", http.StatusUnauthorized },
+		{ "role added", "manager-01", "TRANSFER_APPROVER,TRANSFER_REVIEWER", SignIdentity("secret-test", "manager-01", "TRANSFER_APPROVER"), http.StatusUnauthorized },
+		{ "other user", "manager-02", "TRANSFER_APPROVER", SignIdentity("secret-test", "manager-01", "TRANSFER_APPROVER"), http.StatusUnauthorized },
+		{ "other secret", "manager-01", "TRANSFER_APPROVER", SignIdentity("secret-other", "manager-01", "TRANSFER_APPROVER"), http.StatusUnauthorized },
+		{ "signature only", "", "", "abc", http.StatusUnauthorized },
+	}
+
+	handler := IdentityMiddleware(&identityConfig)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/transfer/1/approve", nil)
+			if tt.userID != "" {
+				req.Header.Set("X-User-Id", tt.userID)
+			}
+			if tt.roles != "" {
+				req.Header.Set("X-User-Roles", tt.roles)
+			}
+			if tt.signature != "" {
+				req.Header.Set("X-User-Signature", tt.signature)
+			}
+			rw := httptest.NewRecorder()
+
+			handler.ServeHTTP(rw, req)
+
+			if rw.Code != tt.status {
+				t.Errorf("status = %d, want %d", rw.Code, tt.status)
+			}
+			if tt.status == http.StatusUnauthorized && rw.Header().Get("Content-Type") != problemContentType {
+				t.Errorf("content type = %q, want %q", rw.Header().Get("Content-Type"), problemContentType)
+			}
+		})
+	}
+}
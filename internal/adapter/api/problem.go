@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About the media type of the error responses (RFC 7807)
+const problemContentType = "application/problem+json"
+
+// About the prefix of the type of a problem, followed by the code
+const problemTypePrefix = "urn:go-fund-transfer:problem:"
+
+// About an error response (RFC 7807), code, retryable and details are extension members
+type problem struct {
+	Type		string					`json:"type"`
+	Title		string					`json:"title"`
+	Status		int						`json:"status"`
+	Detail		string					`json:"detail,omitempty"`
+	Instance	string					`json:"instance,omitempty"`
+	Code		string					`json:"code"`
+	Retryable	bool					`json:"retryable"`
+	TraceID		string					`json:"trace_id,omitempty"`
+	Details		map[string]interface{}	`json:"details,omitempty"`
+}
+
+// About map an error into a problem. A domain error (erro.DomainError) keeps its code, status and details,
+// the business rejections carry the breached limit or the risk decision, any other error is an internal error
+func newProblem(req *http.Request, err error) problem {
+	res := problem{	Type: problemTypePrefix + erro.CodeInternal,
+					Title: http.StatusText(http.StatusInternalServerError),
+					Status: http.StatusInternalServerError,
+					Instance: req.URL.Path,
+					Code: erro.CodeInternal }
+	if trace_id, ok := req.Context().Value("trace-request-id").(string); ok {
+		res.TraceID = trace_id
+	}
+
+	domainError := erro.AsDomain(err)
+	if domainError == nil {
+		// the cause of an internal error is only logged
+		return res
+	}
+
+	res.Type = problemTypePrefix + domainError.Code
+	res.Title = domainError.Message
+	res.Status = domainError.Status
+	res.Detail = err.Error()
+	res.Code = domainError.Code
+	res.Retryable = domainError.Retryable
+	res.Details = domainError.Details
+
+	var limitExceeded *model.LimitExceededError
+	var riskDenied *model.RiskDeniedError
+	switch {
+	case errors.As(err, &limitExceeded):
+		res.Details = map[string]interface{}{"breach": limitExceeded.Breach}
+	case errors.As(err, &riskDenied):
+		res.Details = map[string]interface{}{"risk_decision": riskDenied.Decision}
+	}
+
+	return res
+}
+
+// About an unreadable request (path, query or body), a domain error is kept as it is
+func invalidRequest(err error) error {
+	if erro.AsDomain(err) != nil {
+		return err
+	}
+	return erro.ErrInvalid.Wrap(err)
+}
+
+// About write an error as application/problem+json
+func WriteProblem(rw http.ResponseWriter, req *http.Request, err error) error {
+	res := newProblem(req, err)
+	if res.Status >= http.StatusInternalServerError {
+		childLogger.Error().Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Str("code", res.Code).Err(err).Send()
+	}
+
+	rw.Header().Set("Content-Type", problemContentType)
+	rw.WriteHeader(res.Status)
+	return json.NewEncoder(rw).Encode(res)
+}
+
+// About the error handler of the routes, the error returned by a handler is written as a problem
+func ProblemHandler(h func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if err := h(rw, req); err != nil {
+			errWrite := WriteProblem(rw, req, err)
+			if errWrite != nil {
+				childLogger.Error().Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Err(errWrite).Msg("failed to write the problem")
+			}
+		}
+	}
+}
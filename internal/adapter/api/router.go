@@ -5,6 +5,13 @@ import (
 	"net/http"
 	"github.com/rs/zerolog/log"
 	"strconv"
+	"io"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+	"regexp"
+	"strings"
 
 	"github.com/go-fund-transfer/internal/core/service"
 	"github.com/go-fund-transfer/internal/core/model"
@@ -17,8 +24,8 @@ import (
 var childLogger = log.With().Str("component", "go-fund-transfer").Str("package", "internal.adapter.api").Logger()
 
 var core_json coreJson.CoreJson
-var core_apiError coreJson.APIError
 var tracerProvider go_core_observ.TracerProvider
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
 
 type HttpRouters struct {
 	workerService 	*service.WorkerService
@@ -31,6 +38,47 @@ func NewHttpRouters(workerService *service.WorkerService) HttpRouters {
 	}
 }
 
+// About decode a transfer body and bind the Idempotency-Key header (with the request hash) into the context.
+// The maker of the transfer is the authenticated user (X-User-Id)
+func decodeTransfer(req *http.Request, transfer *model.Transfer) (*http.Request, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return req, err
+	}
+	err = json.Unmarshal(body, transfer)
+	if err != nil {
+		return req, err
+	}
+	transfer.RequestedBy = req.Header.Get("X-User-Id")
+
+	return bindIdempotency(req, transfer)
+}
+
+// About bind the Idempotency-Key header (with the request hash) into the context,
+// the prefix of the keys of the internal workers is refused so a client key never collides with them
+func bindIdempotency(req *http.Request, decoded interface{}) (*http.Request, error) {
+	key := req.Header.Get("Idempotency-Key")
+	if key == "" {
+		return req, nil
+	}
+	if len(key) > 255 || strings.HasPrefix(key, model.IdempotencyInternalPrefix) {
+		return req, erro.ErrInvalid
+	}
+
+	// hash the decoded body, so whitespace or field order do not change the request identity
+	canonical, err := json.Marshal(decoded)
+	if err != nil {
+		return req, err
+	}
+	hash := sha256.Sum256(append([]byte(req.Method + " " + req.URL.Path + "\n"), canonical...))
+
+	idempotency := model.Idempotency{	Key: key,
+										RequestHash: hex.EncodeToString(hash[:]) }
+	ctx := context.WithValue(req.Context(), "idempotency-key", &idempotency)
+
+	return req.WithContext(ctx), nil
+}
+
 // About return a health
 func (h *HttpRouters) Health(rw http.ResponseWriter, req *http.Request) {
 	childLogger.Info().Str("func","Health").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
@@ -66,8 +114,7 @@ func (h *HttpRouters) GetTransfer(rw http.ResponseWriter, req *http.Request) err
 	vars := mux.Vars(req)
 	varID, err := strconv.Atoi(vars["id"]) 
     if err != nil { 
-		core_apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
-		return  &core_apiError
+		return invalidRequest(err)
     } 
 
 	transfer := model.Transfer{}
@@ -76,13 +123,7 @@ func (h *HttpRouters) GetTransfer(rw http.ResponseWriter, req *http.Request) err
 	// call service
 	res, err := h.workerService.GetTransfer(req.Context(), &transfer)
 	if err != nil {
-		switch err {
-		case erro.ErrNotFound:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
-		default:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
-		}
-		return &core_apiError
+		return err
 	}
 	
 	return core_json.WriteJSON(rw, http.StatusOK, res)
@@ -98,24 +139,21 @@ func (h *HttpRouters) AddTransfer(rw http.ResponseWriter, req *http.Request) err
 
 	//parameters
 	transfer := model.Transfer{}
-	err := json.NewDecoder(req.Body).Decode(&transfer)
+	req, err := decodeTransfer(req, &transfer)
     if err != nil {
-		core_apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
-		return &core_apiError
+		return invalidRequest(err)
     }
 
 	// call service
 	res, err := h.workerService.AddTransfer(req.Context(), &transfer)
 	if err != nil {
-		switch err {
-		case erro.ErrNotFound:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
-		case erro.ErrTransInvalid:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusConflict)
-		default:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
-		}
-		return &core_apiError
+		return err
+	}
+
+
+	// a transfer held for a manual review or an approval is accepted, not done
+	if res.Status == model.StatusPendingReview || res.Status == model.StatusAwaitingApproval {
+		return core_json.WriteJSON(rw, http.StatusAccepted, res)
 	}
 	
 	return core_json.WriteJSON(rw, http.StatusOK, res)
@@ -131,24 +169,21 @@ func (h *HttpRouters) AddTransferEvent(rw http.ResponseWriter, req *http.Request
 
 	//parameters
 	transfer := model.Transfer{}
-	err := json.NewDecoder(req.Body).Decode(&transfer)
+	req, err := decodeTransfer(req, &transfer)
     if err != nil {
-		core_apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
-		return &core_apiError
+		return invalidRequest(err)
     }
 
 	// call service
 	res, err := h.workerService.AddTransferEvent(req.Context(), &transfer)
 	if err != nil {
-		switch err {
-		case erro.ErrNotFound:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
-		case erro.ErrTransInvalid:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusConflict)
-		default:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
-		}
-		return &core_apiError
+		return err
+	}
+
+
+	// a transfer held for a manual review or an approval is accepted, not done
+	if res.Status == model.StatusPendingReview || res.Status == model.StatusAwaitingApproval {
+		return core_json.WriteJSON(rw, http.StatusAccepted, res)
 	}
 	
 	return core_json.WriteJSON(rw, http.StatusOK, res)
@@ -164,26 +199,21 @@ func (h *HttpRouters) CreditTransferEvent(rw http.ResponseWriter, req *http.Requ
 
 	//parameters
 	transfer := model.Transfer{}
-	err := json.NewDecoder(req.Body).Decode(&transfer)
+	req, err := decodeTransfer(req, &transfer)
     if err != nil {
-		core_apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
-		return &core_apiError
+		return invalidRequest(err)
     }
 
 	// call service
 	res, err := h.workerService.CreditTransferEvent(req.Context(), &transfer)
 	if err != nil {
-		switch err {
-		case erro.ErrNotFound:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
-		case erro.ErrTransInvalid:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusConflict)
-		case erro.ErrAmountInvalid:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusConflict)
-		default:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
-		}
-		return &core_apiError
+		return err
+	}
+
+
+	// a transfer held for a manual review or an approval is accepted, not done
+	if res.Status == model.StatusPendingReview || res.Status == model.StatusAwaitingApproval {
+		return core_json.WriteJSON(rw, http.StatusAccepted, res)
 	}
 	
 	return core_json.WriteJSON(rw, http.StatusOK, res)
@@ -199,26 +229,912 @@ func (h *HttpRouters) DebitTransferEvent(rw http.ResponseWriter, req *http.Reque
 
 	//parameters
 	transfer := model.Transfer{}
-	err := json.NewDecoder(req.Body).Decode(&transfer)
+	req, err := decodeTransfer(req, &transfer)
     if err != nil {
-		core_apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
-		return &core_apiError
+		return invalidRequest(err)
     }
 
 	// call service
 	res, err := h.workerService.DebitTransferEvent(req.Context(), &transfer)
 	if err != nil {
-		switch err {
-		case erro.ErrNotFound:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
-		case erro.ErrTransInvalid:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusConflict)
-		case erro.ErrAmountInvalid:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusConflict)
-		default:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+		return err
+	}
+
+
+	// a transfer held for a manual review or an approval is accepted, not done
+	if res.Status == model.StatusPendingReview || res.Status == model.StatusAwaitingApproval {
+		return core_json.WriteJSON(rw, http.StatusAccepted, res)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About update the status of a transfer
+func (h *HttpRouters) UpdateTransferStatus(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","UpdateTransferStatus").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.UpdateTransferStatus")
+	defer span.End()
+
+	//parameters
+	vars := mux.Vars(req)
+	varID, err := strconv.Atoi(vars["id"]) 
+    if err != nil { 
+		return invalidRequest(err)
+    } 
+
+	statusTransition := model.StatusTransition{}
+	err = json.NewDecoder(req.Body).Decode(&statusTransition)
+    if err != nil {
+		return invalidRequest(err)
+    }
+	statusTransition.FkTransferID = varID
+
+	// call service
+	res, err := h.workerService.UpdateTransferStatus(req.Context(), &statusTransition)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list the status transitions of a transfer
+func (h *HttpRouters) ListTransferStatus(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListTransferStatus").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ListTransferStatus")
+	defer span.End()
+
+	//parameters
+	vars := mux.Vars(req)
+	varID, err := strconv.Atoi(vars["id"]) 
+    if err != nil { 
+		return invalidRequest(err)
+    } 
+
+	transfer := model.Transfer{}
+	transfer.ID = varID
+
+	// call service
+	res, err := h.workerService.ListTransferStatus(req.Context(), &transfer)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About parse a date parameter (RFC3339 or 2006-01-02), a date only "to" includes the whole day
+func parseDateParam(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return &date, nil
+	}
+	date, err = time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, erro.ErrInvalid
+	}
+	if endOfDay {
+		date = date.AddDate(0, 0, 1)
+	}
+	return &date, nil
+}
+
+// About search the transfers
+func (h *HttpRouters) ListTransfer(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListTransfer").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ListTransfer")
+	defer span.End()
+
+	//parameters
+	params := req.URL.Query()
+	transferFilter := model.TransferFilter{	AccountID: params.Get("account_id"),
+											Status: model.TransferStatus(params.Get("status")),
+											Currency: params.Get("currency") }
+
+	var err error
+	transferFilter.From, err = parseDateParam(params.Get("from"), false)
+	if err != nil {
+		return invalidRequest(err)
+	}
+	transferFilter.To, err = parseDateParam(params.Get("to"), true)
+	if err != nil {
+		return invalidRequest(err)
+	}
+	if params.Get("limit") != "" {
+		transferFilter.Limit, err = strconv.Atoi(params.Get("limit"))
+		if err != nil {
+			return invalidRequest(err)
+		}
+	}
+
+	// call service
+	res, err := h.workerService.ListTransfer(req.Context(), &transferFilter, params.Get("cursor"))
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get the transfer by its transaction_id
+func (h *HttpRouters) GetTransferByTransactionID(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetTransferByTransactionID").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.GetTransferByTransactionID")
+	defer span.End()
+
+	//parameters
+	vars := mux.Vars(req)
+	varTransactionID := vars["transaction_id"]
+	if !uuidRegex.MatchString(varTransactionID) {
+		return erro.ErrInvalid
+	}
+
+	transfer := model.Transfer{}
+	transfer.TransactionID = &varTransactionID
+
+	// call service
+	res, err := h.workerService.GetTransferByTransactionID(req.Context(), &transfer)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About decode a schedule body, the transfer fields plus the execute_at
+func decodeSchedule(req *http.Request, schedule *model.Schedule) error {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+
+	transfer := model.Transfer{}
+	err = json.Unmarshal(body, &transfer)
+	if err != nil {
+		return err
+	}
+
+	var scheduleAt struct {
+		ExecuteAt	time.Time	`json:"execute_at"`
+	}
+	err = json.Unmarshal(body, &scheduleAt)
+	if err != nil {
+		return err
+	}
+
+	transfer.RequestedBy = req.Header.Get("X-User-Id")
+	schedule.Transfer = &transfer
+	schedule.ExecuteAt = scheduleAt.ExecuteAt
+	return nil
+}
+
+// About add a schedule of the given type
+func (h *HttpRouters) addSchedule(rw http.ResponseWriter, req *http.Request, scheduleType string) error {
+	//parameters
+	schedule := model.Schedule{}
+	err := decodeSchedule(req, &schedule)
+    if err != nil {
+		return invalidRequest(err)
+    }
+	schedule.Type = scheduleType
+
+	// call service
+	res, err := h.workerService.AddSchedule(req.Context(), &schedule)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About add a scheduled credit
+func (h *HttpRouters) AddCreditSchedule(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddCreditSchedule").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.AddCreditSchedule")
+	defer span.End()
+
+	return h.addSchedule(rw, req, model.ScheduleCredit)
+}
+
+// About add a scheduled debit
+func (h *HttpRouters) AddDebitSchedule(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddDebitSchedule").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.AddDebitSchedule")
+	defer span.End()
+
+	return h.addSchedule(rw, req, model.ScheduleDebit)
+}
+
+// About add a scheduled transfer
+func (h *HttpRouters) AddTransferSchedule(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddTransferSchedule").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.AddTransferSchedule")
+	defer span.End()
+
+	return h.addSchedule(rw, req, model.ScheduleTransfer)
+}
+
+// About list the schedules (pending by default)
+func (h *HttpRouters) ListSchedule(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListSchedule").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ListSchedule")
+	defer span.End()
+
+	//parameters
+	params := req.URL.Query()
+	schedule := model.Schedule{}
+	schedule.Status = params.Get("status")
+
+	limit := 0
+	var err error
+	if params.Get("limit") != "" {
+		limit, err = strconv.Atoi(params.Get("limit"))
+		if err != nil {
+			return invalidRequest(err)
+		}
+	}
+
+	// call service
+	res, err := h.workerService.ListSchedule(req.Context(), &schedule, limit)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About cancel a pending schedule
+func (h *HttpRouters) CancelSchedule(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","CancelSchedule").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.CancelSchedule")
+	defer span.End()
+
+	//parameters
+	vars := mux.Vars(req)
+	varID, err := strconv.Atoi(vars["id"]) 
+    if err != nil { 
+		return invalidRequest(err)
+    } 
+
+	schedule := model.Schedule{}
+	schedule.ID = varID
+
+	// call service
+	res, err := h.workerService.CancelSchedule(req.Context(), &schedule)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About decode a standing order body, the transfer fields plus the recurrence
+func decodeStandingOrder(req *http.Request, standingOrder *model.StandingOrder) error {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+
+	transfer := model.Transfer{}
+	err = json.Unmarshal(body, &transfer)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(body, standingOrder)
+	if err != nil {
+		return err
+	}
+
+	transfer.RequestedBy = req.Header.Get("X-User-Id")
+	standingOrder.Transfer = &transfer
+	return nil
+}
+
+// About add a standing order
+func (h *HttpRouters) AddStandingOrder(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddStandingOrder").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.AddStandingOrder")
+	defer span.End()
+
+	//parameters
+	standingOrder := model.StandingOrder{}
+	err := decodeStandingOrder(req, &standingOrder)
+    if err != nil {
+		return invalidRequest(err)
+    }
+
+	// call service
+	res, err := h.workerService.AddStandingOrder(req.Context(), &standingOrder)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get a standing order with its history
+func (h *HttpRouters) GetStandingOrder(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetStandingOrder").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.GetStandingOrder")
+	defer span.End()
+
+	//parameters
+	vars := mux.Vars(req)
+	varID, err := strconv.Atoi(vars["id"]) 
+    if err != nil { 
+		return invalidRequest(err)
+    } 
+
+	standingOrder := model.StandingOrder{}
+	standingOrder.ID = varID
+
+	// call service
+	res, err := h.workerService.GetStandingOrder(req.Context(), &standingOrder)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list the standing orders (active by default)
+func (h *HttpRouters) ListStandingOrder(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListStandingOrder").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ListStandingOrder")
+	defer span.End()
+
+	//parameters
+	params := req.URL.Query()
+	standingOrder := model.StandingOrder{}
+	standingOrder.Status = params.Get("status")
+
+	limit := 0
+	var err error
+	if params.Get("limit") != "" {
+		limit, err = strconv.Atoi(params.Get("limit"))
+		if err != nil {
+			return invalidRequest(err)
 		}
-		return &core_apiError
+	}
+
+	// call service
+	res, err := h.workerService.ListStandingOrder(req.Context(), &standingOrder, limit)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About cancel an active standing order
+func (h *HttpRouters) CancelStandingOrder(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","CancelStandingOrder").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.CancelStandingOrder")
+	defer span.End()
+
+	//parameters
+	vars := mux.Vars(req)
+	varID, err := strconv.Atoi(vars["id"]) 
+    if err != nil { 
+		return invalidRequest(err)
+    } 
+
+	standingOrder := model.StandingOrder{}
+	standingOrder.ID = varID
+
+	// call service
+	res, err := h.workerService.CancelStandingOrder(req.Context(), &standingOrder)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About add a batch of transfers
+func (h *HttpRouters) AddTransferBatch(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddTransferBatch").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.AddTransferBatch")
+	defer span.End()
+
+	//parameters
+	transferBatch := model.TransferBatch{}
+	err := json.NewDecoder(req.Body).Decode(&transferBatch)
+    if err != nil {
+		return invalidRequest(err)
+    }
+	for _, transfer := range transferBatch.Transfers {
+		if transfer != nil {
+			transfer.RequestedBy = req.Header.Get("X-User-Id")
+		}
+	}
+	req, err = bindIdempotency(req, &transferBatch)
+    if err != nil {
+		return invalidRequest(err)
+    }
+
+	// call service
+	res, err := h.workerService.AddTransferBatch(req.Context(), &transferBatch)
+	if err != nil {
+		if err == erro.ErrBatchInvalid {
+			// the per item errors of a rejected batch
+			return core_json.WriteJSON(rw, http.StatusUnprocessableEntity, res)
+		}
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get a batch with the results of its items
+func (h *HttpRouters) GetTransferBatch(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetTransferBatch").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.GetTransferBatch")
+	defer span.End()
+
+	//parameters
+	vars := mux.Vars(req)
+	varID, err := strconv.Atoi(vars["id"]) 
+    if err != nil { 
+		return invalidRequest(err)
+    } 
+
+	transferBatch := model.TransferBatch{}
+	transferBatch.ID = varID
+
+	// call service
+	res, err := h.workerService.GetTransferBatch(req.Context(), &transferBatch)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About create a fx quote
+func (h *HttpRouters) AddQuote(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddQuote").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.AddQuote")
+	defer span.End()
+
+	//parameters
+	quote := model.Quote{}
+	err := json.NewDecoder(req.Body).Decode(&quote)
+    if err != nil {
+		return invalidRequest(err)
+    }
+
+	// call service
+	res, err := h.workerService.AddQuote(req.Context(), &quote)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About set (add or replace) the limit of an account or a tenant in a currency
+func (h *HttpRouters) SetTransferLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","SetTransferLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.SetTransferLimit")
+	defer span.End()
+
+	//parameters
+	transferLimit := model.TransferLimit{}
+	err := json.NewDecoder(req.Body).Decode(&transferLimit)
+    if err != nil {
+		return invalidRequest(err)
+    }
+
+	// call service
+	res, err := h.workerService.SetTransferLimit(req.Context(), &transferLimit)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list the limits of an account or a tenant
+func (h *HttpRouters) ListTransferLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListTransferLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ListTransferLimit")
+	defer span.End()
+
+	//parameters
+	vars := mux.Vars(req)
+	transferLimit := model.TransferLimit{}
+	transferLimit.Scope = vars["scope"]
+	transferLimit.ScopeID = vars["id"]
+
+	// call service
+	res, err := h.workerService.ListTransferLimit(req.Context(), &transferLimit)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About delete the limit of an account or a tenant in a currency
+func (h *HttpRouters) DeleteTransferLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","DeleteTransferLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.DeleteTransferLimit")
+	defer span.End()
+
+	//parameters
+	vars := mux.Vars(req)
+	transferLimit := model.TransferLimit{}
+	transferLimit.Scope = vars["scope"]
+	transferLimit.ScopeID = vars["id"]
+	transferLimit.Currency = vars["currency"]
+
+	// call service
+	err := h.workerService.DeleteTransferLimit(req.Context(), &transferLimit)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, transferLimit)
+}
+
+// About get the risk decision of a transfer by its transaction_id
+func (h *HttpRouters) GetRiskDecision(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetRiskDecision").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.GetRiskDecision")
+	defer span.End()
+
+	//parameters
+	vars := mux.Vars(req)
+	riskDecision := model.RiskDecision{}
+	riskDecision.TransactionID = vars["id"]
+
+	// call service
+	res, err := h.workerService.GetRiskDecision(req.Context(), &riskDecision)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list the latest risk decisions by decision (default DENY) and account
+func (h *HttpRouters) ListRiskDecision(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListRiskDecision").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ListRiskDecision")
+	defer span.End()
+
+	//parameters
+	params := req.URL.Query()
+	riskDecision := model.RiskDecision{}
+	riskDecision.Decision = params.Get("decision")
+	riskDecision.AccountFrom = params.Get("account_id")
+
+	limit := 0
+	var err error
+	if params.Get("limit") != "" {
+		limit, err = strconv.Atoi(params.Get("limit"))
+		if err != nil {
+			return invalidRequest(err)
+		}
+	}
+
+	// call service
+	res, err := h.workerService.ListRiskDecision(req.Context(), &riskDecision, limit)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About the roles of the authenticated user (X-User-Roles, comma separated)
+func userRoles(req *http.Request) []string {
+	roles := []string{}
+	for _, role := range strings.Split(req.Header.Get("X-User-Roles"), ",") {
+		if strings.TrimSpace(role) != "" {
+			roles = append(roles, strings.TrimSpace(role))
+		}
+	}
+	return roles
+}
+
+// About decode the decision of a review, the reviewer is the authenticated user (X-User-Id) with its roles (X-User-Roles)
+func decodeReview(req *http.Request, transferReview *model.TransferReview) error {
+	vars := mux.Vars(req)
+	varID, err := strconv.Atoi(vars["id"]) 
+	if err != nil {
+		return err
+	}
+
+	decision := struct {
+		Reason	string	`json:"reason"`
+	}{}
+	if req.ContentLength != 0 {
+		err = json.NewDecoder(req.Body).Decode(&decision)
+		if err != nil {
+			return err
+		}
+	}
+
+	transferReview.ID = varID
+	transferReview.Reason = decision.Reason
+	transferReview.Reviewer = req.Header.Get("X-User-Id")
+	transferReview.ReviewerRoles = userRoles(req)
+	return nil
+}
+
+// About approve a transfer held for review, its original flow is resumed
+func (h *HttpRouters) ApproveReview(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ApproveReview").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ApproveReview")
+	defer span.End()
+
+	//parameters
+	transferReview := model.TransferReview{}
+	err := decodeReview(req, &transferReview)
+    if err != nil {
+		return invalidRequest(err)
+    }
+
+	// call service
+	res, err := h.workerService.ApproveReview(req.Context(), &transferReview)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About reject a transfer held for review with a reason
+func (h *HttpRouters) RejectReview(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","RejectReview").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.RejectReview")
+	defer span.End()
+
+	//parameters
+	transferReview := model.TransferReview{}
+	err := decodeReview(req, &transferReview)
+    if err != nil {
+		return invalidRequest(err)
+    }
+
+	// call service
+	res, err := h.workerService.RejectReview(req.Context(), &transferReview)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get a review with the held transfer and the reasons
+func (h *HttpRouters) GetTransferReview(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetTransferReview").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.GetTransferReview")
+	defer span.End()
+
+	//parameters
+	vars := mux.Vars(req)
+	varID, err := strconv.Atoi(vars["id"]) 
+    if err != nil { 
+		return invalidRequest(err)
+    } 
+
+	transferReview := model.TransferReview{}
+	transferReview.ID = varID
+	transferReview.Reviewer = req.Header.Get("X-User-Id")
+	transferReview.ReviewerRoles = userRoles(req)
+
+	// call service
+	res, err := h.workerService.GetTransferReview(req.Context(), &transferReview)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list the review queue by status (default PENDING)
+func (h *HttpRouters) ListTransferReview(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListTransferReview").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ListTransferReview")
+	defer span.End()
+
+	//parameters
+	params := req.URL.Query()
+	transferReview := model.TransferReview{}
+	transferReview.Status = params.Get("status")
+	transferReview.Reviewer = req.Header.Get("X-User-Id")
+	transferReview.ReviewerRoles = userRoles(req)
+
+	limit := 0
+	var err error
+	if params.Get("limit") != "" {
+		limit, err = strconv.Atoi(params.Get("limit"))
+		if err != nil {
+			return invalidRequest(err)
+		}
+	}
+
+	// call service
+	res, err := h.workerService.ListTransferReview(req.Context(), &transferReview, limit)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About decode the decision of an approval, the checker is the authenticated user (X-User-Id) with its roles (X-User-Roles)
+func decodeApproval(req *http.Request, transferApproval *model.TransferApproval) error {
+	vars := mux.Vars(req)
+	varID, err := strconv.Atoi(vars["id"]) 
+	if err != nil {
+		return err
+	}
+
+	decision := struct {
+		Reason	string	`json:"reason"`
+	}{}
+	if req.ContentLength != 0 {
+		err = json.NewDecoder(req.Body).Decode(&decision)
+		if err != nil {
+			return err
+		}
+	}
+
+	transferApproval.FkTransferID = varID
+	transferApproval.Reason = decision.Reason
+	transferApproval.Checker = req.Header.Get("X-User-Id")
+	transferApproval.CheckerRoles = userRoles(req)
+	return nil
+}
+
+// About approve a transfer awaiting approval, the flow it was submitted with is resumed
+func (h *HttpRouters) ApproveTransfer(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ApproveTransfer").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ApproveTransfer")
+	defer span.End()
+
+	//parameters
+	transferApproval := model.TransferApproval{}
+	err := decodeApproval(req, &transferApproval)
+    if err != nil {
+		return invalidRequest(err)
+    }
+
+	// call service
+	res, err := h.workerService.ApproveTransfer(req.Context(), &transferApproval)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About reject a transfer awaiting approval with a reason
+func (h *HttpRouters) RejectTransfer(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","RejectTransfer").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.RejectTransfer")
+	defer span.End()
+
+	//parameters
+	transferApproval := model.TransferApproval{}
+	err := decodeApproval(req, &transferApproval)
+    if err != nil {
+		return invalidRequest(err)
+    }
+
+	// call service
+	res, err := h.workerService.RejectTransfer(req.Context(), &transferApproval)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list the approval queue by status (default PENDING)
+func (h *HttpRouters) ListTransferApproval(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListTransferApproval").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ListTransferApproval")
+	defer span.End()
+
+	//parameters
+	params := req.URL.Query()
+	transferApproval := model.TransferApproval{}
+	transferApproval.Status = params.Get("status")
+
+	limit := 0
+	var err error
+	if params.Get("limit") != "" {
+		limit, err = strconv.Atoi(params.Get("limit"))
+		if err != nil {
+			return invalidRequest(err)
+		}
+	}
+
+	// call service
+	res, err := h.workerService.ListTransferApproval(req.Context(), &transferApproval, limit)
+	if err != nil {
+		return err
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About the circuit breaker and the bulkhead of each downstream service
+func (h *HttpRouters) ListDependencyStatus(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListDependencyStatus").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ListDependencyStatus")
+	defer span.End()
+
+	// call service
+	res, err := h.workerService.ListDependencyStatus(req.Context())
+	if err != nil {
+		return err
 	}
 	
 	return core_json.WriteJSON(rw, http.StatusOK, res)
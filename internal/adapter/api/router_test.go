@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"strings"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+func TestBindIdempotency(t *testing.T) {
+	tests := []struct {
+		name	string
+		key		string
+		err		error
+		bound	bool
+	}{
+		{ "no key", "", nil, false },
+		{ "client key", "client-key-1", nil, true },
+		{ "client key looking like the scheduler", "schedule-1", nil, true },
+		{ "too long", strings.Repeat("k", 256), erro.ErrInvalid, false },
+		{ "internal prefix", model.IdempotencyInternalPrefix + "schedule-1", erro.ErrInvalid, false },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/add/transferEvent", nil)
+			if tt.key != "" {
+				req.Header.Set("Idempotency-Key", tt.key)
+			}
+
+			req, err := bindIdempotency(req, &model.Transfer{ Type: "TRANSFER" })
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			idempotency, _ := req.Context().Value("idempotency-key").(*model.Idempotency)
+			if (idempotency != nil) != tt.bound {
+				t.Fatalf("bound = %v, want %v", idempotency != nil, tt.bound)
+			}
+			if tt.bound && (idempotency.Key != tt.key || len(idempotency.RequestHash) != 64) {
+				t.Errorf("idempotency = %+v, want the key %s and a sha256 hash", idempotency, tt.key)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package database
+
+import (
+	"time"
+	"context"
+	"errors"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About the select of an approval (scanned by scanTransferApproval)
+const transferApprovalQuery = `SELECT id,
+									fk_transfer_id,
+									transaction_id,
+									flow,
+									payload,
+									maker,
+									status,
+									checker,
+									reason,
+									decided_at,
+									created_at
+							FROM transfer_approval`
+
+// About scan a row of transferApprovalQuery
+func scanTransferApproval(rows pgx.Rows) (*model.TransferApproval, error) {
+	res_approval := model.TransferApproval{}
+	var payload []byte
+
+	err := rows.Scan(	&res_approval.ID,
+						&res_approval.FkTransferID,
+						&res_approval.TransactionID,
+						&res_approval.Flow,
+						&payload,
+						&res_approval.Maker,
+						&res_approval.Status,
+						&res_approval.Checker,
+						&res_approval.Reason,
+						&res_approval.DecidedAt,
+						&res_approval.CreatedAt)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	err = json.Unmarshal(payload, &res_approval.Transfer)
+	if err != nil {
+		return nil, erro.ErrUnmarshal
+	}
+
+	return &res_approval, nil
+}
+
+// About scan the rows of transferApprovalQuery
+func scanTransferApprovalList(rows pgx.Rows) (*[]model.TransferApproval, error) {
+	res_list := []model.TransferApproval{}
+	for rows.Next() {
+		res_approval, err := scanTransferApproval(rows)
+		if err != nil {
+			return nil, err
+		}
+		res_list = append(res_list, *res_approval)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err.Error())
+	}
+	return &res_list, nil
+}
+
+// About add an approval in the transaction of the held transfer
+func (w WorkerRepository) AddTransferApproval(ctx context.Context, tx port.Tx, transferApproval *model.TransferApproval) (*model.TransferApproval, error){
+	childLogger.Info().Str("func","AddTransferApproval").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferApproval",transferApproval).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddTransferApproval")
+	defer span.End()
+
+	// Prepare
+	var id int
+	transferApproval.CreatedAt = time.Now()
+
+	payload, err := json.Marshal(transferApproval.Transfer)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Query and Execute
+	query := `INSERT INTO transfer_approval(	fk_transfer_id,
+												transaction_id,
+												flow,
+												payload,
+												maker,
+												status,
+												created_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	transferApproval.FkTransferID,
+									transferApproval.TransactionID,
+									transferApproval.Flow,
+									payload,
+									transferApproval.Maker,
+									transferApproval.Status,
+									transferApproval.CreatedAt)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	transferApproval.ID = id
+	return transferApproval, nil
+}
+
+// About get the approval of a transfer locking the row until the end of the transaction, so it is decided only once
+func (w WorkerRepository) GetTransferApprovalForUpdate(ctx context.Context, tx port.Tx, transferApproval *model.TransferApproval) (*model.TransferApproval, error){
+	childLogger.Info().Str("func","GetTransferApprovalForUpdate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("fk_transfer_id", transferApproval.FkTransferID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetTransferApprovalForUpdate")
+	defer span.End()
+
+	// Query and Execute
+	query := transferApprovalQuery + `
+				WHERE fk_transfer_id = $1
+				FOR UPDATE`
+
+	rows, err := pgxTx(tx).Query(ctx, query, transferApproval.FkTransferID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res_list, err := scanTransferApprovalList(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(*res_list) == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return &(*res_list)[0], nil
+}
+
+// About list the approvals by status, the oldest first (the queue)
+func (w WorkerRepository) ListTransferApproval(ctx context.Context, transferApproval *model.TransferApproval, limit int) (*[]model.TransferApproval, error){
+	childLogger.Info().Str("func","ListTransferApproval").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("status", transferApproval.Status).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListTransferApproval")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := transferApprovalQuery + `
+				WHERE status = $1
+				ORDER BY created_at
+				LIMIT $2`
+
+	rows, err := conn.Query(ctx, query, transferApproval.Status, limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	return scanTransferApprovalList(rows)
+}
+
+// About record the decision of an approval
+func (w WorkerRepository) UpdateTransferApproval(ctx context.Context, tx port.Tx, transferApproval *model.TransferApproval) (int64, error){
+	childLogger.Info().Str("func","UpdateTransferApproval").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("id", transferApproval.ID).Str("status", transferApproval.Status).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateTransferApproval")
+	defer span.End()
+
+	// Query and Execute
+	query := `UPDATE transfer_approval
+				SET status = $2,
+					checker = $3,
+					reason = $4,
+					decided_at = $5
+				WHERE id = $1`
+
+	row, err := pgxTx(tx).Exec(ctx, query,	transferApproval.ID,
+									transferApproval.Status,
+									transferApproval.Checker,
+									transferApproval.Reason,
+									transferApproval.DecidedAt)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
@@ -0,0 +1,239 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About add a batch and its items (pending)
+func (w WorkerRepository) AddTransferBatch(ctx context.Context, tx port.Tx, transferBatch *model.TransferBatch) (*model.TransferBatch, error){
+	childLogger.Info().Str("func","AddTransferBatch").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("total", transferBatch.Total).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddTransferBatch")
+	defer span.End()
+
+	// Prepare
+	var id int
+	transferBatch.CreatedAt = time.Now()
+
+	// Query and Execute
+	query := `INSERT INTO transfer_batch(	status,
+											total,
+											succeeded,
+											held,
+											failed,
+											created_at)
+				VALUES($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	transferBatch.Status,
+									transferBatch.Total,
+									transferBatch.Succeeded,
+									transferBatch.Held,
+									transferBatch.Failed,
+									transferBatch.CreatedAt)
+
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	transferBatch.ID = id
+
+	queryItem := `INSERT INTO transfer_batch_item(	fk_batch_id,
+													sequence,
+													payload,
+													status)
+					VALUES($1, $2, $3, $4) RETURNING id`
+
+	for i := range transferBatch.Items {
+		item := &transferBatch.Items[i]
+		item.FkBatchID = id
+
+		payload, err := json.Marshal(item.Transfer)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		row := pgxTx(tx).QueryRow(ctx, queryItem,	item.FkBatchID,
+											item.Sequence,
+											payload,
+											item.Status)
+		if err := row.Scan(&item.ID); err != nil {
+			return nil, errors.New(err.Error())
+		}
+	}
+
+	return transferBatch, nil
+}
+
+// About update the result of an item of a batch
+func (w WorkerRepository) UpdateTransferBatchItem(ctx context.Context, item *model.TransferBatchItem) (int64, error){
+	childLogger.Info().Str("func","UpdateTransferBatchItem").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("item", item.ID).Str("status", item.Status).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateTransferBatchItem")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `UPDATE transfer_batch_item
+				SET status = $2,
+					fk_transfer_id = $3,
+					transaction_id = $4,
+					error_code = $5,
+					error_message = $6
+				WHERE id = $1`
+
+	row, err := conn.Exec(ctx, query,	item.ID,
+										item.Status,
+										item.FkTransferID,
+										item.TransactionID,
+										item.ErrorCode,
+										item.ErrorMessage)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About update the outcome of a batch
+func (w WorkerRepository) UpdateTransferBatch(ctx context.Context, transferBatch *model.TransferBatch) (int64, error){
+	childLogger.Info().Str("func","UpdateTransferBatch").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("batch", transferBatch.ID).Str("status", transferBatch.Status).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateTransferBatch")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `UPDATE transfer_batch
+				SET status = $2,
+					succeeded = $3,
+					held = $4,
+					failed = $5,
+					finished_at = $6
+				WHERE id = $1`
+
+	row, err := conn.Exec(ctx, query,	transferBatch.ID,
+										transferBatch.Status,
+										transferBatch.Succeeded,
+										transferBatch.Held,
+										transferBatch.Failed,
+										transferBatch.FinishedAt)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About get a batch with the results of its items
+func (w WorkerRepository) GetTransferBatch(ctx context.Context, transferBatch *model.TransferBatch) (*model.TransferBatch, error){
+	childLogger.Info().Str("func","GetTransferBatch").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetTransferBatch")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_batch := model.TransferBatch{}
+
+	// Query and Execute
+	query := `SELECT id,
+					status,
+					total,
+					succeeded,
+					held,
+					failed,
+					created_at,
+					finished_at
+				FROM transfer_batch
+				WHERE id = $1`
+
+	err = conn.QueryRow(ctx, query, transferBatch.ID).Scan(	&res_batch.ID,
+															&res_batch.Status,
+															&res_batch.Total,
+															&res_batch.Succeeded,
+															&res_batch.Held,
+															&res_batch.Failed,
+															&res_batch.CreatedAt,
+															&res_batch.FinishedAt)
+	if err == pgx.ErrNoRows {
+		return nil, erro.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	queryItem := `SELECT id,
+						fk_batch_id,
+						sequence,
+						fk_transfer_id,
+						transaction_id,
+						status,
+						error_code,
+						error_message
+					FROM transfer_batch_item
+					WHERE fk_batch_id = $1
+					ORDER BY sequence`
+
+	rows, err := conn.Query(ctx, queryItem, res_batch.ID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := model.TransferBatchItem{}
+		err := rows.Scan(	&item.ID,
+							&item.FkBatchID,
+							&item.Sequence,
+							&item.FkTransferID,
+							&item.TransactionID,
+							&item.Status,
+							&item.ErrorCode,
+							&item.ErrorMessage,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_batch.Items = append(res_batch.Items, item)
+	}
+
+	return &res_batch, nil
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+// About mark an event as processed inside the transaction of the status update, returns false for a duplicated event
+func (w WorkerRepository) AddProcessedEvent(ctx context.Context, tx port.Tx, transferEvent *model.TransferEvent) (bool, error){
+	childLogger.Info().Str("func","AddProcessedEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferEvent",transferEvent).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddProcessedEvent")
+	defer span.End()
+
+	// Query and Execute
+	query := `INSERT INTO transfer_event_processed(	transaction_id,
+													event_type,
+													processed_at)
+				VALUES($1, $2, $3)
+				ON CONFLICT (transaction_id, event_type) DO NOTHING`
+
+	res, err := pgxTx(tx).Exec(ctx, query,	transferEvent.TransactionID,
+									transferEvent.EventType,
+									time.Now())
+	if err != nil {
+		return false, errors.New(err.Error())
+	}
+
+	return res.RowsAffected() == 1, nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About reserve an idempotency key inside the transaction, returns false when the key already exists
+func (w WorkerRepository) AddIdempotencyKey(ctx context.Context, tx port.Tx, idempotency *model.Idempotency) (bool, error){
+	childLogger.Info().Str("func","AddIdempotencyKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("idempotency",idempotency).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddIdempotencyKey")
+	defer span.End()
+
+	// Prepare
+	idempotency.CreatedAt = time.Now()
+
+	// Query and Execute
+	// A concurrent transaction holding the same key blocks here until it commits or rollbacks
+	query := `INSERT INTO transfer_idempotency(	idempotency_key,
+												request_hash,
+												created_at)
+				VALUES($1, $2, $3)
+				ON CONFLICT (idempotency_key) DO NOTHING`
+
+	res, err := pgxTx(tx).Exec(ctx, query,	idempotency.Key,
+									idempotency.RequestHash,
+									idempotency.CreatedAt)
+	if err != nil {
+		return false, errors.New(err.Error())
+	}
+
+	return res.RowsAffected() == 1, nil
+}
+
+// About get a stored idempotency key
+func (w WorkerRepository) GetIdempotencyKey(ctx context.Context, tx port.Tx, idempotency *model.Idempotency) (*model.Idempotency, error){
+	childLogger.Info().Str("func","GetIdempotencyKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetIdempotencyKey")
+	defer span.End()
+
+	// Prepare
+	res_idempotency := model.Idempotency{}
+
+	// Query and Execute
+	query := `SELECT idempotency_key,
+					request_hash,
+					response,
+					created_at
+				FROM transfer_idempotency
+				WHERE idempotency_key = $1`
+
+	rows, err := pgxTx(tx).Query(ctx, query, idempotency.Key)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err := rows.Scan(	&res_idempotency.Key,
+							&res_idempotency.RequestHash,
+							&res_idempotency.Response,
+							&res_idempotency.CreatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_idempotency, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About store the response of an idempotency key
+func (w WorkerRepository) UpdateIdempotencyResponse(ctx context.Context, tx port.Tx, idempotency *model.Idempotency) (int64, error){
+	childLogger.Info().Str("func","UpdateIdempotencyResponse").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateIdempotencyResponse")
+	defer span.End()
+
+	// Query and Execute
+	query := `UPDATE transfer_idempotency
+				SET response = $2
+				WHERE idempotency_key = $1`
+
+	row, err := pgxTx(tx).Exec(ctx, query,	idempotency.Key,
+									idempotency.Response)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
@@ -0,0 +1,316 @@
+package database
+
+import (
+	"time"
+	"context"
+	"errors"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// About the select of the limits (scanned by scanTransferLimit)
+const transferLimitQuery = `SELECT 	id,
+									scope,
+									scope_id,
+									currency,
+									max_per_transaction,
+									daily_amount,
+									monthly_amount,
+									daily_count,
+									monthly_count,
+									approval_threshold,
+									updated_at
+							FROM transfer_limit`
+
+// About scan a row of transferLimitQuery
+func scanTransferLimit(rows pgx.Rows) (*model.TransferLimit, error) {
+	res_limit := model.TransferLimit{}
+	var max_per_transaction, daily_amount, monthly_amount, approval_threshold pgtype.Numeric
+
+	err := rows.Scan(	&res_limit.ID,
+						&res_limit.Scope,
+						&res_limit.ScopeID,
+						&res_limit.Currency,
+						&max_per_transaction,
+						&daily_amount,
+						&monthly_amount,
+						&res_limit.DailyCount,
+						&res_limit.MonthlyCount,
+						&approval_threshold,
+						&res_limit.UpdatedAt)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	res_limit.MaxPerTransaction, err = numericToMoney(max_per_transaction, res_limit.Currency)
+	if err != nil {
+		return nil, err
+	}
+	res_limit.DailyAmount, err = numericToMoney(daily_amount, res_limit.Currency)
+	if err != nil {
+		return nil, err
+	}
+	res_limit.MonthlyAmount, err = numericToMoney(monthly_amount, res_limit.Currency)
+	if err != nil {
+		return nil, err
+	}
+	res_limit.ApprovalThreshold, err = numericToMoney(approval_threshold, res_limit.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res_limit, nil
+}
+
+// About add or replace the limit of a scope (account or tenant) in a currency
+func (w WorkerRepository) SetTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) (*model.TransferLimit, error){
+	childLogger.Info().Str("func","SetTransferLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferLimit",transferLimit).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.SetTransferLimit")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	var id int
+	transferLimit.UpdatedAt = time.Now()
+
+	// Query and Execute
+	query := `INSERT INTO transfer_limit(	scope,
+											scope_id,
+											currency,
+											max_per_transaction,
+											daily_amount,
+											monthly_amount,
+											daily_count,
+											monthly_count,
+											approval_threshold,
+											updated_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+				ON CONFLICT (scope, scope_id, currency) DO UPDATE
+				SET max_per_transaction = EXCLUDED.max_per_transaction,
+					daily_amount = EXCLUDED.daily_amount,
+					monthly_amount = EXCLUDED.monthly_amount,
+					daily_count = EXCLUDED.daily_count,
+					monthly_count = EXCLUDED.monthly_count,
+					approval_threshold = EXCLUDED.approval_threshold,
+					updated_at = EXCLUDED.updated_at
+				RETURNING id`
+
+	row := conn.QueryRow(ctx, query,	transferLimit.Scope,
+										transferLimit.ScopeID,
+										transferLimit.Currency,
+										moneyToNumeric(transferLimit.MaxPerTransaction),
+										moneyToNumeric(transferLimit.DailyAmount),
+										moneyToNumeric(transferLimit.MonthlyAmount),
+										transferLimit.DailyCount,
+										transferLimit.MonthlyCount,
+										moneyToNumeric(transferLimit.ApprovalThreshold),
+										transferLimit.UpdatedAt)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	transferLimit.ID = id
+	return transferLimit, nil
+}
+
+// About list the limits of a scope (account or tenant), all currencies
+func (w WorkerRepository) ListTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) (*[]model.TransferLimit, error){
+	childLogger.Info().Str("func","ListTransferLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferLimit",transferLimit).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListTransferLimit")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := transferLimitQuery + `
+				WHERE scope = $1
+				and scope_id = $2
+				ORDER BY currency`
+
+	rows, err := conn.Query(ctx, query, transferLimit.Scope, transferLimit.ScopeID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res_list := []model.TransferLimit{}
+	for rows.Next() {
+		res_limit, err := scanTransferLimit(rows)
+		if err != nil {
+			return nil, err
+		}
+		res_list = append(res_list, *res_limit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return &res_list, nil
+}
+
+// About delete the limit of a scope in a currency
+func (w WorkerRepository) DeleteTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) (int64, error){
+	childLogger.Info().Str("func","DeleteTransferLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferLimit",transferLimit).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.DeleteTransferLimit")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `DELETE FROM transfer_limit
+				WHERE scope = $1
+				and scope_id = $2
+				and upper(currency) = upper($3)`
+
+	row, err := conn.Exec(ctx, query, transferLimit.Scope, transferLimit.ScopeID, transferLimit.Currency)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrNotFound
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About get the limits of an account and of its tenant in a currency (any case, "brl" matches "BRL").
+// The scopes are locked (advisory lock) until the end of the transaction, so concurrent transfers of the
+// same account or tenant are evaluated one after the other against the usage
+func (w WorkerRepository) GetTransferLimitForUpdate(ctx context.Context, tx port.Tx, accountID string, tenantID string, currency string) (*[]model.TransferLimit, error){
+	childLogger.Info().Str("func","GetTransferLimitForUpdate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("account_id", accountID).Str("tenant_id", tenantID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetTransferLimitForUpdate")
+	defer span.End()
+
+	// Lock the scopes, the tenant first to keep the same order in all the transactions
+	query_lock := `SELECT pg_advisory_xact_lock(hashtext($1))`
+	if tenantID != "" {
+		_, err := pgxTx(tx).Exec(ctx, query_lock, model.LimitScopeTenant + ":" + tenantID)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+	}
+	_, err := pgxTx(tx).Exec(ctx, query_lock, model.LimitScopeAccount + ":" + accountID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Query and Execute
+	query := transferLimitQuery + `
+				WHERE upper(currency) = upper($1)
+				and ((scope = $2 and scope_id = $3) or (scope = $4 and scope_id = $5))`
+
+	rows, err := pgxTx(tx).Query(ctx, query,	currency,
+										model.LimitScopeAccount,
+										accountID,
+										model.LimitScopeTenant,
+										tenantID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res_list := []model.TransferLimit{}
+	for rows.Next() {
+		res_limit, err := scanTransferLimit(rows)
+		if err != nil {
+			return nil, err
+		}
+		res_list = append(res_list, *res_limit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return &res_list, nil
+}
+
+// About the outgoing amount and count of a scope in a currency since the start of the day and of the month (UTC).
+// Credits (by type_charge, whatever their status), failed or reversed transfers and transfers closed by the review
+// or the approval are not counted, a held transfer keeps its allowance
+func (w WorkerRepository) GetLimitUsage(ctx context.Context, tx port.Tx, transferLimit *model.TransferLimit, dayStart time.Time, monthStart time.Time) (*model.LimitUsage, error){
+	childLogger.Info().Str("func","GetLimitUsage").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("scope", transferLimit.Scope).Str("scope_id", transferLimit.ScopeID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetLimitUsage")
+	defer span.End()
+
+	// Prepare
+	res_usage := model.LimitUsage{}
+	var daily_amount, monthly_amount pgtype.Numeric
+
+	scope_column := "fr.account_id"
+	if transferLimit.Scope == model.LimitScopeTenant {
+		scope_column = "fr.tenant_id"
+	}
+
+	// Query and Execute
+	query := `SELECT COALESCE(SUM(ABS(trans.amount)) FILTER (WHERE trans.transfer_at >= $3), 0),
+					COUNT(*) FILTER (WHERE trans.transfer_at >= $3),
+					COALESCE(SUM(ABS(trans.amount)), 0),
+					COUNT(*)
+				FROM transfer_moviment as trans,
+					account as fr
+				WHERE trans.fk_account_id_from = fr.id
+				and ` + scope_column + ` = $1
+				and upper(trans.currency) = upper($2)
+				and trans.transfer_at >= $4
+				and trans.type_charge IS DISTINCT FROM $5
+				and trans.status NOT IN ($6, $7, $8, $9, $10)`
+
+	err := pgxTx(tx).QueryRow(ctx, query,	transferLimit.ScopeID,
+									transferLimit.Currency,
+									dayStart,
+									monthStart,
+									"CREDIT",
+									model.StatusTransferFailed,
+									model.StatusTransferReversed,
+									model.StatusReviewRejected,
+									model.StatusReviewExpired,
+									model.StatusApprovalRejected).Scan(	&daily_amount,
+																		&res_usage.DailyCount,
+																		&monthly_amount,
+																		&res_usage.MonthlyCount)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	res_usage.DailyAmount, err = numericToMoney(daily_amount, transferLimit.Currency)
+	if err != nil {
+		return nil, err
+	}
+	res_usage.MonthlyAmount, err = numericToMoney(monthly_amount, transferLimit.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res_usage, nil
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"time"
+	"sort"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About a copy of a stored approval (called with the lock held)
+func copyTransferApproval(transferApproval model.TransferApproval) (model.TransferApproval, error) {
+	payload, err := copyPayload(transferApproval.Transfer)
+	if err != nil {
+		return transferApproval, err
+	}
+	transferApproval.Transfer = payload
+	transferApproval.CheckerRoles = nil
+	transferApproval.DecidedAt = copyTime(transferApproval.DecidedAt)
+	return transferApproval, nil
+}
+
+// About add an approval in the transaction of the held transfer
+func (r *TransferRepository) AddTransferApproval(ctx context.Context, tx port.Tx, transferApproval *model.TransferApproval) (*model.TransferApproval, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	transferApproval.CreatedAt = time.Now()
+
+	res_approval, err := copyTransferApproval(*transferApproval)
+	if err != nil {
+		return nil, err
+	}
+	res_approval.Checker = ""
+	res_approval.Reason = ""
+	res_approval.DecidedAt = nil
+
+	transferApproval.ID = r.nextID("transfer_approval")
+	res_approval.ID = transferApproval.ID
+
+	id := transferApproval.ID
+	r.approvals[id] = res_approval
+	t.onRollback(func() { delete(r.approvals, id) })
+
+	return transferApproval, nil
+}
+
+// About get the approval of a transfer (the postgres one locks the row until the end of the transaction)
+func (r *TransferRepository) GetTransferApprovalForUpdate(ctx context.Context, tx port.Tx, transferApproval *model.TransferApproval) (*model.TransferApproval, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	res_approval := model.TransferApproval{}
+	for _, stored := range r.approvals {
+		if stored.FkTransferID == transferApproval.FkTransferID && (!found || stored.ID < res_approval.ID) {
+			found = true
+			res_approval = stored
+		}
+	}
+	if !found {
+		return nil, erro.ErrNotFound
+	}
+	res_approval, err = copyTransferApproval(res_approval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res_approval, nil
+}
+
+// About list the approvals by status, the oldest first (the queue)
+func (r *TransferRepository) ListTransferApproval(ctx context.Context, transferApproval *model.TransferApproval, limit int) (*[]model.TransferApproval, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_list := []model.TransferApproval{}
+	for _, stored := range r.approvals {
+		if stored.Status == transferApproval.Status {
+			res_list = append(res_list, stored)
+		}
+	}
+	sort.Slice(res_list, func(i, j int) bool { return res_list[i].CreatedAt.Before(res_list[j].CreatedAt) })
+	if len(res_list) > limit {
+		res_list = res_list[:limit]
+	}
+
+	for i := range res_list {
+		res_approval, err := copyTransferApproval(res_list[i])
+		if err != nil {
+			return nil, err
+		}
+		res_list[i] = res_approval
+	}
+
+	return &res_list, nil
+}
+
+// About record the decision of an approval
+func (r *TransferRepository) UpdateTransferApproval(ctx context.Context, tx port.Tx, transferApproval *model.TransferApproval) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	old, ok := r.approvals[transferApproval.ID]
+	if !ok {
+		return 0, erro.ErrUpdateRows
+	}
+	res_approval := old
+	res_approval.Status = transferApproval.Status
+	res_approval.Checker = transferApproval.Checker
+	res_approval.Reason = transferApproval.Reason
+	res_approval.DecidedAt = copyTime(transferApproval.DecidedAt)
+	r.approvals[old.ID] = res_approval
+	t.onRollback(func() { r.approvals[old.ID] = old })
+
+	return 1, nil
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"time"
+	"sort"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About add a batch and its items (pending)
+func (r *TransferRepository) AddTransferBatch(ctx context.Context, tx port.Tx, transferBatch *model.TransferBatch) (*model.TransferBatch, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	transferBatch.CreatedAt = time.Now()
+	transferBatch.ID = r.nextID("transfer_batch")
+
+	id := transferBatch.ID
+	r.batches[id] = model.TransferBatch{	ID: id,
+											Status: transferBatch.Status,
+											Total: transferBatch.Total,
+											Succeeded: transferBatch.Succeeded,
+											Held: transferBatch.Held,
+											Failed: transferBatch.Failed,
+											CreatedAt: transferBatch.CreatedAt }
+	t.onRollback(func() { delete(r.batches, id) })
+
+	for i := range transferBatch.Items {
+		item := &transferBatch.Items[i]
+		item.FkBatchID = id
+		item.ID = r.nextID("transfer_batch_item")
+
+		item_id := item.ID
+		r.batchItems[item_id] = model.TransferBatchItem{	ID: item_id,
+															FkBatchID: id,
+															Sequence: item.Sequence,
+															Status: item.Status }
+		t.onRollback(func() { delete(r.batchItems, item_id) })
+	}
+
+	return transferBatch, nil
+}
+
+// About update the result of an item of a batch
+func (r *TransferRepository) UpdateTransferBatchItem(ctx context.Context, item *model.TransferBatchItem) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_item, ok := r.batchItems[item.ID]
+	if !ok {
+		return 0, erro.ErrUpdateRows
+	}
+	res_item.Status = item.Status
+	res_item.FkTransferID = copyInt(item.FkTransferID)
+	res_item.TransactionID = copyString(item.TransactionID)
+	res_item.ErrorCode = item.ErrorCode
+	res_item.ErrorMessage = item.ErrorMessage
+	r.batchItems[item.ID] = res_item
+
+	return 1, nil
+}
+
+// About update the outcome of a batch
+func (r *TransferRepository) UpdateTransferBatch(ctx context.Context, transferBatch *model.TransferBatch) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_batch, ok := r.batches[transferBatch.ID]
+	if !ok {
+		return 0, erro.ErrUpdateRows
+	}
+	res_batch.Status = transferBatch.Status
+	res_batch.Succeeded = transferBatch.Succeeded
+	res_batch.Held = transferBatch.Held
+	res_batch.Failed = transferBatch.Failed
+	res_batch.FinishedAt = copyTime(transferBatch.FinishedAt)
+	r.batches[transferBatch.ID] = res_batch
+
+	return 1, nil
+}
+
+// About get a batch with the results of its items
+func (r *TransferRepository) GetTransferBatch(ctx context.Context, transferBatch *model.TransferBatch) (*model.TransferBatch, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_batch, ok := r.batches[transferBatch.ID]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+	res_batch.FinishedAt = copyTime(res_batch.FinishedAt)
+
+	for _, item := range r.batchItems {
+		if item.FkBatchID == res_batch.ID {
+			item.FkTransferID = copyInt(item.FkTransferID)
+			item.TransactionID = copyString(item.TransactionID)
+			res_batch.Items = append(res_batch.Items, item)
+		}
+	}
+	sort.Slice(res_batch.Items, func(i, j int) bool { return res_batch.Items[i].Sequence < res_batch.Items[j].Sequence })
+
+	return &res_batch, nil
+}
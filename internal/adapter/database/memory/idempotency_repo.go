@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"time"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About reserve an idempotency key inside the transaction, returns false when the key already exists
+func (r *TransferRepository) AddIdempotencyKey(ctx context.Context, tx port.Tx, idempotency *model.Idempotency) (bool, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return false, err
+	}
+
+	idempotency.CreatedAt = time.Now()
+
+	key := idempotency.Key
+	if _, ok := r.idempotencyKeys[key]; ok {
+		return false, nil
+	}
+	r.idempotencyKeys[key] = model.Idempotency{	Key: key,
+												RequestHash: idempotency.RequestHash,
+												CreatedAt: idempotency.CreatedAt }
+	t.onRollback(func() { delete(r.idempotencyKeys, key) })
+
+	return true, nil
+}
+
+// About get a stored idempotency key
+func (r *TransferRepository) GetIdempotencyKey(ctx context.Context, tx port.Tx, idempotency *model.Idempotency) (*model.Idempotency, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	res_idempotency, ok := r.idempotencyKeys[idempotency.Key]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+	res_idempotency.Response = append([]byte(nil), res_idempotency.Response...)
+
+	return &res_idempotency, nil
+}
+
+// About store the response of an idempotency key
+func (r *TransferRepository) UpdateIdempotencyResponse(ctx context.Context, tx port.Tx, idempotency *model.Idempotency) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	old, ok := r.idempotencyKeys[idempotency.Key]
+	if !ok {
+		return 0, erro.ErrUpdateRows
+	}
+	res_idempotency := old
+	res_idempotency.Response = append([]byte(nil), idempotency.Response...)
+	r.idempotencyKeys[old.Key] = res_idempotency
+	t.onRollback(func() { r.idempotencyKeys[old.Key] = old })
+
+	return 1, nil
+}
+
+// About mark an event as processed inside the transaction of the status update, returns false for a duplicated event
+func (r *TransferRepository) AddProcessedEvent(ctx context.Context, tx port.Tx, transferEvent *model.TransferEvent) (bool, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return false, err
+	}
+
+	key := transferEvent.TransactionID + ":" + transferEvent.EventType
+	if r.processedEvents[key] {
+		return false, nil
+	}
+	r.processedEvents[key] = true
+	t.onRollback(func() { delete(r.processedEvents, key) })
+
+	return true, nil
+}
@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"time"
+	"sort"
+	"context"
+	"strings"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About the statuses not counted as outgoing movements by the limits and the risk rules: failed or reversed
+// transfers and transfers closed by the review or the approval
+var notOutgoingStatus = []model.TransferStatus{	model.StatusTransferFailed,
+												model.StatusTransferReversed,
+												model.StatusReviewRejected,
+												model.StatusReviewExpired,
+												model.StatusApprovalRejected }
+
+// About a movement counted by the limits and the risk rules, the credits are excluded by type whatever their status
+func isOutgoing(transfer model.Transfer) bool {
+	return transfer.Type != "CREDIT" && !hasStatus(transfer.Status, notOutgoingStatus)
+}
+
+func hasStatus(status model.TransferStatus, statusList []model.TransferStatus) bool {
+	for _, s := range statusList {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// About the absolute amount in the minor unit
+func absMinor(money model.Money) int64 {
+	if money.Minor < 0 {
+		return -money.Minor
+	}
+	return money.Minor
+}
+
+// About add or replace the limit of a scope (account or tenant) in a currency
+func (r *TransferRepository) SetTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) (*model.TransferLimit, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transferLimit.UpdatedAt = time.Now()
+
+	id := 0
+	for _, res_limit := range r.limits {
+		if res_limit.Scope == transferLimit.Scope && res_limit.ScopeID == transferLimit.ScopeID && res_limit.Currency == transferLimit.Currency {
+			id = res_limit.ID
+		}
+	}
+	if id == 0 {
+		id = r.nextID("transfer_limit")
+	}
+
+	transferLimit.ID = id
+	r.limits[id] = *transferLimit
+
+	return transferLimit, nil
+}
+
+// About list the limits of a scope (account or tenant), all currencies
+func (r *TransferRepository) ListTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) (*[]model.TransferLimit, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_list := []model.TransferLimit{}
+	for _, res_limit := range r.limits {
+		if res_limit.Scope == transferLimit.Scope && res_limit.ScopeID == transferLimit.ScopeID {
+			res_list = append(res_list, res_limit)
+		}
+	}
+	sort.Slice(res_list, func(i, j int) bool { return res_list[i].Currency < res_list[j].Currency })
+
+	return &res_list, nil
+}
+
+// About delete the limit of a scope in a currency
+func (r *TransferRepository) DeleteTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, res_limit := range r.limits {
+		if res_limit.Scope == transferLimit.Scope && res_limit.ScopeID == transferLimit.ScopeID && strings.EqualFold(res_limit.Currency, transferLimit.Currency) {
+			delete(r.limits, id)
+			return 1, nil
+		}
+	}
+
+	return 0, erro.ErrNotFound
+}
+
+// About get the limits of an account and of its tenant in a currency (the postgres one locks the scopes)
+func (r *TransferRepository) GetTransferLimitForUpdate(ctx context.Context, tx port.Tx, accountID string, tenantID string, currency string) (*[]model.TransferLimit, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	res_list := []model.TransferLimit{}
+	for _, res_limit := range r.limits {
+		if !strings.EqualFold(res_limit.Currency, currency) {
+			continue
+		}
+		if (res_limit.Scope == model.LimitScopeAccount && res_limit.ScopeID == accountID) ||
+			(res_limit.Scope == model.LimitScopeTenant && res_limit.ScopeID == tenantID) {
+			res_list = append(res_list, res_limit)
+		}
+	}
+	sort.Slice(res_list, func(i, j int) bool { return res_list[i].ID < res_list[j].ID })
+
+	return &res_list, nil
+}
+
+// About the outgoing amount and count of a scope in a currency since the start of the day and of the month
+func (r *TransferRepository) GetLimitUsage(ctx context.Context, tx port.Tx, transferLimit *model.TransferLimit, dayStart time.Time, monthStart time.Time) (*model.LimitUsage, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	res_usage := model.LimitUsage{}
+	var daily_amount, monthly_amount int64
+	for _, row := range r.transfers {
+		scope_id := row.transfer.AccountFrom.AccountID
+		if transferLimit.Scope == model.LimitScopeTenant {
+			scope_id = row.tenantID
+		}
+		if scope_id != transferLimit.ScopeID ||
+			!strings.EqualFold(row.transfer.Currency, transferLimit.Currency) ||
+			row.transfer.TransferAt.Before(monthStart) ||
+			!isOutgoing(row.transfer) {
+			continue
+		}
+
+		monthly_amount = monthly_amount + absMinor(row.transfer.Amount)
+		res_usage.MonthlyCount = res_usage.MonthlyCount + 1
+		if !row.transfer.TransferAt.Before(dayStart) {
+			daily_amount = daily_amount + absMinor(row.transfer.Amount)
+			res_usage.DailyCount = res_usage.DailyCount + 1
+		}
+	}
+
+	res_usage.DailyAmount, err = model.NewMoney(daily_amount, transferLimit.Currency)
+	if err != nil {
+		return nil, err
+	}
+	res_usage.MonthlyAmount, err = model.NewMoney(monthly_amount, transferLimit.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res_usage, nil
+}
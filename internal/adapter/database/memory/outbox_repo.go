@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"time"
+	"sort"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About add an event into the outbox, inside the transaction of the transfer
+func (r *TransferRepository) AddOutbox(ctx context.Context, tx port.Tx, outbox *model.Outbox) (*model.Outbox, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	outbox.Status = model.OutboxPending
+	outbox.CreatedAt = time.Now()
+	outbox.ID = r.nextID("outbox")
+
+	res_outbox := *outbox
+	res_outbox.Payload = append([]byte(nil), outbox.Payload...)
+	res_outbox.SentAt = nil
+
+	id := outbox.ID
+	r.outbox[id] = res_outbox
+	t.onRollback(func() { delete(r.outbox, id) })
+
+	return outbox, nil
+}
+
+// About list the pending events
+func (r *TransferRepository) ListOutboxPending(ctx context.Context, tx port.Tx, limit int) (*[]model.Outbox, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	res_list := []model.Outbox{}
+	for _, outbox := range r.outbox {
+		if outbox.Status == model.OutboxPending {
+			outbox.Payload = append([]byte(nil), outbox.Payload...)
+			res_list = append(res_list, outbox)
+		}
+	}
+	sort.Slice(res_list, func(i, j int) bool { return res_list[i].ID < res_list[j].ID })
+	if len(res_list) > limit {
+		res_list = res_list[:limit]
+	}
+
+	return &res_list, nil
+}
+
+// About update the status, attempts and error of an outbox event
+func (r *TransferRepository) UpdateOutbox(ctx context.Context, tx port.Tx, outbox *model.Outbox) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	old, ok := r.outbox[outbox.ID]
+	if !ok {
+		return 0, erro.ErrUpdateRows
+	}
+	res_outbox := old
+	res_outbox.Status = outbox.Status
+	res_outbox.Attempts = outbox.Attempts
+	res_outbox.LastError = outbox.LastError
+	res_outbox.SentAt = copyTime(outbox.SentAt)
+	r.outbox[old.ID] = res_outbox
+	t.onRollback(func() { r.outbox[old.ID] = old })
+
+	return 1, nil
+}
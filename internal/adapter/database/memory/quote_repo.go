@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"errors"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About add a quote
+func (r *TransferRepository) AddQuote(ctx context.Context, quote *model.Quote) (*model.Quote, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.quotes[quote.ID]; ok {
+		return nil, errors.New("duplicate key value violates unique constraint fx_quote_pkey")
+	}
+
+	res_quote := *quote
+	res_quote.UsedAt = nil
+	res_quote.TransactionID = nil
+	r.quotes[quote.ID] = res_quote
+
+	return quote, nil
+}
+
+// About get a quote (the postgres one locks the row until the end of the transaction)
+func (r *TransferRepository) GetQuoteForUpdate(ctx context.Context, tx port.Tx, quote *model.Quote) (*model.Quote, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	res_quote, ok := r.quotes[quote.ID]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+	res_quote.UsedAt = copyTime(res_quote.UsedAt)
+	res_quote.TransactionID = copyString(res_quote.TransactionID)
+
+	return &res_quote, nil
+}
+
+// About mark a quote as used by a transfer
+func (r *TransferRepository) UpdateQuote(ctx context.Context, tx port.Tx, quote *model.Quote) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	old, ok := r.quotes[quote.ID]
+	if !ok {
+		return 0, erro.ErrUpdateRows
+	}
+	res_quote := old
+	res_quote.Status = quote.Status
+	res_quote.UsedAt = copyTime(quote.UsedAt)
+	res_quote.TransactionID = copyString(quote.TransactionID)
+	r.quotes[old.ID] = res_quote
+	t.onRollback(func() { r.quotes[old.ID] = old })
+
+	return 1, nil
+}
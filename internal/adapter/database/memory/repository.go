@@ -0,0 +1,200 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+	"sync"
+	"errors"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/rs/zerolog/log"
+)
+
+var childLogger = log.With().Str("component","go-fund-transfer").Str("package","internal.adapter.database.memory").Logger()
+
+var _ port.TransferRepository = (*TransferRepository)(nil)
+
+// About the error of a unit of work used after its Commit or Rollback
+var errTxClosed = errors.New("tx is closed")
+
+// About an in-memory TransferRepository for the tests of the use cases, it keeps the same rows and
+// returns the same errors as the postgres one (database.WorkerRepository).
+// A write in a unit of work is visible at once and undone by its Rollback, there are no row locks
+// (the units of work of concurrent tests are not isolated from each other).
+// CommitErr forces a failure of the commits, their writes are undone as by a failed postgres commit
+type TransferRepository struct {
+	mu						sync.Mutex
+	CommitErr				error
+	sequence				map[string]int
+	transfers				map[int]transferRow
+	statusTransitions		map[int]model.StatusTransition
+	idempotencyKeys			map[string]model.Idempotency
+	processedEvents			map[string]bool
+	sagas					map[int]model.Saga
+	sagaSteps				map[int]model.SagaStep
+	outbox					map[int]model.Outbox
+	schedules				map[int]model.Schedule
+	standingOrders			map[int]model.StandingOrder
+	occurrences				map[int]model.StandingOrderOccurrence
+	batches					map[int]model.TransferBatch
+	batchItems				map[int]model.TransferBatchItem
+	quotes					map[string]model.Quote
+	limits					map[int]model.TransferLimit
+	riskDecisions			map[int]model.RiskDecision
+	reviews					map[int]model.TransferReview
+	approvals				map[int]model.TransferApproval
+}
+
+func NewTransferRepository() *TransferRepository{
+	childLogger.Info().Str("func","NewTransferRepository").Send()
+
+	return &TransferRepository{
+		sequence: map[string]int{},
+		transfers: map[int]transferRow{},
+		statusTransitions: map[int]model.StatusTransition{},
+		idempotencyKeys: map[string]model.Idempotency{},
+		processedEvents: map[string]bool{},
+		sagas: map[int]model.Saga{},
+		sagaSteps: map[int]model.SagaStep{},
+		outbox: map[int]model.Outbox{},
+		schedules: map[int]model.Schedule{},
+		standingOrders: map[int]model.StandingOrder{},
+		occurrences: map[int]model.StandingOrderOccurrence{},
+		batches: map[int]model.TransferBatch{},
+		batchItems: map[int]model.TransferBatchItem{},
+		quotes: map[string]model.Quote{},
+		limits: map[int]model.TransferLimit{},
+		riskDecisions: map[int]model.RiskDecision{},
+		reviews: map[int]model.TransferReview{},
+		approvals: map[int]model.TransferApproval{},
+	}
+}
+
+// About a unit of work, the undo of its writes in the order they were done
+type memoryTx struct {
+	repository	*TransferRepository
+	undo		[]func()
+	closed		bool
+}
+
+func (t *memoryTx) Commit(ctx context.Context) error {
+	t.repository.mu.Lock()
+	defer t.repository.mu.Unlock()
+
+	if t.closed {
+		return errTxClosed
+	}
+	t.closed = true
+	if t.repository.CommitErr != nil {
+		for i := len(t.undo) - 1; i >= 0; i-- {
+			t.undo[i]()
+		}
+		t.undo = nil
+		return t.repository.CommitErr
+	}
+	t.undo = nil
+	return nil
+}
+
+func (t *memoryTx) Rollback(ctx context.Context) error {
+	t.repository.mu.Lock()
+	defer t.repository.mu.Unlock()
+
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+	t.undo = nil
+	return nil
+}
+
+// About record how to undo a write
+func (t *memoryTx) onRollback(undo func()) {
+	t.undo = append(t.undo, undo)
+}
+
+// About start a unit of work
+func (r *TransferRepository) Begin(ctx context.Context) (port.Tx, error){
+	return &memoryTx{repository: r}, nil
+}
+
+// About the unit of work of a Tx, it must be open and started by this repository (called with the lock held)
+func (r *TransferRepository) open(tx port.Tx) (*memoryTx, error) {
+	t, ok := tx.(*memoryTx)
+	if !ok || t.repository != r {
+		return nil, errors.New("tx not started by the memory repository")
+	}
+	if t.closed {
+		return nil, errTxClosed
+	}
+	return t, nil
+}
+
+// About the next id of a table, like a sequence it is not given back by a rollback (called with the lock held)
+func (r *TransferRepository) nextID(table string) int {
+	r.sequence[table] = r.sequence[table] + 1
+	return r.sequence[table]
+}
+
+// About create a uuid transaction
+func (r *TransferRepository) GetTransactionUUID(ctx context.Context) (*string, error){
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.New(err.Error())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	uuid := fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+	return &uuid, nil
+}
+
+// About copy a transfer kept as a json payload (schedule, saga, review ...) the way it is stored and read
+func copyPayload(transfer *model.Transfer) (*model.Transfer, error) {
+	if transfer == nil {
+		return nil, nil
+	}
+	payload, err := json.Marshal(transfer)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	res_transfer := model.Transfer{}
+	err = json.Unmarshal(payload, &res_transfer)
+	if err != nil {
+		return nil, erro.ErrUnmarshal
+	}
+	return &res_transfer, nil
+}
+
+func copyString(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	res := *value
+	return &res
+}
+
+func copyInt(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	res := *value
+	return &res
+}
+
+func copyTime(value *time.Time) *time.Time {
+	if value == nil {
+		return nil
+	}
+	res := *value
+	return &res
+}
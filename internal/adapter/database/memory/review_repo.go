@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"time"
+	"sort"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About a copy of a stored review (called with the lock held)
+func copyTransferReview(transferReview model.TransferReview) (model.TransferReview, error) {
+	payload, err := copyPayload(transferReview.Transfer)
+	if err != nil {
+		return transferReview, err
+	}
+	transferReview.Transfer = payload
+	transferReview.Reasons = append([]model.RiskReason(nil), transferReview.Reasons...)
+	transferReview.DecidedAt = copyTime(transferReview.DecidedAt)
+	return transferReview, nil
+}
+
+// About the reviews matching a filter in an order (called with the lock held)
+func (r *TransferRepository) listTransferReview(match func(transferReview *model.TransferReview) bool, less func(a, b *model.TransferReview) bool, limit int) (*[]model.TransferReview, error) {
+	res_list := []model.TransferReview{}
+	for _, transferReview := range r.reviews {
+		if match(&transferReview) {
+			res_list = append(res_list, transferReview)
+		}
+	}
+	sort.Slice(res_list, func(i, j int) bool { return less(&res_list[i], &res_list[j]) })
+	if len(res_list) > limit {
+		res_list = res_list[:limit]
+	}
+
+	for i := range res_list {
+		res_review, err := copyTransferReview(res_list[i])
+		if err != nil {
+			return nil, err
+		}
+		res_list[i] = res_review
+	}
+
+	return &res_list, nil
+}
+
+// About get a review (called with the lock held)
+func (r *TransferRepository) getTransferReview(transferReview *model.TransferReview) (*model.TransferReview, error) {
+	res_review, ok := r.reviews[transferReview.ID]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+	res_review, err := copyTransferReview(res_review)
+	if err != nil {
+		return nil, err
+	}
+	return &res_review, nil
+}
+
+// About add a review in the transaction of the held transfer
+func (r *TransferRepository) AddTransferReview(ctx context.Context, tx port.Tx, transferReview *model.TransferReview) (*model.TransferReview, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	transferReview.CreatedAt = time.Now()
+
+	res_review, err := copyTransferReview(*transferReview)
+	if err != nil {
+		return nil, err
+	}
+	res_review.Reviewer = ""
+	res_review.Reason = ""
+	res_review.DecidedAt = nil
+
+	transferReview.ID = r.nextID("transfer_review")
+	res_review.ID = transferReview.ID
+
+	id := transferReview.ID
+	r.reviews[id] = res_review
+	t.onRollback(func() { delete(r.reviews, id) })
+
+	return transferReview, nil
+}
+
+// About get a review
+func (r *TransferRepository) GetTransferReview(ctx context.Context, transferReview *model.TransferReview) (*model.TransferReview, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.getTransferReview(transferReview)
+}
+
+// About get a review (the postgres one locks the row until the end of the transaction)
+func (r *TransferRepository) GetTransferReviewForUpdate(ctx context.Context, tx port.Tx, transferReview *model.TransferReview) (*model.TransferReview, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.getTransferReview(transferReview)
+}
+
+// About list the reviews by status, the oldest first (the queue)
+func (r *TransferRepository) ListTransferReview(ctx context.Context, transferReview *model.TransferReview, limit int) (*[]model.TransferReview, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.listTransferReview(func(res_review *model.TransferReview) bool {
+		return res_review.Status == transferReview.Status
+	}, func(a, b *model.TransferReview) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}, limit)
+}
+
+// About list the pending reviews past expires_at
+func (r *TransferRepository) ListTransferReviewExpired(ctx context.Context, tx port.Tx, limit int) (*[]model.TransferReview, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return r.listTransferReview(func(res_review *model.TransferReview) bool {
+		return res_review.Status == model.ReviewPending && !res_review.ExpiresAt.After(now)
+	}, func(a, b *model.TransferReview) bool {
+		return a.ExpiresAt.Before(b.ExpiresAt)
+	}, limit)
+}
+
+// About record the decision of a review
+func (r *TransferRepository) UpdateTransferReview(ctx context.Context, tx port.Tx, transferReview *model.TransferReview) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	old, ok := r.reviews[transferReview.ID]
+	if !ok {
+		return 0, erro.ErrUpdateRows
+	}
+	res_review := old
+	res_review.Status = transferReview.Status
+	res_review.Reviewer = transferReview.Reviewer
+	res_review.Reason = transferReview.Reason
+	res_review.DecidedAt = copyTime(transferReview.DecidedAt)
+	r.reviews[old.ID] = res_review
+	t.onRollback(func() { r.reviews[old.ID] = old })
+
+	return 1, nil
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"time"
+	"sort"
+	"errors"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About the statuses of a transfer not counted as a known destination: failed, in or closed by the review or the approval
+var notSentStatus = []model.TransferStatus{	model.StatusTransferFailed,
+											model.StatusPendingReview,
+											model.StatusReviewRejected,
+											model.StatusReviewExpired,
+											model.StatusAwaitingApproval,
+											model.StatusApprovalRejected }
+
+func copyRiskDecision(riskDecision model.RiskDecision) model.RiskDecision {
+	riskDecision.Reasons = append([]model.RiskReason(nil), riskDecision.Reasons...)
+	return riskDecision
+}
+
+// About count the outgoing movements of an account since a time.
+// A currency and a round amount narrow the count to the amounts multiple of it
+func (r *TransferRepository) CountTransferSince(ctx context.Context, tx port.Tx, accountID string, since time.Time, roundTo *model.Money) (int, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return 0, err
+	}
+	if roundTo != nil && roundTo.Minor == 0 {
+		return 0, errors.New("division by zero")
+	}
+
+	count := 0
+	for _, row := range r.transfers {
+		if row.transfer.AccountFrom.AccountID != accountID ||
+			row.transfer.TransferAt.Before(since) ||
+			!isOutgoing(row.transfer) {
+			continue
+		}
+		if roundTo != nil &&
+			(row.transfer.Currency != roundTo.Currency || absMinor(row.transfer.Amount) % roundTo.Minor != 0) {
+			continue
+		}
+		count = count + 1
+	}
+
+	return count, nil
+}
+
+// About an account already sent a transfer to another one
+func (r *TransferRepository) HasTransferTo(ctx context.Context, tx port.Tx, accountIDFrom string, accountIDTo string) (bool, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, row := range r.transfers {
+		if row.transfer.AccountFrom.AccountID == accountIDFrom &&
+			row.transfer.AccountTo.AccountID == accountIDTo &&
+			!hasStatus(row.transfer.Status, notSentStatus) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// About add a risk decision, it is kept when the transaction of the transfer rolls back
+func (r *TransferRepository) AddRiskDecision(ctx context.Context, riskDecision *model.RiskDecision) (*model.RiskDecision, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	riskDecision.CreatedAt = time.Now()
+	riskDecision.ID = r.nextID("transfer_risk_decision")
+	r.riskDecisions[riskDecision.ID] = copyRiskDecision(*riskDecision)
+
+	return riskDecision, nil
+}
+
+// About get the risk decision of a transfer by its transaction_id
+func (r *TransferRepository) GetRiskDecision(ctx context.Context, riskDecision *model.RiskDecision) (*model.RiskDecision, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := false
+	res_riskDecision := model.RiskDecision{}
+	for _, stored := range r.riskDecisions {
+		if stored.TransactionID == riskDecision.TransactionID && (!found || stored.ID < res_riskDecision.ID) {
+			found = true
+			res_riskDecision = stored
+		}
+	}
+	if !found {
+		return nil, erro.ErrNotFound
+	}
+	res_riskDecision = copyRiskDecision(res_riskDecision)
+
+	return &res_riskDecision, nil
+}
+
+// About list the latest risk decisions by decision and, optionally, source account
+func (r *TransferRepository) ListRiskDecision(ctx context.Context, riskDecision *model.RiskDecision, limit int) (*[]model.RiskDecision, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_list := []model.RiskDecision{}
+	for _, stored := range r.riskDecisions {
+		if stored.Decision == riskDecision.Decision &&
+			(riskDecision.AccountFrom == "" || stored.AccountFrom == riskDecision.AccountFrom) {
+			res_list = append(res_list, copyRiskDecision(stored))
+		}
+	}
+	sort.Slice(res_list, func(i, j int) bool {
+		if res_list[i].CreatedAt.Equal(res_list[j].CreatedAt) {
+			return res_list[i].ID > res_list[j].ID
+		}
+		return res_list[i].CreatedAt.After(res_list[j].CreatedAt)
+	})
+	if len(res_list) > limit {
+		res_list = res_list[:limit]
+	}
+
+	return &res_list, nil
+}
@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"time"
+	"sort"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About add a saga, it is committed at once (outside of the transfer transaction)
+func (r *TransferRepository) AddSaga(ctx context.Context, saga *model.Saga) (*model.Saga, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saga.CreatedAt = time.Now()
+	saga.UpdatedAt = saga.CreatedAt
+
+	payload, err := copyPayload(saga.Transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	saga.ID = r.nextID("transfer_saga")
+	r.sagas[saga.ID] = model.Saga{	ID: saga.ID,
+									TransactionID: copyString(saga.TransactionID),
+									Type: saga.Type,
+									Status: saga.Status,
+									Transfer: payload,
+									CreatedAt: saga.CreatedAt,
+									UpdatedAt: saga.UpdatedAt }
+
+	return saga, nil
+}
+
+// About update the status of a saga
+func (r *TransferRepository) UpdateSaga(ctx context.Context, saga *model.Saga) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saga.UpdatedAt = time.Now()
+
+	res_saga, ok := r.sagas[saga.ID]
+	if !ok {
+		return 0, erro.ErrUpdateRows
+	}
+	res_saga.Status = saga.Status
+	res_saga.UpdatedAt = saga.UpdatedAt
+	r.sagas[saga.ID] = res_saga
+
+	return 1, nil
+}
+
+// About refresh the updated_at of a running saga, ErrUpdateRows when the recovery claimed it
+func (r *TransferRepository) TouchSaga(ctx context.Context, saga *model.Saga) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saga.UpdatedAt = time.Now()
+
+	res_saga, ok := r.sagas[saga.ID]
+	if !ok || res_saga.Status != model.SagaRunning {
+		return 0, erro.ErrUpdateRows
+	}
+	res_saga.UpdatedAt = saga.UpdatedAt
+	r.sagas[saga.ID] = res_saga
+
+	return 1, nil
+}
+
+// About complete a running saga inside the transfer transaction
+func (r *TransferRepository) CompleteSaga(ctx context.Context, tx port.Tx, saga *model.Saga) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	saga.Status = model.SagaCompleted
+	saga.UpdatedAt = time.Now()
+
+	old, ok := r.sagas[saga.ID]
+	if !ok || old.Status != model.SagaRunning {
+		return 0, erro.ErrUpdateRows
+	}
+	res_saga := old
+	res_saga.Status = saga.Status
+	res_saga.UpdatedAt = saga.UpdatedAt
+	r.sagas[saga.ID] = res_saga
+	t.onRollback(func() { r.sagas[old.ID] = old })
+
+	return 1, nil
+}
+
+// About add a saga step
+func (r *TransferRepository) AddSagaStep(ctx context.Context, sagaStep *model.SagaStep) (*model.SagaStep, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sagaStep.CreatedAt = time.Now()
+	sagaStep.UpdatedAt = sagaStep.CreatedAt
+	sagaStep.ID = r.nextID("transfer_saga_step")
+	r.sagaSteps[sagaStep.ID] = *sagaStep
+
+	return sagaStep, nil
+}
+
+// About update the status of a saga step
+func (r *TransferRepository) UpdateSagaStep(ctx context.Context, sagaStep *model.SagaStep) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sagaStep.UpdatedAt = time.Now()
+
+	res_sagaStep, ok := r.sagaSteps[sagaStep.ID]
+	if !ok {
+		return 0, erro.ErrUpdateRows
+	}
+	res_sagaStep.Status = sagaStep.Status
+	res_sagaStep.Error = sagaStep.Error
+	res_sagaStep.UpdatedAt = sagaStep.UpdatedAt
+	r.sagaSteps[sagaStep.ID] = res_sagaStep
+
+	return 1, nil
+}
+
+// About claim the sagas left unfinished moving them to COMPENSATING
+func (r *TransferRepository) ClaimSagaRecovery(ctx context.Context, olderThan time.Time, limit int) (*[]model.Saga, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := []int{}
+	for id, saga := range r.sagas {
+		if (saga.Status == model.SagaRunning || saga.Status == model.SagaCompensating) && saga.UpdatedAt.Before(olderThan) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	res_list := []model.Saga{}
+	updated_at := time.Now()
+	for _, id := range ids {
+		saga := r.sagas[id]
+		saga.Status = model.SagaCompensating
+		saga.UpdatedAt = updated_at
+		r.sagas[id] = saga
+
+		payload, err := copyPayload(saga.Transfer)
+		if err != nil {
+			return nil, err
+		}
+		saga.Transfer = payload
+		saga.TransactionID = copyString(saga.TransactionID)
+		res_list = append(res_list, saga)
+	}
+
+	return &res_list, nil
+}
+
+// About list the steps of a saga
+func (r *TransferRepository) ListSagaStep(ctx context.Context, saga *model.Saga) (*[]model.SagaStep, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_list := []model.SagaStep{}
+	for _, sagaStep := range r.sagaSteps {
+		if sagaStep.FkSagaID == saga.ID {
+			res_list = append(res_list, sagaStep)
+		}
+	}
+	sort.Slice(res_list, func(i, j int) bool { return res_list[i].ID < res_list[j].ID })
+
+	return &res_list, nil
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"time"
+	"sort"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About a copy of a stored schedule (called with the lock held)
+func copySchedule(schedule model.Schedule) (model.Schedule, error) {
+	payload, err := copyPayload(schedule.Transfer)
+	if err != nil {
+		return schedule, err
+	}
+	schedule.Transfer = payload
+	schedule.FkTransferID = copyInt(schedule.FkTransferID)
+	schedule.LockedUntil = copyTime(schedule.LockedUntil)
+	return schedule, nil
+}
+
+// About the schedules matching a filter ordered by execute_at (called with the lock held)
+func (r *TransferRepository) listSchedule(match func(schedule *model.Schedule) bool, limit int) (*[]model.Schedule, error) {
+	res_list := []model.Schedule{}
+	for _, schedule := range r.schedules {
+		if match(&schedule) {
+			res_list = append(res_list, schedule)
+		}
+	}
+	sort.Slice(res_list, func(i, j int) bool {
+		if res_list[i].ExecuteAt.Equal(res_list[j].ExecuteAt) {
+			return res_list[i].ID < res_list[j].ID
+		}
+		return res_list[i].ExecuteAt.Before(res_list[j].ExecuteAt)
+	})
+	if len(res_list) > limit {
+		res_list = res_list[:limit]
+	}
+
+	for i := range res_list {
+		res_schedule, err := copySchedule(res_list[i])
+		if err != nil {
+			return nil, err
+		}
+		res_list[i] = res_schedule
+	}
+
+	return &res_list, nil
+}
+
+// About add a schedule
+func (r *TransferRepository) AddSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = schedule.CreatedAt
+
+	res_schedule, err := copySchedule(*schedule)
+	if err != nil {
+		return nil, err
+	}
+	res_schedule.FkTransferID = nil
+	res_schedule.LockedUntil = nil
+
+	schedule.ID = r.nextID("transfer_schedule")
+	res_schedule.ID = schedule.ID
+	r.schedules[schedule.ID] = res_schedule
+
+	return schedule, nil
+}
+
+// About list the schedules by status ordered by execute_at
+func (r *TransferRepository) ListSchedule(ctx context.Context, schedule *model.Schedule, limit int) (*[]model.Schedule, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.listSchedule(func(res_schedule *model.Schedule) bool {
+		return res_schedule.Status == schedule.Status
+	}, limit)
+}
+
+// About cancel a pending schedule
+func (r *TransferRepository) CancelSchedule(ctx context.Context, schedule *model.Schedule) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_schedule, ok := r.schedules[schedule.ID]
+	if !ok {
+		return 0, erro.ErrNotFound
+	}
+	now := time.Now()
+	if res_schedule.Status != model.SchedulePending || (res_schedule.LockedUntil != nil && res_schedule.LockedUntil.After(now)) {
+		return 0, erro.ErrStatusTransition
+	}
+	res_schedule.Status = model.ScheduleCanceled
+	res_schedule.UpdatedAt = now
+	r.schedules[schedule.ID] = res_schedule
+
+	return 1, nil
+}
+
+// About claim the due schedules until lockedUntil (lease) counting an attempt
+func (r *TransferRepository) ClaimScheduleDue(ctx context.Context, lockedUntil time.Time, limit int) (*[]model.Schedule, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	res_list, err := r.listSchedule(func(res_schedule *model.Schedule) bool {
+		return res_schedule.Status == model.SchedulePending &&
+				!res_schedule.ExecuteAt.After(now) &&
+				(res_schedule.LockedUntil == nil || !res_schedule.LockedUntil.After(now))
+	}, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range *res_list {
+		res_schedule := &(*res_list)[i]
+		res_schedule.Attempts = res_schedule.Attempts + 1
+		res_schedule.LockedUntil = copyTime(&lockedUntil)
+		res_schedule.UpdatedAt = now
+
+		stored := r.schedules[res_schedule.ID]
+		stored.Attempts = res_schedule.Attempts
+		stored.LockedUntil = copyTime(&lockedUntil)
+		stored.UpdatedAt = now
+		r.schedules[res_schedule.ID] = stored
+	}
+
+	return res_list, nil
+}
+
+// About update a schedule after an execution releasing its lease, only while the lease claimed (LockedUntil) is held
+func (r *TransferRepository) UpdateSchedule(ctx context.Context, schedule *model.Schedule) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.schedules[schedule.ID]
+	if !ok || old.LockedUntil == nil || schedule.LockedUntil == nil || !old.LockedUntil.Equal(*schedule.LockedUntil) {
+		return 0, erro.ErrUpdateRows
+	}
+
+	schedule.UpdatedAt = time.Now()
+
+	res_schedule := old
+	res_schedule.Status = schedule.Status
+	res_schedule.ExecuteAt = schedule.ExecuteAt
+	res_schedule.Attempts = schedule.Attempts
+	res_schedule.LastError = schedule.LastError
+	res_schedule.FkTransferID = copyInt(schedule.FkTransferID)
+	res_schedule.LockedUntil = nil
+	res_schedule.UpdatedAt = schedule.UpdatedAt
+	r.schedules[old.ID] = res_schedule
+
+	schedule.LockedUntil = nil
+	return 1, nil
+}
@@ -0,0 +1,251 @@
+package memory
+
+import (
+	"time"
+	"sort"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About a copy of a stored standing order (called with the lock held)
+func copyStandingOrder(standingOrder model.StandingOrder) (model.StandingOrder, error) {
+	payload, err := copyPayload(standingOrder.Transfer)
+	if err != nil {
+		return standingOrder, err
+	}
+	standingOrder.Transfer = payload
+	standingOrder.EndAt = copyTime(standingOrder.EndAt)
+	standingOrder.LockedUntil = copyTime(standingOrder.LockedUntil)
+	standingOrder.History = nil
+	return standingOrder, nil
+}
+
+// About the standing orders matching a filter in an order (called with the lock held)
+func (r *TransferRepository) listStandingOrder(match func(standingOrder *model.StandingOrder) bool, less func(a, b *model.StandingOrder) bool, limit int) (*[]model.StandingOrder, error) {
+	res_list := []model.StandingOrder{}
+	for _, standingOrder := range r.standingOrders {
+		if match(&standingOrder) {
+			res_list = append(res_list, standingOrder)
+		}
+	}
+	sort.Slice(res_list, func(i, j int) bool { return less(&res_list[i], &res_list[j]) })
+	if len(res_list) > limit {
+		res_list = res_list[:limit]
+	}
+
+	for i := range res_list {
+		res_standingOrder, err := copyStandingOrder(res_list[i])
+		if err != nil {
+			return nil, err
+		}
+		res_list[i] = res_standingOrder
+	}
+
+	return &res_list, nil
+}
+
+// About add a standing order
+func (r *TransferRepository) AddStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (*model.StandingOrder, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	standingOrder.CreatedAt = time.Now()
+	standingOrder.UpdatedAt = standingOrder.CreatedAt
+
+	res_standingOrder, err := copyStandingOrder(*standingOrder)
+	if err != nil {
+		return nil, err
+	}
+	res_standingOrder.LockedUntil = nil
+
+	standingOrder.ID = r.nextID("standing_order")
+	res_standingOrder.ID = standingOrder.ID
+	r.standingOrders[standingOrder.ID] = res_standingOrder
+
+	return standingOrder, nil
+}
+
+// About get a standing order
+func (r *TransferRepository) GetStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (*model.StandingOrder, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_standingOrder, ok := r.standingOrders[standingOrder.ID]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+	res_standingOrder, err := copyStandingOrder(res_standingOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res_standingOrder, nil
+}
+
+// About list the standing orders by status
+func (r *TransferRepository) ListStandingOrder(ctx context.Context, standingOrder *model.StandingOrder, limit int) (*[]model.StandingOrder, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.listStandingOrder(func(res_standingOrder *model.StandingOrder) bool {
+		return res_standingOrder.Status == standingOrder.Status
+	}, func(a, b *model.StandingOrder) bool {
+		return a.ID < b.ID
+	}, limit)
+}
+
+// About cancel an active standing order
+func (r *TransferRepository) CancelStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_standingOrder, ok := r.standingOrders[standingOrder.ID]
+	if !ok {
+		return 0, erro.ErrNotFound
+	}
+	now := time.Now()
+	if res_standingOrder.Status != model.StandingOrderActive || (res_standingOrder.LockedUntil != nil && res_standingOrder.LockedUntil.After(now)) {
+		return 0, erro.ErrStatusTransition
+	}
+	res_standingOrder.Status = model.StandingOrderCanceled
+	res_standingOrder.UpdatedAt = now
+	r.standingOrders[standingOrder.ID] = res_standingOrder
+
+	return 1, nil
+}
+
+// About claim the due standing orders until lockedUntil (lease) counting an attempt
+func (r *TransferRepository) ClaimStandingOrderDue(ctx context.Context, lockedUntil time.Time, limit int) (*[]model.StandingOrder, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	res_list, err := r.listStandingOrder(func(res_standingOrder *model.StandingOrder) bool {
+		return res_standingOrder.Status == model.StandingOrderActive &&
+				!res_standingOrder.NextExecuteAt.After(now) &&
+				(res_standingOrder.LockedUntil == nil || !res_standingOrder.LockedUntil.After(now))
+	}, func(a, b *model.StandingOrder) bool {
+		if a.NextExecuteAt.Equal(b.NextExecuteAt) {
+			return a.ID < b.ID
+		}
+		return a.NextExecuteAt.Before(b.NextExecuteAt)
+	}, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range *res_list {
+		res_standingOrder := &(*res_list)[i]
+		res_standingOrder.Attempts = res_standingOrder.Attempts + 1
+		res_standingOrder.LockedUntil = copyTime(&lockedUntil)
+		res_standingOrder.UpdatedAt = now
+
+		stored := r.standingOrders[res_standingOrder.ID]
+		stored.Attempts = res_standingOrder.Attempts
+		stored.LockedUntil = copyTime(&lockedUntil)
+		stored.UpdatedAt = now
+		r.standingOrders[res_standingOrder.ID] = stored
+	}
+
+	return res_list, nil
+}
+
+// About update the progress of a standing order releasing its lease, only while the lease claimed (LockedUntil) is held
+func (r *TransferRepository) UpdateStandingOrder(ctx context.Context, tx port.Tx, standingOrder *model.StandingOrder) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	old, ok := r.standingOrders[standingOrder.ID]
+	if !ok || old.LockedUntil == nil || standingOrder.LockedUntil == nil || !old.LockedUntil.Equal(*standingOrder.LockedUntil) {
+		return 0, erro.ErrUpdateRows
+	}
+
+	standingOrder.UpdatedAt = time.Now()
+
+	res_standingOrder := old
+	res_standingOrder.Occurrences = standingOrder.Occurrences
+	res_standingOrder.Attempts = standingOrder.Attempts
+	res_standingOrder.NextExecuteAt = standingOrder.NextExecuteAt
+	res_standingOrder.Status = standingOrder.Status
+	res_standingOrder.LastError = standingOrder.LastError
+	res_standingOrder.LockedUntil = nil
+	res_standingOrder.UpdatedAt = standingOrder.UpdatedAt
+	r.standingOrders[old.ID] = res_standingOrder
+	t.onRollback(func() { r.standingOrders[old.ID] = old })
+
+	return 1, nil
+}
+
+// About add an occurrence into the history of a standing order
+func (r *TransferRepository) AddStandingOrderOccurrence(ctx context.Context, tx port.Tx, occurrence *model.StandingOrderOccurrence) (*model.StandingOrderOccurrence, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrence.ExecutedAt = time.Now()
+	occurrence.ID = r.nextID("standing_order_occurrence")
+
+	res_occurrence := *occurrence
+	res_occurrence.FkTransferID = copyInt(occurrence.FkTransferID)
+
+	id := occurrence.ID
+	r.occurrences[id] = res_occurrence
+	t.onRollback(func() { delete(r.occurrences, id) })
+
+	return occurrence, nil
+}
+
+// About resolve the occurrence held with a transfer (by fk_transfer_id), a transfer of no held occurrence updates nothing
+func (r *TransferRepository) ResolveStandingOrderOccurrence(ctx context.Context, tx port.Tx, occurrence *model.StandingOrderOccurrence) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for id, old := range r.occurrences {
+		if old.Status != model.OccurrenceHeld || old.FkTransferID == nil || occurrence.FkTransferID == nil || *old.FkTransferID != *occurrence.FkTransferID {
+			continue
+		}
+		res_occurrence := old
+		res_occurrence.Status = occurrence.Status
+		res_occurrence.LastError = occurrence.LastError
+		r.occurrences[id] = res_occurrence
+		t.onRollback(func() { r.occurrences[old.ID] = old })
+		count = count + 1
+	}
+
+	return count, nil
+}
+
+// About list the history of a standing order
+func (r *TransferRepository) ListStandingOrderOccurrence(ctx context.Context, standingOrder *model.StandingOrder) (*[]model.StandingOrderOccurrence, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_list := []model.StandingOrderOccurrence{}
+	for _, occurrence := range r.occurrences {
+		if occurrence.FkStandingOrderID == standingOrder.ID {
+			occurrence.FkTransferID = copyInt(occurrence.FkTransferID)
+			res_list = append(res_list, occurrence)
+		}
+	}
+	sort.Slice(res_list, func(i, j int) bool { return res_list[i].Sequence < res_list[j].Sequence })
+
+	return &res_list, nil
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"time"
+	"sort"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About get a transfer (the postgres one locks the row until the end of the transaction)
+func (r *TransferRepository) GetTransferForUpdate(ctx context.Context, tx port.Tx, transfer *model.Transfer) (*model.Transfer, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, ok := r.transfers[transfer.ID]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+
+	return &model.Transfer{	ID: row.transfer.ID,
+							Type: row.transfer.Type,
+							Status: row.transfer.Status,
+							TransferAt: row.transfer.TransferAt,
+							Currency: row.transfer.Currency,
+							TransactionID: copyString(row.transfer.TransactionID) }, nil
+}
+
+// About get the id of a transfer by its transaction_id
+func (r *TransferRepository) GetTransferIDByTransactionID(ctx context.Context, tx port.Tx, transfer *model.Transfer) (*model.Transfer, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, ok := r.findTransferByTransactionID(transfer.TransactionID)
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+
+	return &model.Transfer{	ID: row.transfer.ID,
+							TransactionID: copyString(row.transfer.TransactionID) }, nil
+}
+
+// About update the status of a transfer
+func (r *TransferRepository) UpdateTransferStatus(ctx context.Context, tx port.Tx, transfer *model.Transfer) (int64, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	row, ok := r.transfers[transfer.ID]
+	if !ok {
+		return 0, erro.ErrUpdateRows
+	}
+	old := row
+	row.transfer.Status = transfer.Status
+	r.transfers[transfer.ID] = row
+	t.onRollback(func() { r.transfers[old.transfer.ID] = old })
+
+	return 1, nil
+}
+
+// About record a status transition of a transfer
+func (r *TransferRepository) AddStatusTransition(ctx context.Context, tx port.Tx, statusTransition *model.StatusTransition) (*model.StatusTransition, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.addStatusTransition(t, statusTransition), nil
+}
+
+// About record a status transition (called with the lock held)
+func (r *TransferRepository) addStatusTransition(t *memoryTx, statusTransition *model.StatusTransition) *model.StatusTransition {
+	if statusTransition.ChangedAt.IsZero() {
+		statusTransition.ChangedAt = time.Now()
+	}
+	statusTransition.ID = r.nextID("transfer_status_history")
+
+	id := statusTransition.ID
+	r.statusTransitions[id] = *statusTransition
+	t.onRollback(func() { delete(r.statusTransitions, id) })
+
+	return statusTransition
+}
+
+// About list the status transitions of a transfer
+func (r *TransferRepository) ListStatusTransition(ctx context.Context, transfer *model.Transfer) (*[]model.StatusTransition, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_list := []model.StatusTransition{}
+	for _, statusTransition := range r.statusTransitions {
+		if statusTransition.FkTransferID == transfer.ID {
+			res_list = append(res_list, statusTransition)
+		}
+	}
+	sort.Slice(res_list, func(i, j int) bool { return res_list[i].ID < res_list[j].ID })
+
+	return &res_list, nil
+}
@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"time"
+	"sort"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About a row of transfer_moviment, the tenant is the one of the source account (the account table in postgres)
+type transferRow struct {
+	transfer	model.Transfer
+	tenantID	string
+}
+
+// About the columns of a transfer, the account_id of both legs included (as transferQuery)
+func transferColumns(transfer *model.Transfer) model.Transfer {
+	res_transfer := model.Transfer{	ID: transfer.ID,
+									AccountFrom: &model.AccountStatement{},
+									AccountTo: &model.AccountStatement{},
+									Type: transfer.Type,
+									Status: transfer.Status,
+									TransferAt: transfer.TransferAt,
+									Currency: transfer.Currency,
+									Amount: transfer.Amount,
+									TransactionID: copyString(transfer.TransactionID) }
+	if transfer.AccountFrom != nil {
+		res_transfer.AccountFrom.FkAccountID = transfer.AccountFrom.FkAccountID
+		res_transfer.AccountFrom.AccountID = transfer.AccountFrom.AccountID
+	}
+	if transfer.AccountTo != nil {
+		res_transfer.AccountTo.FkAccountID = transfer.AccountTo.FkAccountID
+		res_transfer.AccountTo.AccountID = transfer.AccountTo.AccountID
+	}
+	if transfer.Fx != nil {
+		fx := *transfer.Fx
+		res_transfer.Fx = &fx
+	}
+	return res_transfer
+}
+
+// About add a transfer transaction
+func (r *TransferRepository) AddTransfer(ctx context.Context, tx port.Tx, transfer *model.Transfer) (*model.Transfer, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.open(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer.TransferAt = time.Now()
+	transfer.ID = r.nextID("transfer_moviment")
+
+	row := transferRow{transfer: transferColumns(transfer)}
+	if transfer.AccountFrom != nil {
+		row.tenantID = transfer.AccountFrom.TenantID
+	}
+	id := transfer.ID
+	r.transfers[id] = row
+	t.onRollback(func() { delete(r.transfers, id) })
+
+	// Record the initial status
+	r.addStatusTransition(t, &model.StatusTransition{	FkTransferID: id,
+														StatusTo: transfer.Status,
+														ChangedAt: transfer.TransferAt })
+
+	return transfer, nil
+}
+
+// About get a transfer
+func (r *TransferRepository) GetTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.transfers[transfer.ID]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+	res_transfer := transferColumns(&row.transfer)
+	return &res_transfer, nil
+}
+
+// About get a transfer by its transaction_id
+func (r *TransferRepository) GetTransferByTransactionID(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.findTransferByTransactionID(transfer.TransactionID)
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+	res_transfer := transferColumns(&row.transfer)
+	return &res_transfer, nil
+}
+
+// About the transfer of a transaction_id, the lowest id first (called with the lock held)
+func (r *TransferRepository) findTransferByTransactionID(transactionID *string) (transferRow, bool) {
+	found := false
+	res := transferRow{}
+	if transactionID == nil {
+		return res, false
+	}
+	for _, row := range r.transfers {
+		if row.transfer.TransactionID != nil && *row.transfer.TransactionID == *transactionID &&
+			(!found || row.transfer.ID < res.transfer.ID) {
+			found = true
+			res = row
+		}
+	}
+	return res, found
+}
+
+// About list the transfers matching a filter, one page after the cursor ordered by transfer_at desc
+func (r *TransferRepository) ListTransfer(ctx context.Context, transferFilter *model.TransferFilter) (*[]model.Transfer, error){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res_list := []model.Transfer{}
+	for _, row := range r.transfers {
+		transfer := row.transfer
+		if transferFilter.AccountID != "" &&
+			transfer.AccountFrom.AccountID != transferFilter.AccountID &&
+			transfer.AccountTo.AccountID != transferFilter.AccountID {
+			continue
+		}
+		if transferFilter.Status != "" && transfer.Status != transferFilter.Status {
+			continue
+		}
+		if transferFilter.Currency != "" && transfer.Currency != transferFilter.Currency {
+			continue
+		}
+		if transferFilter.From != nil && transfer.TransferAt.Before(*transferFilter.From) {
+			continue
+		}
+		if transferFilter.To != nil && !transfer.TransferAt.Before(*transferFilter.To) {
+			continue
+		}
+		if transferFilter.Cursor != nil && !transferBefore(&transfer, transferFilter.Cursor.TransferAt, transferFilter.Cursor.ID) {
+			continue
+		}
+		res_list = append(res_list, transferColumns(&transfer))
+	}
+
+	sort.Slice(res_list, func(i, j int) bool {
+		return transferBefore(&res_list[j], res_list[i].TransferAt, res_list[i].ID)
+	})
+	if len(res_list) > transferFilter.Limit {
+		res_list = res_list[:transferFilter.Limit]
+	}
+
+	return &res_list, nil
+}
+
+// About (transfer_at, id) of a transfer is lower than (transferAt, id)
+func transferBefore(transfer *model.Transfer, transferAt time.Time, id int) bool {
+	if transfer.TransferAt.Equal(transferAt) {
+		return transfer.ID < id
+	}
+	return transfer.TransferAt.Before(transferAt)
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About add an event into the outbox, inside the transaction of the transfer
+func (w WorkerRepository) AddOutbox(ctx context.Context, tx port.Tx, outbox *model.Outbox) (*model.Outbox, error){
+	childLogger.Info().Str("func","AddOutbox").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("topic", outbox.Topic).Str("event_type", outbox.EventType).Str("key", outbox.Key).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddOutbox")
+	defer span.End()
+
+	// Prepare
+	var id int
+	outbox.Status = model.OutboxPending
+	outbox.CreatedAt = time.Now()
+
+	// Query and Execute
+	query := `INSERT INTO outbox(	topic,
+									event_type,
+									key,
+									trace_id,
+									payload,
+									status,
+									attempts,
+									created_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	outbox.Topic,
+									outbox.EventType,
+									outbox.Key,
+									outbox.TraceID,
+									outbox.Payload,
+									outbox.Status,
+									outbox.Attempts,
+									outbox.CreatedAt)
+
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	outbox.ID = id
+	return outbox, nil
+}
+
+// About list the pending events locking them, SKIP LOCKED lets many relays run in parallel
+func (w WorkerRepository) ListOutboxPending(ctx context.Context, tx port.Tx, limit int) (*[]model.Outbox, error){
+	childLogger.Debug().Str("func","ListOutboxPending").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListOutboxPending")
+	defer span.End()
+
+	// Prepare
+	res_list := []model.Outbox{}
+
+	// Query and Execute
+	query := `SELECT id,
+					topic,
+					event_type,
+					key,
+					trace_id,
+					payload,
+					status,
+					attempts,
+					last_error,
+					created_at
+				FROM outbox
+				WHERE status = $1
+				ORDER BY id
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED`
+
+	rows, err := pgxTx(tx).Query(ctx, query, model.OutboxPending, limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		outbox := model.Outbox{}
+		err := rows.Scan(	&outbox.ID,
+							&outbox.Topic,
+							&outbox.EventType,
+							&outbox.Key,
+							&outbox.TraceID,
+							&outbox.Payload,
+							&outbox.Status,
+							&outbox.Attempts,
+							&outbox.LastError,
+							&outbox.CreatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_list = append(res_list, outbox)
+	}
+
+	return &res_list, nil
+}
+
+// About update the status, attempts and error of an outbox event
+func (w WorkerRepository) UpdateOutbox(ctx context.Context, tx port.Tx, outbox *model.Outbox) (int64, error){
+	childLogger.Debug().Str("func","UpdateOutbox").Int("id", outbox.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateOutbox")
+	defer span.End()
+
+	// Query and Execute
+	query := `UPDATE outbox
+				SET status = $2,
+					attempts = $3,
+					last_error = $4,
+					sent_at = $5
+				WHERE id = $1`
+
+	row, err := pgxTx(tx).Exec(ctx, query,	outbox.ID,
+									outbox.Status,
+									outbox.Attempts,
+									outbox.LastError,
+									outbox.SentAt)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// About add a quote
+func (w WorkerRepository) AddQuote(ctx context.Context, quote *model.Quote) (*model.Quote, error){
+	childLogger.Info().Str("func","AddQuote").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("quote",quote).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddQuote")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	var rate pgtype.Numeric
+	if err := rate.Scan(quote.Rate); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Query and Execute
+	query := `INSERT INTO fx_quote(	id,
+									currency,
+									amount,
+									currency_to,
+									rate,
+									fee,
+									destination_amount,
+									status,
+									created_at,
+									expires_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err = conn.Exec(ctx, query,	quote.ID,
+									quote.Currency,
+									moneyToNumeric(quote.Amount),
+									quote.CurrencyTo,
+									rate,
+									moneyToNumeric(quote.Fee),
+									moneyToNumeric(quote.DestinationAmount),
+									quote.Status,
+									quote.CreatedAt,
+									quote.ExpiresAt)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return quote, nil
+}
+
+// About get a quote locking the row until the end of the transaction, so a quote is redeemed only once
+func (w WorkerRepository) GetQuoteForUpdate(ctx context.Context, tx port.Tx, quote *model.Quote) (*model.Quote, error){
+	childLogger.Info().Str("func","GetQuoteForUpdate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("quote_id", quote.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetQuoteForUpdate")
+	defer span.End()
+
+	// Prepare
+	res_quote := model.Quote{}
+	var amount, rate, fee, destination_amount pgtype.Numeric
+
+	// Query and Execute
+	query := `SELECT id,
+					currency,
+					amount,
+					currency_to,
+					rate,
+					fee,
+					destination_amount,
+					status,
+					created_at,
+					expires_at,
+					used_at,
+					transaction_id
+				FROM fx_quote
+				WHERE id = $1
+				FOR UPDATE`
+
+	err := pgxTx(tx).QueryRow(ctx, query, quote.ID).Scan(	&res_quote.ID,
+													&res_quote.Currency,
+													&amount,
+													&res_quote.CurrencyTo,
+													&rate,
+													&fee,
+													&destination_amount,
+													&res_quote.Status,
+													&res_quote.CreatedAt,
+													&res_quote.ExpiresAt,
+													&res_quote.UsedAt,
+													&res_quote.TransactionID)
+	if err == pgx.ErrNoRows {
+		return nil, erro.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	res_quote.Amount, err = numericToMoney(amount, res_quote.Currency)
+	if err != nil {
+		return nil, err
+	}
+	res_quote.Fee, err = numericToMoney(fee, res_quote.Currency)
+	if err != nil {
+		return nil, err
+	}
+	res_quote.DestinationAmount, err = numericToMoney(destination_amount, res_quote.CurrencyTo)
+	if err != nil {
+		return nil, err
+	}
+	rate_value, err := rate.Value()
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	res_quote.Rate, _ = rate_value.(string)
+
+	return &res_quote, nil
+}
+
+// About mark a quote as used by a transfer
+func (w WorkerRepository) UpdateQuote(ctx context.Context, tx port.Tx, quote *model.Quote) (int64, error){
+	childLogger.Info().Str("func","UpdateQuote").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("quote_id", quote.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateQuote")
+	defer span.End()
+
+	// Query and Execute
+	query := `UPDATE fx_quote
+				SET status = $2,
+					used_at = $3,
+					transaction_id = $4
+				WHERE id = $1`
+
+	row, err := pgxTx(tx).Exec(ctx, query,	quote.ID,
+									quote.Status,
+									quote.UsedAt,
+									quote.TransactionID)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
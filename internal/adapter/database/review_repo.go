@@ -0,0 +1,278 @@
+package database
+
+import (
+	"time"
+	"context"
+	"errors"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About the select of a review (scanned by scanTransferReview)
+const transferReviewQuery = `SELECT id,
+									fk_transfer_id,
+									transaction_id,
+									flow,
+									payload,
+									reasons,
+									status,
+									reviewer,
+									reason,
+									expires_at,
+									decided_at,
+									created_at
+							FROM transfer_review`
+
+// About scan a row of transferReviewQuery
+func scanTransferReview(rows pgx.Rows) (*model.TransferReview, error) {
+	res_review := model.TransferReview{}
+	var payload, reasons []byte
+
+	err := rows.Scan(	&res_review.ID,
+						&res_review.FkTransferID,
+						&res_review.TransactionID,
+						&res_review.Flow,
+						&payload,
+						&reasons,
+						&res_review.Status,
+						&res_review.Reviewer,
+						&res_review.Reason,
+						&res_review.ExpiresAt,
+						&res_review.DecidedAt,
+						&res_review.CreatedAt)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	err = json.Unmarshal(payload, &res_review.Transfer)
+	if err != nil {
+		return nil, erro.ErrUnmarshal
+	}
+	err = json.Unmarshal(reasons, &res_review.Reasons)
+	if err != nil {
+		return nil, erro.ErrUnmarshal
+	}
+
+	return &res_review, nil
+}
+
+// About scan the rows of transferReviewQuery
+func scanTransferReviewList(rows pgx.Rows) (*[]model.TransferReview, error) {
+	res_list := []model.TransferReview{}
+	for rows.Next() {
+		res_review, err := scanTransferReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		res_list = append(res_list, *res_review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err.Error())
+	}
+	return &res_list, nil
+}
+
+// About add a review in the transaction of the held transfer
+func (w WorkerRepository) AddTransferReview(ctx context.Context, tx port.Tx, transferReview *model.TransferReview) (*model.TransferReview, error){
+	childLogger.Info().Str("func","AddTransferReview").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferReview",transferReview).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddTransferReview")
+	defer span.End()
+
+	// Prepare
+	var id int
+	transferReview.CreatedAt = time.Now()
+
+	payload, err := json.Marshal(transferReview.Transfer)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	reasons, err := json.Marshal(transferReview.Reasons)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Query and Execute
+	query := `INSERT INTO transfer_review(	fk_transfer_id,
+											transaction_id,
+											flow,
+											payload,
+											reasons,
+											status,
+											expires_at,
+											created_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	transferReview.FkTransferID,
+									transferReview.TransactionID,
+									transferReview.Flow,
+									payload,
+									reasons,
+									transferReview.Status,
+									transferReview.ExpiresAt,
+									transferReview.CreatedAt)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	transferReview.ID = id
+	return transferReview, nil
+}
+
+// About get a review
+func (w WorkerRepository) GetTransferReview(ctx context.Context, transferReview *model.TransferReview) (*model.TransferReview, error){
+	childLogger.Info().Str("func","GetTransferReview").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("id", transferReview.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetTransferReview")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := transferReviewQuery + `
+				WHERE id = $1`
+
+	rows, err := conn.Query(ctx, query, transferReview.ID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res_list, err := scanTransferReviewList(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(*res_list) == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return &(*res_list)[0], nil
+}
+
+// About get a review locking the row until the end of the transaction, so a review is decided only once
+func (w WorkerRepository) GetTransferReviewForUpdate(ctx context.Context, tx port.Tx, transferReview *model.TransferReview) (*model.TransferReview, error){
+	childLogger.Info().Str("func","GetTransferReviewForUpdate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("id", transferReview.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetTransferReviewForUpdate")
+	defer span.End()
+
+	// Query and Execute
+	query := transferReviewQuery + `
+				WHERE id = $1
+				FOR UPDATE`
+
+	rows, err := pgxTx(tx).Query(ctx, query, transferReview.ID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res_list, err := scanTransferReviewList(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(*res_list) == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return &(*res_list)[0], nil
+}
+
+// About list the reviews by status, the oldest first (the queue)
+func (w WorkerRepository) ListTransferReview(ctx context.Context, transferReview *model.TransferReview, limit int) (*[]model.TransferReview, error){
+	childLogger.Info().Str("func","ListTransferReview").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("status", transferReview.Status).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListTransferReview")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := transferReviewQuery + `
+				WHERE status = $1
+				ORDER BY created_at
+				LIMIT $2`
+
+	rows, err := conn.Query(ctx, query, transferReview.Status, limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	return scanTransferReviewList(rows)
+}
+
+// About list the pending reviews past expires_at locking them (rows locked by another pod are skipped)
+func (w WorkerRepository) ListTransferReviewExpired(ctx context.Context, tx port.Tx, limit int) (*[]model.TransferReview, error){
+	childLogger.Debug().Str("func","ListTransferReviewExpired").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListTransferReviewExpired")
+	defer span.End()
+
+	// Query and Execute
+	query := transferReviewQuery + `
+				WHERE status = $1
+				and expires_at <= $2
+				ORDER BY expires_at
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED`
+
+	rows, err := pgxTx(tx).Query(ctx, query, model.ReviewPending, time.Now(), limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	return scanTransferReviewList(rows)
+}
+
+// About record the decision of a review
+func (w WorkerRepository) UpdateTransferReview(ctx context.Context, tx port.Tx, transferReview *model.TransferReview) (int64, error){
+	childLogger.Info().Str("func","UpdateTransferReview").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("id", transferReview.ID).Str("status", transferReview.Status).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateTransferReview")
+	defer span.End()
+
+	// Query and Execute
+	query := `UPDATE transfer_review
+				SET status = $2,
+					reviewer = $3,
+					reason = $4,
+					decided_at = $5
+				WHERE id = $1`
+
+	row, err := pgxTx(tx).Exec(ctx, query,	transferReview.ID,
+									transferReview.Status,
+									transferReview.Reviewer,
+									transferReview.Reason,
+									transferReview.DecidedAt)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
@@ -0,0 +1,275 @@
+package database
+
+import (
+	"time"
+	"strconv"
+	"context"
+	"errors"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// About the select of a risk decision (scanned by scanRiskDecision)
+const riskDecisionQuery = `SELECT 	id,
+									transaction_id,
+									operation,
+									account_from,
+									account_to,
+									currency,
+									amount,
+									decision,
+									reasons,
+									created_at
+							FROM transfer_risk_decision`
+
+// About scan a row of riskDecisionQuery
+func scanRiskDecision(rows pgx.Rows) (*model.RiskDecision, error) {
+	res_riskDecision := model.RiskDecision{}
+	var amount pgtype.Numeric
+	var reasons []byte
+
+	err := rows.Scan(	&res_riskDecision.ID,
+						&res_riskDecision.TransactionID,
+						&res_riskDecision.Operation,
+						&res_riskDecision.AccountFrom,
+						&res_riskDecision.AccountTo,
+						&res_riskDecision.Currency,
+						&amount,
+						&res_riskDecision.Decision,
+						&reasons,
+						&res_riskDecision.CreatedAt)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	res_riskDecision.Amount, err = numericToMoney(amount, res_riskDecision.Currency)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(reasons, &res_riskDecision.Reasons)
+	if err != nil {
+		return nil, erro.ErrUnmarshal
+	}
+
+	return &res_riskDecision, nil
+}
+
+// About count the outgoing movements (credits by type, failed or reversed and closed by the review or the approval excluded) of an account since a time.
+// A currency and a round amount narrow the count to the amounts multiple of it
+func (w WorkerRepository) CountTransferSince(ctx context.Context, tx port.Tx, accountID string, since time.Time, roundTo *model.Money) (int, error){
+	childLogger.Info().Str("func","CountTransferSince").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("account_id", accountID).Time("since", since).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.CountTransferSince")
+	defer span.End()
+
+	// Prepare
+	var count int
+	args := []interface{}{	accountID,
+							since,
+							"CREDIT",
+							model.StatusTransferFailed,
+							model.StatusTransferReversed,
+							model.StatusReviewRejected,
+							model.StatusReviewExpired,
+							model.StatusApprovalRejected }
+
+	// Query and Execute
+	query := `SELECT COUNT(*)
+				FROM transfer_moviment as trans,
+					account as fr
+				WHERE trans.fk_account_id_from = fr.id
+				and fr.account_id = $1
+				and trans.transfer_at >= $2
+				and trans.type_charge IS DISTINCT FROM $3
+				and trans.status NOT IN ($4, $5, $6, $7, $8)`
+
+	if roundTo != nil {
+		args = append(args, roundTo.Currency, moneyToNumeric(*roundTo))
+		query = query + `
+				and trans.currency = $` + strconv.Itoa(len(args) - 1) + `
+				and MOD(ABS(trans.amount), $` + strconv.Itoa(len(args)) + `) = 0`
+	}
+
+	err := pgxTx(tx).QueryRow(ctx, query, args...).Scan(&count)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return count, nil
+}
+
+// About an account already sent a transfer (not failed nor in or closed by the review or the approval) to another one
+func (w WorkerRepository) HasTransferTo(ctx context.Context, tx port.Tx, accountIDFrom string, accountIDTo string) (bool, error){
+	childLogger.Info().Str("func","HasTransferTo").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("account_from", accountIDFrom).Str("account_to", accountIDTo).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.HasTransferTo")
+	defer span.End()
+
+	// Prepare
+	var exists bool
+
+	// Query and Execute
+	query := `SELECT EXISTS (SELECT 1
+							FROM transfer_moviment as trans,
+								account as fr,
+								account as t
+							WHERE trans.fk_account_id_from = fr.id
+							and trans.fk_account_id_to = t.id
+							and fr.account_id = $1
+							and t.account_id = $2
+							and trans.status NOT IN ($3, $4, $5, $6, $7, $8))`
+
+	err := pgxTx(tx).QueryRow(ctx, query,	accountIDFrom,
+									accountIDTo,
+									model.StatusTransferFailed,
+									model.StatusPendingReview,
+									model.StatusReviewRejected,
+									model.StatusReviewExpired,
+									model.StatusAwaitingApproval,
+									model.StatusApprovalRejected).Scan(&exists)
+	if err != nil {
+		return false, errors.New(err.Error())
+	}
+
+	return exists, nil
+}
+
+// About add a risk decision. It uses its own connection, so the decision of a denied transfer is kept
+// when the transaction of the transfer rolls back
+func (w WorkerRepository) AddRiskDecision(ctx context.Context, riskDecision *model.RiskDecision) (*model.RiskDecision, error){
+	childLogger.Info().Str("func","AddRiskDecision").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("riskDecision",riskDecision).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddRiskDecision")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	var id int
+	riskDecision.CreatedAt = time.Now()
+
+	reasons, err := json.Marshal(riskDecision.Reasons)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Query and Execute
+	query := `INSERT INTO transfer_risk_decision(	transaction_id,
+													operation,
+													account_from,
+													account_to,
+													currency,
+													amount,
+													decision,
+													reasons,
+													created_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+
+	row := conn.QueryRow(ctx, query,	riskDecision.TransactionID,
+										riskDecision.Operation,
+										riskDecision.AccountFrom,
+										riskDecision.AccountTo,
+										riskDecision.Currency,
+										moneyToNumeric(riskDecision.Amount),
+										riskDecision.Decision,
+										reasons,
+										riskDecision.CreatedAt)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	riskDecision.ID = id
+	return riskDecision, nil
+}
+
+// About get the risk decision of a transfer by its transaction_id
+func (w WorkerRepository) GetRiskDecision(ctx context.Context, riskDecision *model.RiskDecision) (*model.RiskDecision, error){
+	childLogger.Info().Str("func","GetRiskDecision").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("transaction_id", riskDecision.TransactionID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetRiskDecision")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := riskDecisionQuery + `
+				WHERE transaction_id = $1`
+
+	rows, err := conn.Query(ctx, query, riskDecision.TransactionID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		return scanRiskDecision(rows)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About list the latest risk decisions by decision and, optionally, source account
+func (w WorkerRepository) ListRiskDecision(ctx context.Context, riskDecision *model.RiskDecision, limit int) (*[]model.RiskDecision, error){
+	childLogger.Info().Str("func","ListRiskDecision").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("riskDecision", riskDecision).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListRiskDecision")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := riskDecisionQuery + `
+				WHERE decision = $1
+				and ($2 = '' or account_from = $2)
+				ORDER BY created_at desc
+				LIMIT $3`
+
+	rows, err := conn.Query(ctx, query, riskDecision.Decision, riskDecision.AccountFrom, limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res_list := []model.RiskDecision{}
+	for rows.Next() {
+		res_riskDecision, err := scanRiskDecision(rows)
+		if err != nil {
+			return nil, err
+		}
+		res_list = append(res_list, *res_riskDecision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return &res_list, nil
+}
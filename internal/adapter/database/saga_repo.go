@@ -0,0 +1,384 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About add a saga, it is committed at once (outside of the transfer transaction) to survive a rollback or a crash
+func (w WorkerRepository) AddSaga(ctx context.Context, saga *model.Saga) (*model.Saga, error){
+	childLogger.Info().Str("func","AddSaga").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("saga",saga).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddSaga")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	var id int
+	saga.CreatedAt = time.Now()
+	saga.UpdatedAt = saga.CreatedAt
+
+	payload, err := json.Marshal(saga.Transfer)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Query and Execute
+	query := `INSERT INTO transfer_saga(transaction_id,
+										type,
+										status,
+										payload,
+										created_at,
+										updated_at)
+				VALUES($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	row := conn.QueryRow(ctx, query,	saga.TransactionID,
+										saga.Type,
+										saga.Status,
+										payload,
+										saga.CreatedAt,
+										saga.UpdatedAt)
+
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	saga.ID = id
+	return saga, nil
+}
+
+// About update the status of a saga
+func (w WorkerRepository) UpdateSaga(ctx context.Context, saga *model.Saga) (int64, error){
+	childLogger.Info().Str("func","UpdateSaga").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("saga",saga).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateSaga")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	saga.UpdatedAt = time.Now()
+
+	// Query and Execute
+	query := `UPDATE transfer_saga
+				SET status = $2,
+					updated_at = $3
+				WHERE id = $1`
+
+	row, err := conn.Exec(ctx, query,	saga.ID,
+										saga.Status,
+										saga.UpdatedAt)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About refresh the updated_at of a running saga, the recovery claims only the sagas not touched for a while.
+// 0 rows (ErrUpdateRows) when the saga is no longer running, the recovery claimed it
+func (w WorkerRepository) TouchSaga(ctx context.Context, saga *model.Saga) (int64, error){
+	childLogger.Info().Str("func","TouchSaga").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("saga",saga.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.TouchSaga")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	saga.UpdatedAt = time.Now()
+
+	// Query and Execute
+	query := `UPDATE transfer_saga
+				SET updated_at = $2
+				WHERE id = $1
+				AND status = $3`
+
+	row, err := conn.Exec(ctx, query,	saga.ID,
+										saga.UpdatedAt,
+										model.SagaRunning)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About complete a saga inside the transfer transaction, so a saga is completed only if the transfer is committed
+func (w WorkerRepository) CompleteSaga(ctx context.Context, tx port.Tx, saga *model.Saga) (int64, error){
+	childLogger.Info().Str("func","CompleteSaga").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("saga",saga).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.CompleteSaga")
+	defer span.End()
+
+	// Prepare
+	saga.Status = model.SagaCompleted
+	saga.UpdatedAt = time.Now()
+
+	// Query and Execute
+	query := `UPDATE transfer_saga
+				SET status = $2,
+					updated_at = $3
+				WHERE id = $1
+				AND status = $4`
+
+	row, err := pgxTx(tx).Exec(ctx, query,	saga.ID,
+									saga.Status,
+									saga.UpdatedAt,
+									model.SagaRunning)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About add a saga step
+func (w WorkerRepository) AddSagaStep(ctx context.Context, sagaStep *model.SagaStep) (*model.SagaStep, error){
+	childLogger.Info().Str("func","AddSagaStep").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("sagaStep",sagaStep).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddSagaStep")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	var id int
+	sagaStep.CreatedAt = time.Now()
+	sagaStep.UpdatedAt = sagaStep.CreatedAt
+
+	// Query and Execute
+	query := `INSERT INTO transfer_saga_step(	fk_saga_id,
+												name,
+												action,
+												status,
+												error,
+												created_at,
+												updated_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	row := conn.QueryRow(ctx, query,	sagaStep.FkSagaID,
+										sagaStep.Name,
+										sagaStep.Action,
+										sagaStep.Status,
+										sagaStep.Error,
+										sagaStep.CreatedAt,
+										sagaStep.UpdatedAt)
+
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	sagaStep.ID = id
+	return sagaStep, nil
+}
+
+// About update the status of a saga step
+func (w WorkerRepository) UpdateSagaStep(ctx context.Context, sagaStep *model.SagaStep) (int64, error){
+	childLogger.Info().Str("func","UpdateSagaStep").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("sagaStep",sagaStep).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateSagaStep")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	sagaStep.UpdatedAt = time.Now()
+
+	// Query and Execute
+	query := `UPDATE transfer_saga_step
+				SET status = $2,
+					error = $3,
+					updated_at = $4
+				WHERE id = $1`
+
+	row, err := conn.Exec(ctx, query,	sagaStep.ID,
+										sagaStep.Status,
+										sagaStep.Error,
+										sagaStep.UpdatedAt)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About claim the sagas left unfinished (ex: pod crash) moving them to COMPENSATING, SKIP LOCKED lets many pods recover in parallel
+func (w WorkerRepository) ClaimSagaRecovery(ctx context.Context, olderThan time.Time, limit int) (*[]model.Saga, error){
+	childLogger.Info().Str("func","ClaimSagaRecovery").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ClaimSagaRecovery")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_list := []model.Saga{}
+
+	// Query and Execute
+	query := `UPDATE transfer_saga
+				SET status = $1,
+					updated_at = $2
+				WHERE id IN (	SELECT id
+								FROM transfer_saga
+								WHERE status IN ($3, $1)
+								AND updated_at < $4
+								ORDER BY id
+								LIMIT $5
+								FOR UPDATE SKIP LOCKED )
+				RETURNING id,
+						transaction_id,
+						type,
+						status,
+						payload,
+						created_at,
+						updated_at`
+
+	rows, err := conn.Query(ctx, query,	model.SagaCompensating,
+										time.Now(),
+										model.SagaRunning,
+										olderThan,
+										limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		saga := model.Saga{}
+		var payload []byte
+		err := rows.Scan(	&saga.ID,
+							&saga.TransactionID,
+							&saga.Type,
+							&saga.Status,
+							&payload,
+							&saga.CreatedAt,
+							&saga.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		err = json.Unmarshal(payload, &saga.Transfer)
+		if err != nil {
+			return nil, erro.ErrUnmarshal
+		}
+		res_list = append(res_list, saga)
+	}
+
+	return &res_list, nil
+}
+
+// About list the steps of a saga
+func (w WorkerRepository) ListSagaStep(ctx context.Context, saga *model.Saga) (*[]model.SagaStep, error){
+	childLogger.Info().Str("func","ListSagaStep").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListSagaStep")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_list := []model.SagaStep{}
+
+	// Query and Execute
+	query := `SELECT id,
+					fk_saga_id,
+					name,
+					action,
+					status,
+					error,
+					created_at,
+					updated_at
+				FROM transfer_saga_step
+				WHERE fk_saga_id = $1
+				ORDER BY id`
+
+	rows, err := conn.Query(ctx, query, saga.ID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sagaStep := model.SagaStep{}
+		err := rows.Scan(	&sagaStep.ID,
+							&sagaStep.FkSagaID,
+							&sagaStep.Name,
+							&sagaStep.Action,
+							&sagaStep.Status,
+							&sagaStep.Error,
+							&sagaStep.CreatedAt,
+							&sagaStep.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_list = append(res_list, sagaStep)
+	}
+
+	return &res_list, nil
+}
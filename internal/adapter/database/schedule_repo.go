@@ -0,0 +1,306 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"errors"
+	"time"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About the select of a schedule (scanned by scanSchedule)
+const scheduleQuery = `SELECT 	id,
+								type,
+								payload,
+								execute_at,
+								status,
+								attempts,
+								last_error,
+								fk_transfer_id,
+								locked_until,
+								created_at,
+								updated_at
+						FROM transfer_schedule`
+
+// About the columns of scheduleQuery, returned by the claim
+const scheduleColumns = `id, type, payload, execute_at, status, attempts, last_error, fk_transfer_id, locked_until, created_at, updated_at`
+
+// About scan a row of scheduleQuery
+func scanSchedule(rows pgx.Rows) (*model.Schedule, error) {
+	res_schedule := model.Schedule{}
+	var payload []byte
+
+	err := rows.Scan(	&res_schedule.ID,
+						&res_schedule.Type,
+						&payload,
+						&res_schedule.ExecuteAt,
+						&res_schedule.Status,
+						&res_schedule.Attempts,
+						&res_schedule.LastError,
+						&res_schedule.FkTransferID,
+						&res_schedule.LockedUntil,
+						&res_schedule.CreatedAt,
+						&res_schedule.UpdatedAt,
+					)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	err = json.Unmarshal(payload, &res_schedule.Transfer)
+	if err != nil {
+		return nil, erro.ErrUnmarshal
+	}
+
+	return &res_schedule, nil
+}
+
+// About add a schedule
+func (w WorkerRepository) AddSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error){
+	childLogger.Info().Str("func","AddSchedule").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("schedule",schedule).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddSchedule")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	var id int
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = schedule.CreatedAt
+
+	payload, err := json.Marshal(schedule.Transfer)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Query and Execute
+	query := `INSERT INTO transfer_schedule(type,
+											payload,
+											execute_at,
+											status,
+											attempts,
+											last_error,
+											created_at,
+											updated_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+
+	row := conn.QueryRow(ctx, query,	schedule.Type,
+										payload,
+										schedule.ExecuteAt,
+										schedule.Status,
+										schedule.Attempts,
+										schedule.LastError,
+										schedule.CreatedAt,
+										schedule.UpdatedAt)
+
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	schedule.ID = id
+	return schedule, nil
+}
+
+// About list the schedules by status ordered by execute_at
+func (w WorkerRepository) ListSchedule(ctx context.Context, schedule *model.Schedule, limit int) (*[]model.Schedule, error){
+	childLogger.Info().Str("func","ListSchedule").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListSchedule")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_list := []model.Schedule{}
+
+	// Query and Execute
+	query := scheduleQuery + ` WHERE status = $1 ORDER BY execute_at, id LIMIT $2`
+
+	rows, err := conn.Query(ctx, query, schedule.Status, limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		res_schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		res_list = append(res_list, *res_schedule)
+	}
+
+	return &res_list, nil
+}
+
+// About cancel a pending schedule, a schedule already running (claimed by the scheduler, lease held) is no longer pending
+func (w WorkerRepository) CancelSchedule(ctx context.Context, schedule *model.Schedule) (int64, error){
+	childLogger.Info().Str("func","CancelSchedule").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("schedule",schedule).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.CancelSchedule")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `UPDATE transfer_schedule
+				SET status = $2,
+					updated_at = $3
+				WHERE id = $1
+				AND status = $4
+				AND (locked_until IS NULL OR locked_until <= $3)`
+
+	row, err := conn.Exec(ctx, query,	schedule.ID,
+										model.ScheduleCanceled,
+										time.Now(),
+										model.SchedulePending)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		// tells apart an unknown schedule from one that is no longer pending
+		var status string
+		err = conn.QueryRow(ctx, `SELECT status FROM transfer_schedule WHERE id = $1`, schedule.ID).Scan(&status)
+		if err == pgx.ErrNoRows {
+			return 0, erro.ErrNotFound
+		}
+		if err != nil {
+			return 0, errors.New(err.Error())
+		}
+		return 0, erro.ErrStatusTransition
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About claim the due schedules until lockedUntil (lease) counting an attempt, the claim is committed before the execution.
+// SKIP LOCKED lets many schedulers (pods) claim in parallel, a schedule whose lease expired (crash) is claimed again
+func (w WorkerRepository) ClaimScheduleDue(ctx context.Context, lockedUntil time.Time, limit int) (*[]model.Schedule, error){
+	childLogger.Debug().Str("func","ClaimScheduleDue").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ClaimScheduleDue")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_list := []model.Schedule{}
+	now := time.Now()
+
+	// Query and Execute
+	query := `UPDATE transfer_schedule
+				SET locked_until = $1,
+					attempts = attempts + 1,
+					updated_at = $2
+				WHERE id IN (	SELECT id
+								FROM transfer_schedule
+								WHERE status = $3
+								AND execute_at <= $2
+								AND (locked_until IS NULL OR locked_until <= $2)
+								ORDER BY execute_at, id
+								LIMIT $4
+								FOR UPDATE SKIP LOCKED )
+				RETURNING ` + scheduleColumns
+
+	rows, err := conn.Query(ctx, query, lockedUntil, now, model.SchedulePending, limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		res_schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		res_list = append(res_list, *res_schedule)
+	}
+
+	sort.Slice(res_list, func(i, j int) bool {
+		if res_list[i].ExecuteAt.Equal(res_list[j].ExecuteAt) {
+			return res_list[i].ID < res_list[j].ID
+		}
+		return res_list[i].ExecuteAt.Before(res_list[j].ExecuteAt)
+	})
+
+	return &res_list, nil
+}
+
+// About update a schedule after an execution releasing its lease, only while the lease claimed (LockedUntil) is held
+func (w WorkerRepository) UpdateSchedule(ctx context.Context, schedule *model.Schedule) (int64, error){
+	childLogger.Info().Str("func","UpdateSchedule").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("schedule",schedule.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateSchedule")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	schedule.UpdatedAt = time.Now()
+
+	// Query and Execute
+	query := `UPDATE transfer_schedule
+				SET status = $2,
+					execute_at = $3,
+					attempts = $4,
+					last_error = $5,
+					fk_transfer_id = $6,
+					locked_until = NULL,
+					updated_at = $7
+				WHERE id = $1
+				AND locked_until = $8`
+
+	row, err := conn.Exec(ctx, query,	schedule.ID,
+										schedule.Status,
+										schedule.ExecuteAt,
+										schedule.Attempts,
+										schedule.LastError,
+										schedule.FkTransferID,
+										schedule.UpdatedAt,
+										schedule.LockedUntil)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	schedule.LockedUntil = nil
+	return row.RowsAffected(), nil
+}
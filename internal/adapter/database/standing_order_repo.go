@@ -0,0 +1,478 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"errors"
+	"time"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About the select of a standing order (scanned by scanStandingOrder)
+const standingOrderQuery = `SELECT 	id,
+									payload,
+									frequency,
+									interval,
+									start_at,
+									end_at,
+									max_occurrences,
+									failure_policy,
+									occurrences,
+									attempts,
+									next_execute_at,
+									status,
+									last_error,
+									locked_until,
+									created_at,
+									updated_at
+							FROM standing_order`
+
+// About the columns of standingOrderQuery, returned by the claim
+const standingOrderColumns = `id, payload, frequency, interval, start_at, end_at, max_occurrences, failure_policy, occurrences, attempts, next_execute_at, status, last_error, locked_until, created_at, updated_at`
+
+// About scan a row of standingOrderQuery
+func scanStandingOrder(rows pgx.Rows) (*model.StandingOrder, error) {
+	res_standingOrder := model.StandingOrder{}
+	var payload []byte
+
+	err := rows.Scan(	&res_standingOrder.ID,
+						&payload,
+						&res_standingOrder.Frequency,
+						&res_standingOrder.Interval,
+						&res_standingOrder.StartAt,
+						&res_standingOrder.EndAt,
+						&res_standingOrder.MaxOccurrences,
+						&res_standingOrder.FailurePolicy,
+						&res_standingOrder.Occurrences,
+						&res_standingOrder.Attempts,
+						&res_standingOrder.NextExecuteAt,
+						&res_standingOrder.Status,
+						&res_standingOrder.LastError,
+						&res_standingOrder.LockedUntil,
+						&res_standingOrder.CreatedAt,
+						&res_standingOrder.UpdatedAt,
+					)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	err = json.Unmarshal(payload, &res_standingOrder.Transfer)
+	if err != nil {
+		return nil, erro.ErrUnmarshal
+	}
+
+	return &res_standingOrder, nil
+}
+
+// About add a standing order
+func (w WorkerRepository) AddStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (*model.StandingOrder, error){
+	childLogger.Info().Str("func","AddStandingOrder").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("standingOrder",standingOrder).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddStandingOrder")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	var id int
+	standingOrder.CreatedAt = time.Now()
+	standingOrder.UpdatedAt = standingOrder.CreatedAt
+
+	payload, err := json.Marshal(standingOrder.Transfer)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Query and Execute
+	query := `INSERT INTO standing_order(	payload,
+											frequency,
+											interval,
+											start_at,
+											end_at,
+											max_occurrences,
+											failure_policy,
+											occurrences,
+											attempts,
+											next_execute_at,
+											status,
+											last_error,
+											created_at,
+											updated_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id`
+
+	row := conn.QueryRow(ctx, query,	payload,
+										standingOrder.Frequency,
+										standingOrder.Interval,
+										standingOrder.StartAt,
+										standingOrder.EndAt,
+										standingOrder.MaxOccurrences,
+										standingOrder.FailurePolicy,
+										standingOrder.Occurrences,
+										standingOrder.Attempts,
+										standingOrder.NextExecuteAt,
+										standingOrder.Status,
+										standingOrder.LastError,
+										standingOrder.CreatedAt,
+										standingOrder.UpdatedAt)
+
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	standingOrder.ID = id
+	return standingOrder, nil
+}
+
+// About get a standing order
+func (w WorkerRepository) GetStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (*model.StandingOrder, error){
+	childLogger.Info().Str("func","GetStandingOrder").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetStandingOrder")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := standingOrderQuery + ` WHERE id = $1`
+
+	rows, err := conn.Query(ctx, query, standingOrder.ID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanStandingOrder(rows)
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About list the standing orders by status
+func (w WorkerRepository) ListStandingOrder(ctx context.Context, standingOrder *model.StandingOrder, limit int) (*[]model.StandingOrder, error){
+	childLogger.Info().Str("func","ListStandingOrder").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListStandingOrder")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_list := []model.StandingOrder{}
+
+	// Query and Execute
+	query := standingOrderQuery + ` WHERE status = $1 ORDER BY id LIMIT $2`
+
+	rows, err := conn.Query(ctx, query, standingOrder.Status, limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		res_standingOrder, err := scanStandingOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		res_list = append(res_list, *res_standingOrder)
+	}
+
+	return &res_list, nil
+}
+
+// About cancel an active standing order, not while an occurrence runs (claimed by the runner, lease held)
+func (w WorkerRepository) CancelStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (int64, error){
+	childLogger.Info().Str("func","CancelStandingOrder").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("standingOrder",standingOrder.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.CancelStandingOrder")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `UPDATE standing_order
+				SET status = $2,
+					updated_at = $3
+				WHERE id = $1
+				AND status = $4
+				AND (locked_until IS NULL OR locked_until <= $3)`
+
+	row, err := conn.Exec(ctx, query,	standingOrder.ID,
+										model.StandingOrderCanceled,
+										time.Now(),
+										model.StandingOrderActive)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		// tells apart an unknown standing order from one that is no longer active
+		var status string
+		err = conn.QueryRow(ctx, `SELECT status FROM standing_order WHERE id = $1`, standingOrder.ID).Scan(&status)
+		if err == pgx.ErrNoRows {
+			return 0, erro.ErrNotFound
+		}
+		if err != nil {
+			return 0, errors.New(err.Error())
+		}
+		return 0, erro.ErrStatusTransition
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About claim the due standing orders until lockedUntil (lease) counting an attempt, the claim is committed before the execution.
+// SKIP LOCKED lets many runners (pods) claim in parallel, a standing order whose lease expired (crash) is claimed again
+func (w WorkerRepository) ClaimStandingOrderDue(ctx context.Context, lockedUntil time.Time, limit int) (*[]model.StandingOrder, error){
+	childLogger.Debug().Str("func","ClaimStandingOrderDue").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ClaimStandingOrderDue")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_list := []model.StandingOrder{}
+	now := time.Now()
+
+	// Query and Execute
+	query := `UPDATE standing_order
+				SET locked_until = $1,
+					attempts = attempts + 1,
+					updated_at = $2
+				WHERE id IN (	SELECT id
+								FROM standing_order
+								WHERE status = $3
+								AND next_execute_at <= $2
+								AND (locked_until IS NULL OR locked_until <= $2)
+								ORDER BY next_execute_at, id
+								LIMIT $4
+								FOR UPDATE SKIP LOCKED )
+				RETURNING ` + standingOrderColumns
+
+	rows, err := conn.Query(ctx, query, lockedUntil, now, model.StandingOrderActive, limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		res_standingOrder, err := scanStandingOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		res_list = append(res_list, *res_standingOrder)
+	}
+
+	sort.Slice(res_list, func(i, j int) bool {
+		if res_list[i].NextExecuteAt.Equal(res_list[j].NextExecuteAt) {
+			return res_list[i].ID < res_list[j].ID
+		}
+		return res_list[i].NextExecuteAt.Before(res_list[j].NextExecuteAt)
+	})
+
+	return &res_list, nil
+}
+
+// About update the progress of a standing order releasing its lease, only while the lease claimed (LockedUntil) is held
+func (w WorkerRepository) UpdateStandingOrder(ctx context.Context, tx port.Tx, standingOrder *model.StandingOrder) (int64, error){
+	childLogger.Info().Str("func","UpdateStandingOrder").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("standingOrder",standingOrder.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateStandingOrder")
+	defer span.End()
+
+	// Prepare
+	standingOrder.UpdatedAt = time.Now()
+
+	// Query and Execute
+	query := `UPDATE standing_order
+				SET occurrences = $2,
+					attempts = $3,
+					next_execute_at = $4,
+					status = $5,
+					last_error = $6,
+					locked_until = NULL,
+					updated_at = $7
+				WHERE id = $1
+				AND locked_until = $8`
+
+	row, err := pgxTx(tx).Exec(ctx, query,	standingOrder.ID,
+									standingOrder.Occurrences,
+									standingOrder.Attempts,
+									standingOrder.NextExecuteAt,
+									standingOrder.Status,
+									standingOrder.LastError,
+									standingOrder.UpdatedAt,
+									standingOrder.LockedUntil)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About add an occurrence into the history of a standing order
+func (w WorkerRepository) AddStandingOrderOccurrence(ctx context.Context, tx port.Tx, occurrence *model.StandingOrderOccurrence) (*model.StandingOrderOccurrence, error){
+	childLogger.Info().Str("func","AddStandingOrderOccurrence").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("occurrence",occurrence).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddStandingOrderOccurrence")
+	defer span.End()
+
+	// Prepare
+	var id int
+	occurrence.ExecutedAt = time.Now()
+
+	// Query and Execute
+	query := `INSERT INTO standing_order_occurrence(fk_standing_order_id,
+													sequence,
+													scheduled_at,
+													status,
+													attempts,
+													last_error,
+													fk_transfer_id,
+													executed_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	occurrence.FkStandingOrderID,
+									occurrence.Sequence,
+									occurrence.ScheduledAt,
+									occurrence.Status,
+									occurrence.Attempts,
+									occurrence.LastError,
+									occurrence.FkTransferID,
+									occurrence.ExecutedAt)
+
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	occurrence.ID = id
+	return occurrence, nil
+}
+
+// About resolve the occurrence held with a transfer (by fk_transfer_id), a transfer of no held occurrence updates nothing
+func (w WorkerRepository) ResolveStandingOrderOccurrence(ctx context.Context, tx port.Tx, occurrence *model.StandingOrderOccurrence) (int64, error){
+	childLogger.Info().Str("func","ResolveStandingOrderOccurrence").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("occurrence",occurrence).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ResolveStandingOrderOccurrence")
+	defer span.End()
+
+	// Query and Execute
+	query := `UPDATE standing_order_occurrence
+				SET status = $2,
+					last_error = $3
+				WHERE fk_transfer_id = $1
+				AND status = $4`
+
+	row, err := pgxTx(tx).Exec(ctx, query,	occurrence.FkTransferID,
+									occurrence.Status,
+									occurrence.LastError,
+									model.OccurrenceHeld)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About list the history of a standing order
+func (w WorkerRepository) ListStandingOrderOccurrence(ctx context.Context, standingOrder *model.StandingOrder) (*[]model.StandingOrderOccurrence, error){
+	childLogger.Info().Str("func","ListStandingOrderOccurrence").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListStandingOrderOccurrence")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_list := []model.StandingOrderOccurrence{}
+
+	// Query and Execute
+	query := `SELECT id,
+					fk_standing_order_id,
+					sequence,
+					scheduled_at,
+					status,
+					attempts,
+					last_error,
+					fk_transfer_id,
+					executed_at
+				FROM standing_order_occurrence
+				WHERE fk_standing_order_id = $1
+				ORDER BY sequence`
+
+	rows, err := conn.Query(ctx, query, standingOrder.ID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		occurrence := model.StandingOrderOccurrence{}
+		err := rows.Scan(	&occurrence.ID,
+							&occurrence.FkStandingOrderID,
+							&occurrence.Sequence,
+							&occurrence.ScheduledAt,
+							&occurrence.Status,
+							&occurrence.Attempts,
+							&occurrence.LastError,
+							&occurrence.FkTransferID,
+							&occurrence.ExecutedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_list = append(res_list, occurrence)
+	}
+
+	return &res_list, nil
+}
@@ -0,0 +1,207 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About get a transfer locking the row until the end of the transaction
+func (w WorkerRepository) GetTransferForUpdate(ctx context.Context, tx port.Tx, transfer *model.Transfer) (*model.Transfer, error){
+	childLogger.Info().Str("func","GetTransferForUpdate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetTransferForUpdate")
+	defer span.End()
+
+	// Prepare
+	res_transfer := model.Transfer{}
+
+	// Query and Execute
+	query := `SELECT id,
+					type_charge,
+					status,
+					transfer_at,
+					currency,
+					transaction_id
+				FROM transfer_moviment
+				WHERE id = $1
+				FOR UPDATE`
+
+	rows, err := pgxTx(tx).Query(ctx, query, transfer.ID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err := rows.Scan(	&res_transfer.ID,
+							&res_transfer.Type,
+							&res_transfer.Status,
+							&res_transfer.TransferAt,
+							&res_transfer.Currency,
+							&res_transfer.TransactionID,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_transfer, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About get the id of a transfer by its transaction_id
+func (w WorkerRepository) GetTransferIDByTransactionID(ctx context.Context, tx port.Tx, transfer *model.Transfer) (*model.Transfer, error){
+	childLogger.Info().Str("func","GetTransferIDByTransactionID").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetTransferIDByTransactionID")
+	defer span.End()
+
+	// Prepare
+	res_transfer := model.Transfer{}
+
+	// Query and Execute
+	query := `SELECT id,
+					transaction_id
+				FROM transfer_moviment
+				WHERE transaction_id = $1`
+
+	rows, err := pgxTx(tx).Query(ctx, query, transfer.TransactionID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err := rows.Scan(	&res_transfer.ID,
+							&res_transfer.TransactionID,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_transfer, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About update the status of a transfer
+func (w WorkerRepository) UpdateTransferStatus(ctx context.Context, tx port.Tx, transfer *model.Transfer) (int64, error){
+	childLogger.Info().Str("func","UpdateTransferStatus").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transfer",transfer).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateTransferStatus")
+	defer span.End()
+
+	// Query and Execute
+	query := `UPDATE transfer_moviment
+				SET status = $2
+				WHERE id = $1`
+
+	row, err := pgxTx(tx).Exec(ctx, query,	transfer.ID,
+									transfer.Status)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	if row.RowsAffected() == 0 {
+		return 0, erro.ErrUpdateRows
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About record a status transition of a transfer
+func (w WorkerRepository) AddStatusTransition(ctx context.Context, tx port.Tx, statusTransition *model.StatusTransition) (*model.StatusTransition, error){
+	childLogger.Info().Str("func","AddStatusTransition").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("statusTransition",statusTransition).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddStatusTransition")
+	defer span.End()
+
+	// Prepare
+	var id int
+	if statusTransition.ChangedAt.IsZero() {
+		statusTransition.ChangedAt = time.Now()
+	}
+
+	// Query and Execute
+	query := `INSERT INTO transfer_status_history(	fk_transfer_id,
+													status_from,
+													status_to,
+													reason,
+													changed_at)
+				VALUES($1, $2, $3, $4, $5) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	statusTransition.FkTransferID,
+									statusTransition.StatusFrom,
+									statusTransition.StatusTo,
+									statusTransition.Reason,
+									statusTransition.ChangedAt)
+
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	statusTransition.ID = id
+	return statusTransition, nil
+}
+
+// About list the status transitions of a transfer
+func (w WorkerRepository) ListStatusTransition(ctx context.Context, transfer *model.Transfer) (*[]model.StatusTransition, error){
+	childLogger.Info().Str("func","ListStatusTransition").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListStatusTransition")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_list := []model.StatusTransition{}
+
+	// Query and Execute
+	query := `SELECT id,
+					fk_transfer_id,
+					status_from,
+					status_to,
+					reason,
+					changed_at
+				FROM transfer_status_history
+				WHERE fk_transfer_id = $1
+				ORDER BY id`
+
+	rows, err := conn.Query(ctx, query, transfer.ID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		statusTransition := model.StatusTransition{}
+		err := rows.Scan(	&statusTransition.ID,
+							&statusTransition.FkTransferID,
+							&statusTransition.StatusFrom,
+							&statusTransition.StatusTo,
+							&statusTransition.Reason,
+							&statusTransition.ChangedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_list = append(res_list, statusTransition)
+	}
+
+	return &res_list, nil
+}
@@ -1,179 +1,399 @@
-package database
-
-import (
-	"context"
-	"errors"
-	"time"
-
-	"github.com/go-fund-transfer/internal/core/model"
-	"github.com/go-fund-transfer/internal/core/erro"
-
-	go_core_observ "github.com/eliezerraj/go-core/observability"
-	go_core_pg "github.com/eliezerraj/go-core/database/pg"
-
-	"github.com/jackc/pgx/v5"
-	"github.com/rs/zerolog/log"
-)
-
-var childLogger = log.With().Str("component","go-fund-transfer").Str("package","internal.adapter.database").Logger()
-var tracerProvider go_core_observ.TracerProvider
-
-type WorkerRepository struct {
-	DatabasePGServer *go_core_pg.DatabasePGServer
-}
-
-func NewWorkerRepository(databasePGServer *go_core_pg.DatabasePGServer) *WorkerRepository{
-	childLogger.Info().Str("func","NewWorkerRepository").Send()
-
-	return &WorkerRepository{
-		DatabasePGServer: databasePGServer,
-	}
-}
-
-// About create a uuid transaction
-func (w WorkerRepository) GetTransactionUUID(ctx context.Context) (*string, error){
-	childLogger.Info().Str("func","GetTransactionUUID").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
-	
-	// Trace
-	span := tracerProvider.Span(ctx, "database.GetTransactionUUID")
-	defer span.End()
-
-	// get DB connection
-	conn, err := w.DatabasePGServer.Acquire(ctx)
-	if err != nil {
-		return nil, errors.New(err.Error())
-	}
-	defer w.DatabasePGServer.Release(conn)
-
-	// Prepare
-	var uuid string
-
-	// Query and Execute
-	query := `SELECT uuid_generate_v4()`
-
-	rows, err := conn.Query(ctx, query)
-	if err != nil {
-		return nil, errors.New(err.Error())
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		err := rows.Scan(&uuid) 
-		if err != nil {
-			return nil, errors.New(err.Error())
-        }
-		return &uuid, nil
-	}
-	
-	return &uuid, nil
-}
-
-// About add a transfer transaction
-func (w WorkerRepository) AddTransfer(ctx context.Context, tx pgx.Tx, transfer *model.Transfer) (*model.Transfer, error){
-	childLogger.Info().Str("func","AddTransfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transfer",transfer).Send()
-
-	// Trace
-	span := tracerProvider.Span(ctx, "database.AddTransfer")
-	defer span.End()
-
-	// Prepare
-	var id int
-	transfer.TransferAt = time.Now()
-
-	// Query and Execute
-	query := `INSERT INTO transfer_moviment(fk_account_id_from, 
-											fk_account_id_to,
-											type_charge,
-											status,  
-											transfer_at,
-											currency,
-											amount,
-											transaction_id) 
-				VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
-
-	row := tx.QueryRow(ctx, query,	transfer.AccountFrom.FkAccountID, 
-									transfer.AccountTo.FkAccountID,
-									transfer.Type,
-									transfer.Status,
-									transfer.TransferAt,
-									transfer.Currency,
-									transfer.Amount,
-									transfer.TransactionID)				
-
-	if err := row.Scan(&id); err != nil {
-		return nil, errors.New(err.Error())
-	}
-
-	// Set PK
-	transfer.ID = id
-	return transfer , nil
-}
-
-// About add transfer transaction
-func (w WorkerRepository) GetTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error){
-	childLogger.Info().Str("func","GetTransfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
-
-	// Trace
-	span := tracerProvider.Span(ctx, "database.GetTransfer")
-	defer span.End()
-
-	// get DB connection
-	conn, err := w.DatabasePGServer.Acquire(ctx)
-	if err != nil {
-		return nil, errors.New(err.Error())
-	}
-	defer w.DatabasePGServer.Release(conn)
-
-	// Prepare
-	res_accountFrom := model.AccountStatement{}
-	res_accountTo := model.AccountStatement{}
-	res_transfer := model.Transfer{	AccountFrom: &res_accountFrom,
-									AccountTo: &res_accountTo}
-
-	// Query e Execute
-	query :=  `SELECT 	trans.id,
-						fk_account_id_from,
-						fr.account_id,
-						fk_account_id_to,
-						t.account_id,
-						type_charge,
-						status,
-						transfer_at,
-						currency, 
-						amount,
-						transaction_id
-				FROM transfer_moviment as trans,
-					account as fr,
-					account as t
-				WHERE trans.id = $1
-				and fk_account_id_from = fr.id
-				and fk_account_id_to = t.id	`
-
-	rows, err := conn.Query(ctx, query, transfer.ID)
-	if err != nil {
-		return nil, errors.New(err.Error())
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		err := rows.Scan( 	&res_transfer.ID,
-							&res_accountFrom.FkAccountID, 
-							&res_accountFrom.AccountID, 
-							&res_accountTo.FkAccountID,
-							&res_accountTo.AccountID,
-							&res_transfer.Type, 
-							&res_transfer.Status,
-							&res_transfer.TransferAt,
-							&res_transfer.Currency,
-							&res_transfer.Amount,
-							&res_transfer.TransactionID,
-						)
-		if err != nil {
-			return nil, errors.New(err.Error())
-        }
-
-		return &res_transfer , nil
-	}
-
-	return nil, erro.ErrNotFound
-}
\ No newline at end of file
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+	"math/big"
+	"fmt"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+	go_core_pg "github.com/eliezerraj/go-core/database/pg"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+var childLogger = log.With().Str("component","go-fund-transfer").Str("package","internal.adapter.database").Logger()
+var tracerProvider go_core_observ.TracerProvider
+
+type WorkerRepository struct {
+	DatabasePGServer *go_core_pg.DatabasePGServer
+}
+
+func NewWorkerRepository(databasePGServer *go_core_pg.DatabasePGServer) *WorkerRepository{
+	childLogger.Info().Str("func","NewWorkerRepository").Send()
+
+	return &WorkerRepository{
+		DatabasePGServer: databasePGServer,
+	}
+}
+
+// About create a uuid transaction
+func (w WorkerRepository) GetTransactionUUID(ctx context.Context) (*string, error){
+	childLogger.Info().Str("func","GetTransactionUUID").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+	
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetTransactionUUID")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	var uuid string
+
+	// Query and Execute
+	query := `SELECT uuid_generate_v4()`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err := rows.Scan(&uuid) 
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &uuid, nil
+	}
+	
+	return &uuid, nil
+}
+
+// About add a transfer transaction
+func (w WorkerRepository) AddTransfer(ctx context.Context, tx port.Tx, transfer *model.Transfer) (*model.Transfer, error){
+	childLogger.Info().Str("func","AddTransfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transfer",transfer).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddTransfer")
+	defer span.End()
+
+	// Prepare
+	var id int
+	transfer.TransferAt = time.Now()
+
+	// Query and Execute
+	query := `INSERT INTO transfer_moviment(fk_account_id_from, 
+											fk_account_id_to,
+											type_charge,
+											status,  
+											transfer_at,
+											currency,
+											amount,
+											transaction_id,
+											fx_rate,
+											currency_to,
+											amount_to,
+											fx_rate_at,
+											fx_fee,
+											quote_id) 
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id`
+
+	// the fx columns are null when the legs have the same currency
+	var fx_rate pgtype.Numeric
+	var currency_to *string
+	var amount_to pgtype.Numeric
+	var fx_rate_at *time.Time
+	var fx_fee pgtype.Numeric
+	var quote_id *string
+	if transfer.Fx != nil {
+		if err := fx_rate.Scan(transfer.Fx.Rate); err != nil {
+			return nil, errors.New(err.Error())
+		}
+		currency_to = &transfer.Fx.DestinationCurrency
+		amount_to = moneyToNumeric(transfer.Fx.DestinationAmount)
+		fx_rate_at = &transfer.Fx.RateAt
+		fx_fee = moneyToNumeric(transfer.Fx.Fee)
+		if transfer.Fx.QuoteID != "" {
+			quote_id = &transfer.Fx.QuoteID
+		}
+	}
+
+	row := pgxTx(tx).QueryRow(ctx, query,	transfer.AccountFrom.FkAccountID, 
+									transfer.AccountTo.FkAccountID,
+									transfer.Type,
+									transfer.Status,
+									transfer.TransferAt,
+									transfer.Currency,
+									moneyToNumeric(transfer.Amount),
+									transfer.TransactionID,
+									fx_rate,
+									currency_to,
+									amount_to,
+									fx_rate_at,
+									fx_fee,
+									quote_id)				
+
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	transfer.ID = id
+
+	// Record the initial status
+	_, err := w.AddStatusTransition(ctx, tx, &model.StatusTransition{	FkTransferID: id,
+																		StatusTo: transfer.Status,
+																		ChangedAt: transfer.TransferAt })
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer , nil
+}
+
+// About add transfer transaction
+func (w WorkerRepository) GetTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error){
+	childLogger.Info().Str("func","GetTransfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetTransfer")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query e Execute
+	query := transferQuery + ` and trans.id = $1`
+
+	rows, err := conn.Query(ctx, query, transfer.ID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		res_transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		return res_transfer , nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About get a transfer by its transaction_id (the uuid sent to go-debit, go-credit and kafka)
+func (w WorkerRepository) GetTransferByTransactionID(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error){
+	childLogger.Info().Str("func","GetTransferByTransactionID").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetTransferByTransactionID")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query e Execute
+	query := transferQuery + ` and trans.transaction_id = $1`
+
+	rows, err := conn.Query(ctx, query, transfer.TransactionID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		res_transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		return res_transfer , nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About the select of a transfer joined with the account_id of both legs (scanned by scanTransfer)
+const transferQuery = `SELECT 	trans.id,
+								fk_account_id_from,
+								fr.account_id,
+								fk_account_id_to,
+								t.account_id,
+								type_charge,
+								status,
+								transfer_at,
+								currency, 
+								amount,
+								transaction_id,
+								fx_rate,
+								currency_to,
+								amount_to,
+								fx_rate_at,
+								fx_fee,
+								quote_id
+						FROM transfer_moviment as trans,
+							account as fr,
+							account as t
+						WHERE fk_account_id_from = fr.id
+						and fk_account_id_to = t.id`
+
+// About scan a row of transferQuery
+func scanTransfer(rows pgx.Rows) (*model.Transfer, error) {
+	res_accountFrom := model.AccountStatement{}
+	res_accountTo := model.AccountStatement{}
+	res_transfer := model.Transfer{	AccountFrom: &res_accountFrom,
+									AccountTo: &res_accountTo}
+	var amount pgtype.Numeric
+	var fx_rate pgtype.Numeric
+	var currency_to *string
+	var amount_to pgtype.Numeric
+	var fx_rate_at *time.Time
+	var fx_fee pgtype.Numeric
+	var quote_id *string
+
+	err := rows.Scan( 	&res_transfer.ID,
+						&res_accountFrom.FkAccountID, 
+						&res_accountFrom.AccountID, 
+						&res_accountTo.FkAccountID,
+						&res_accountTo.AccountID,
+						&res_transfer.Type, 
+						&res_transfer.Status,
+						&res_transfer.TransferAt,
+						&res_transfer.Currency,
+						&amount,
+						&res_transfer.TransactionID,
+						&fx_rate,
+						&currency_to,
+						&amount_to,
+						&fx_rate_at,
+						&fx_fee,
+						&quote_id,
+					)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	res_transfer.Amount, err = numericToMoney(amount, res_transfer.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if currency_to != nil && fx_rate.Valid {
+		rate, err := fx_rate.Value()
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+		amountTo, err := numericToMoney(amount_to, *currency_to)
+		if err != nil {
+			return nil, err
+		}
+		res_transfer.Fx = &model.FxConversion{	Rate: rate.(string),
+												SourceCurrency: res_transfer.Amount.Currency,
+												SourceAmount: res_transfer.Amount,
+												DestinationCurrency: amountTo.Currency,
+												DestinationAmount: amountTo }
+		if fx_rate_at != nil {
+			res_transfer.Fx.RateAt = *fx_rate_at
+		}
+		res_transfer.Fx.Fee, err = numericToMoney(fx_fee, res_transfer.Currency)
+		if err != nil {
+			return nil, err
+		}
+		if quote_id != nil {
+			res_transfer.Fx.QuoteID = *quote_id
+		}
+	}
+
+	return &res_transfer, nil
+}
+
+// About bind a money into a NUMERIC column without passing through float
+func moneyToNumeric(money model.Money) pgtype.Numeric {
+	return pgtype.Numeric{	Int: big.NewInt(money.Minor),
+							Exp: -money.Exponent(),
+							Valid: true }
+}
+
+// About read a NUMERIC column into a money of the given currency
+func numericToMoney(numeric pgtype.Numeric, currency string) (model.Money, error) {
+	if !numeric.Valid {
+		return model.NewMoney(0, currency)
+	}
+	return model.MoneyFromDecimal(numeric.Int, numeric.Exp, currency)
+}
+
+// About list the transfers matching a filter, one page after the cursor ordered by transfer_at desc
+func (w WorkerRepository) ListTransfer(ctx context.Context, transferFilter *model.TransferFilter) (*[]model.Transfer, error){
+	childLogger.Info().Str("func","ListTransfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferFilter", transferFilter).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListTransfer")
+	defer span.End()
+
+	// get DB connection
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_list := []model.Transfer{}
+	args := []any{}
+	query := transferQuery
+
+	if transferFilter.AccountID != "" {
+		args = append(args, transferFilter.AccountID)
+		query = query + fmt.Sprintf(` and (fr.account_id = $%v or t.account_id = $%v)`, len(args), len(args))
+	}
+	if transferFilter.Status != "" {
+		args = append(args, transferFilter.Status)
+		query = query + fmt.Sprintf(` and trans.status = $%v`, len(args))
+	}
+	if transferFilter.Currency != "" {
+		args = append(args, transferFilter.Currency)
+		query = query + fmt.Sprintf(` and trans.currency = $%v`, len(args))
+	}
+	if transferFilter.From != nil {
+		args = append(args, *transferFilter.From)
+		query = query + fmt.Sprintf(` and trans.transfer_at >= $%v`, len(args))
+	}
+	if transferFilter.To != nil {
+		args = append(args, *transferFilter.To)
+		query = query + fmt.Sprintf(` and trans.transfer_at < $%v`, len(args))
+	}
+	if transferFilter.Cursor != nil {
+		args = append(args, transferFilter.Cursor.TransferAt, transferFilter.Cursor.ID)
+		query = query + fmt.Sprintf(` and (trans.transfer_at, trans.id) < ($%v, $%v)`, len(args) - 1, len(args))
+	}
+	args = append(args, transferFilter.Limit)
+	query = query + fmt.Sprintf(` ORDER BY trans.transfer_at desc, trans.id desc LIMIT $%v`, len(args))
+
+	// Query e Execute
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		res_transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		res_list = append(res_list, *res_transfer)
+	}
+
+	return &res_list, nil
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-fund-transfer/internal/core/port"
+
+	go_core_pg "github.com/eliezerraj/go-core/database/pg"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ port.TransferRepository = (*WorkerRepository)(nil)
+
+// About a postgres transaction as a unit of work, it holds the connection until Commit or Rollback
+type pgTx struct {
+	pgx.Tx
+	conn				*pgxpool.Conn
+	databasePGServer	*go_core_pg.DatabasePGServer
+	released			bool
+}
+
+// About release the connection once
+func (t *pgTx) release() {
+	if !t.released {
+		t.released = true
+		t.databasePGServer.ReleaseTx(t.conn)
+	}
+}
+
+func (t *pgTx) Commit(ctx context.Context) error {
+	defer t.release()
+	return t.Tx.Commit(ctx)
+}
+
+func (t *pgTx) Rollback(ctx context.Context) error {
+	defer t.release()
+	return t.Tx.Rollback(ctx)
+}
+
+// About the pgx transaction of a unit of work started by WorkerRepository.Begin
+func pgxTx(tx port.Tx) pgx.Tx {
+	return tx.(*pgTx).Tx
+}
+
+// About start a unit of work
+func (w WorkerRepository) Begin(ctx context.Context) (port.Tx, error){
+	childLogger.Info().Str("func","Begin").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.Begin")
+	defer span.End()
+
+	tx, conn, err := w.DatabasePGServer.StartTx(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return &pgTx{	Tx: tx,
+					conn: conn,
+					databasePGServer: w.DatabasePGServer }, nil
+}
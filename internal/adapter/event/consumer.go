@@ -0,0 +1,186 @@
+package event
+
+import (
+	"time"
+	"context"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+	go_core_event "github.com/eliezerraj/go-core/event/kafka"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// About the function that applies a consumed event, an error means the event must be retried
+type TransferEventHandler func(ctx context.Context, transferEvent *model.TransferEvent) error
+
+type ConsumerEvent struct {
+	Topics			[]string
+	consumer		*kafka.Consumer
+	deadLetter		port.EventPublisher
+	consumerConfig	*model.ConsumerConfig
+}
+
+// About create a kafka consumer with manual offset commit.
+// The go-core consumer commits the position of the consumer (already ahead of the message handled),
+// here each offset is committed only after its message was applied or sent to the dead letter topic
+// (by a producer of its own, outside the kafka transactions of the publisher)
+func NewConsumerEvent(ctx context.Context, consumerConfig *model.ConsumerConfig, kafkaConfigurations *go_core_event.KafkaConfigurations) (*ConsumerEvent, error) {
+	childLogger.Info().Str("func","NewConsumerEvent").Send()
+
+	//trace
+	span := tracerProvider.Span(ctx, "adapter.event.NewConsumerEvent")
+	defer span.End()
+
+	kafkaBrokerUrls := 	kafkaConfigurations.Brokers1 + "," + kafkaConfigurations.Brokers2 + "," + kafkaConfigurations.Brokers3
+
+	config := &kafka.ConfigMap{	"bootstrap.servers":            kafkaBrokerUrls,
+								"security.protocol":            kafkaConfigurations.Protocol,
+								"sasl.mechanisms":              kafkaConfigurations.Mechanisms,
+								"sasl.username":                kafkaConfigurations.Username,
+								"sasl.password":                kafkaConfigurations.Password,
+								"group.id":                     consumerConfig.GroupID,
+								"enable.auto.commit":           false,
+								"broker.address.family": 		"v4",
+								"client.id": 					kafkaConfigurations.Clientid,
+								"session.timeout.ms":    		6000,
+								"max.poll.interval.ms":			consumerConfig.MaxPollInterval,
+								"isolation.level":				"read_committed",
+								"auto.offset.reset":     		"earliest",
+								}
+
+	deadLetter, err := NewWorkerEvent(ctx, kafkaConfigurations)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := kafka.NewConsumer(config)
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		return nil, err
+	}
+
+	return &ConsumerEvent{
+		Topics: consumerConfig.Topics,
+		consumer: consumer,
+		deadLetter: deadLetter,
+		consumerConfig: consumerConfig,
+	}, nil
+}
+
+// About consume the events until the context is done
+func (c *ConsumerEvent) Consume(ctx context.Context, handler TransferEventHandler) {
+	childLogger.Info().Str("func","Consume").Interface("topics", c.Topics).Send()
+
+	defer func() {
+		c.consumer.Close()
+		childLogger.Info().Msg("consumer closed !!!")
+	}()
+
+	err := c.consumer.SubscribeTopics(c.Topics, nil)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("failed to subscribe topics")
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		ev := c.consumer.Poll(100)
+		if ev == nil {
+			continue
+		}
+
+		switch e := ev.(type) {
+		case *kafka.Message:
+			if !c.handleMessage(ctx, e, handler) {
+				return
+			}
+		case kafka.Error:
+			childLogger.Error().Err(e).Msg("kafka.Error")
+		default:
+			childLogger.Debug().Interface("event", e).Msg("ignored")
+		}
+	}
+}
+
+// About apply a message, then commit its offset. Returns false when the context is done.
+// When the message could not be applied nor sent to the dead letter topic, the consumer seeks back to it
+// and the next poll delivers it again
+func (c *ConsumerEvent) handleMessage(ctx context.Context, message *kafka.Message, handler TransferEventHandler) bool {
+	err := c.applyMessage(ctx, message, handler)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		childLogger.Error().Err(err).Interface("partition", message.TopicPartition).Msg("event not applied nor dead lettered, seek back")
+
+		err = c.consumer.Seek(message.TopicPartition, 0)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("failed to seek back the event")
+		}
+		return true
+	}
+
+	_, err = c.consumer.CommitMessage(message)
+	if err != nil {
+		// the event will be delivered again and deduped by transaction_id
+		childLogger.Error().Err(err).Msg("failed to commit the offset")
+	}
+
+	return true
+}
+
+// About apply a message retrying with backoff up to MaxAttempts, the consumer does not poll in between
+// (the retries end before max.poll.interval.ms, see ConsumerConfig.Validate). An event still failing
+// (a transfer that never shows up ...) is sent to the dead letter topic, so the partition moves on.
+// Returns nil when the message can be committed
+func (c *ConsumerEvent) applyMessage(ctx context.Context, message *kafka.Message, handler TransferEventHandler) error {
+	headers := map[string]string{}
+	for _, header := range message.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+
+	ctx_event := context.WithValue(ctx, "trace-request-id", headers["trace-request-id"])
+
+	var transferEvent model.TransferEvent
+	err := json.Unmarshal(message.Value, &transferEvent)
+	if err != nil {
+		// a poison message is skipped, otherwise it would block the partition
+		childLogger.Error().Interface("trace-resquest-id", headers["trace-request-id"]).Err(err).Str("payload", string(message.Value)).Msg("event unmarshal error, skipped")
+		return nil
+	}
+	if transferEvent.EventType == "" {
+		transferEvent.EventType = headers["event_type"]
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = handler(ctx_event, &transferEvent)
+		if err == nil {
+			return nil
+		}
+		if attempt >= c.consumerConfig.MaxAttempts {
+			break
+		}
+		childLogger.Error().Interface("trace-resquest-id", headers["trace-request-id"]).Err(err).Int("attempt", attempt).Msg("failed to apply the event, retrying")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.consumerConfig.Backoff(attempt)):
+		}
+	}
+
+	childLogger.Error().Interface("trace-resquest-id", headers["trace-request-id"]).Err(err).Str("dead_letter_topic", c.consumerConfig.DeadLetterTopic).Msg("event not applied, dead lettered")
+
+	var trace_id *string
+	if value, ok := headers["trace-request-id"]; ok {
+		trace_id = &value
+	}
+	return c.deadLetter.Producer(ctx, c.consumerConfig.DeadLetterTopic, transferEvent.EventType, string(message.Key), trace_id, message.Value)
+}
@@ -0,0 +1,127 @@
+package event
+
+import (
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// About a consumer without kafka, its dead letter topic is an in memory publisher
+func newTestConsumer(deadLetter *MemoryPublisher) *ConsumerEvent {
+	return &ConsumerEvent{	Topics: []string{ "topic.completion" },
+							deadLetter: deadLetter,
+							consumerConfig: &model.ConsumerConfig{	Enabled: true,
+																	GroupID: "GROUP-01",
+																	Topics: []string{ "topic.completion" },
+																	DeadLetterTopic: "topic.completion.dlq",
+																	MaxAttempts: 3,
+																	RetryBackoff: 1,
+																	RetryBackoffMax: 2,
+																	MaxPollInterval: 1000 } }
+}
+
+// About a completion event as consumed from kafka
+func newTestMessage(payload string) *kafka.Message {
+	topic := "topic.completion"
+	return &kafka.Message{	TopicPartition: kafka.TopicPartition{ Topic: &topic, Partition: 0, Offset: 10 },
+							Key: []byte("tx-1"),
+							Value: []byte(payload),
+							Headers: []kafka.Header{ { Key: "trace-request-id", Value: []byte("trace-1") } } }
+}
+
+func TestConsumerApplyMessage(t *testing.T) {
+	tests := []struct {
+		name		string
+		payload		string
+		failures	int
+		wantCalls	int
+		wantDead	bool
+	}{
+		{ name: "applied", payload: `{"event_type":"CREDIT_DONE","transaction_id":"tx-1"}`, failures: 0, wantCalls: 1 },
+		{ name: "applied on a retry", payload: `{"event_type":"CREDIT_DONE","transaction_id":"tx-1"}`, failures: 2, wantCalls: 3 },
+		{ name: "attempts exhausted", payload: `{"event_type":"CREDIT_DONE","transaction_id":"tx-1"}`, failures: 10, wantCalls: 3, wantDead: true },
+		{ name: "poison message", payload: `{"event_type":`, failures: 10, wantCalls: 0 },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadLetter := NewMemoryPublisher()
+			consumerEvent := newTestConsumer(deadLetter)
+
+			calls := 0
+			handler := func(ctx context.Context, transferEvent *model.TransferEvent) error {
+				calls++
+				if calls <= tt.failures {
+					return erro.ErrEventTooEarly
+				}
+				return nil
+			}
+
+			// the offset is committed in every case, the partition moves on
+			err := consumerEvent.applyMessage(context.Background(), newTestMessage(tt.payload), handler)
+			if err != nil {
+				t.Fatalf("applyMessage: %v", err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+
+			published := deadLetter.Published()
+			if !tt.wantDead {
+				if len(published) != 0 {
+					t.Errorf("dead lettered = %+v, want none", published)
+				}
+				return
+			}
+			if len(published) != 1 {
+				t.Fatalf("dead lettered = %d, want 1", len(published))
+			}
+			if published[0].Topic != "topic.completion.dlq" || published[0].EventType != "CREDIT_DONE" || published[0].Key != "tx-1" || published[0].TraceID != "trace-1" || string(published[0].Payload) != tt.payload {
+				t.Errorf("dead lettered = %+v, want the event on topic.completion.dlq", published[0])
+			}
+		})
+	}
+}
+
+func TestConsumerApplyMessageDeadLetterFailed(t *testing.T) {
+	deadLetter := NewMemoryPublisher()
+	deadLetter.Err = errors.New("broker unavailable")
+	consumerEvent := newTestConsumer(deadLetter)
+
+	handler := func(ctx context.Context, transferEvent *model.TransferEvent) error {
+		return erro.ErrEventTooEarly
+	}
+
+	// not committed, the consumer seeks back to the event
+	err := consumerEvent.applyMessage(context.Background(), newTestMessage(`{"event_type":"DEBIT_DONE","transaction_id":"tx-1"}`), handler)
+	if err == nil || err.Error() != "broker unavailable" {
+		t.Errorf("err = %v, want the dead letter error", err)
+	}
+}
+
+func TestConsumerApplyMessageCanceled(t *testing.T) {
+	deadLetter := NewMemoryPublisher()
+	consumerEvent := newTestConsumer(deadLetter)
+	consumerEvent.consumerConfig.RetryBackoff = 60000
+	consumerEvent.consumerConfig.RetryBackoffMax = 60000
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := func(ctx context.Context, transferEvent *model.TransferEvent) error {
+		cancel()
+		return erro.ErrEventTooEarly
+	}
+
+	// a shutdown during the backoff neither commits nor dead letters the event
+	err := consumerEvent.applyMessage(ctx, newTestMessage(`{"event_type":"DEBIT_DONE","transaction_id":"tx-1"}`), handler)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+	if len(deadLetter.Published()) != 0 {
+		t.Errorf("dead lettered = %d, want 0", len(deadLetter.Published()))
+	}
+}
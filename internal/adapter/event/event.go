@@ -1,66 +1,161 @@
-package event
-
-import (
-	"context"
-
-	go_core_observ "github.com/eliezerraj/go-core/observability"
-	go_core_event "github.com/eliezerraj/go-core/event/kafka"
-
-	"github.com/rs/zerolog/log"
-)
-
-var childLogger = log.With().Str("component","go-fund-transfer").Str("package","internal.adapter.event").Logger()
-
-var tracerProvider go_core_observ.TracerProvider
-var producerWorker go_core_event.ProducerWorker
-
-type WorkerEvent struct {
-	Topics	[]string
-	WorkerKafka *go_core_event.ProducerWorker 
-}
-
-// About create a worker producer kafka
-func NewWorkerEvent(ctx context.Context, topics []string, kafkaConfigurations *go_core_event.KafkaConfigurations) (*WorkerEvent, error) {
-	childLogger.Info().Str("func","NewWorkerEvent").Send()
-
-	//trace
-	span := tracerProvider.Span(ctx, "adapter.event.NewWorkerEvent")
-	defer span.End()
-
-	workerKafka, err := producerWorker.NewProducerWorker(kafkaConfigurations)
-	if err != nil {
-		childLogger.Error().Err(err).Send()
-		return nil, err
-	}
-
-	return &WorkerEvent{
-		Topics: topics,
-		WorkerKafka: workerKafka,
-	},nil
-}
-
-// About create a worker producer kafka with transaction
-func NewWorkerEventTX(ctx context.Context, topics []string, kafkaConfigurations *go_core_event.KafkaConfigurations) (*WorkerEvent, error) {
-	childLogger.Info().Str("func","NewWorkerEventTX").Send()
-
-	//trace
-	span := tracerProvider.Span(ctx, "adapter.event.NewWorkerEventTX")
-	defer span.End()
-
-	workerKafka, err := producerWorker.NewProducerWorkerTX(kafkaConfigurations)
-	if err != nil {
-		return nil, err
-	}
-
-	// Start Kafka InitTransactions
-	err = workerKafka.InitTransactions(ctx)
-	if err != nil {
-		childLogger.Error().Err(err).Send()
-		return nil, err
-	}	
-
-	return &WorkerEvent{
-		Topics: topics,
-		WorkerKafka: workerKafka,
-	},nil
-}
\ No newline at end of file
+package event
+
+import (
+	"fmt"
+	"errors"
+	"context"
+	"math/rand/v2"
+
+	"github.com/go-fund-transfer/internal/core/port"
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+	go_core_event "github.com/eliezerraj/go-core/event/kafka"
+
+	"github.com/rs/zerolog/log"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+var childLogger = log.With().Str("component","go-fund-transfer").Str("package","internal.adapter.event").Logger()
+
+var tracerProvider go_core_observ.TracerProvider
+
+var errNotTransactional = errors.New("the producer is not transactional")
+
+var _ port.EventPublisher = (*WorkerEvent)(nil)
+
+// About the kafka publisher. It holds its own confluent producer (the settings of the go-core one),
+// the go-core producer sends only the trace-request-id header
+type WorkerEvent struct {
+	producer		*kafka.Producer
+	transactional	bool
+}
+
+// About the settings of the producer, the ones of the go-core producer
+func producerConfig(kafkaConfigurations *go_core_event.KafkaConfigurations) *kafka.ConfigMap {
+	kafkaBrokerUrls := 	kafkaConfigurations.Brokers1 + "," + kafkaConfigurations.Brokers2 + "," + kafkaConfigurations.Brokers3
+
+	return &kafka.ConfigMap{	"bootstrap.servers":            kafkaBrokerUrls,
+								"security.protocol":            kafkaConfigurations.Protocol,
+								"sasl.mechanisms":              kafkaConfigurations.Mechanisms,
+								"sasl.username":                kafkaConfigurations.Username,
+								"sasl.password":                kafkaConfigurations.Password,
+								"acks": 						"all",
+								"message.timeout.ms":			5000,
+								"retries":						5,
+								"retry.backoff.ms":				500,
+								"enable.idempotence":			true,
+								}
+}
+
+// About create a worker producer kafka
+func NewWorkerEvent(ctx context.Context, kafkaConfigurations *go_core_event.KafkaConfigurations) (*WorkerEvent, error) {
+	childLogger.Info().Str("func","NewWorkerEvent").Send()
+
+	//trace
+	span := tracerProvider.Span(ctx, "adapter.event.NewWorkerEvent")
+	defer span.End()
+
+	producer, err := kafka.NewProducer(producerConfig(kafkaConfigurations))
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		return nil, err
+	}
+
+	return &WorkerEvent{
+		producer: producer,
+	},nil
+}
+
+// About create a worker producer kafka with transaction
+func NewWorkerEventTX(ctx context.Context, kafkaConfigurations *go_core_event.KafkaConfigurations) (*WorkerEvent, error) {
+	childLogger.Info().Str("func","NewWorkerEventTX").Send()
+
+	//trace
+	span := tracerProvider.Span(ctx, "adapter.event.NewWorkerEventTX")
+	defer span.End()
+
+	config := producerConfig(kafkaConfigurations)
+	config.SetKey("transactional.id", fmt.Sprintf("go-core-trx-%v", rand.IntN(1000)))
+
+	producer, err := kafka.NewProducer(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start Kafka InitTransactions
+	err = producer.InitTransactions(ctx)
+	if err != nil {
+		childLogger.Error().Err(err).Send()
+		return nil, err
+	}	
+
+	return &WorkerEvent{
+		producer: producer,
+		transactional: true,
+	},nil
+}
+
+// About begin a kafka transaction
+func (w *WorkerEvent) BeginTransaction(ctx context.Context) error {
+	if !w.transactional {
+		return errNotTransactional
+	}
+	return w.producer.BeginTransaction()
+}
+
+// About commit the kafka transaction
+func (w *WorkerEvent) CommitTransaction(ctx context.Context) error {
+	if !w.transactional {
+		return errNotTransactional
+	}
+	return w.producer.CommitTransaction(ctx)
+}
+
+// About abort the kafka transaction
+func (w *WorkerEvent) AbortTransaction(ctx context.Context) error {
+	if !w.transactional {
+		return errNotTransactional
+	}
+	return w.producer.AbortTransaction(ctx)
+}
+
+// About the message of an event, its headers are the event_type and the trace-request-id
+func newMessage(event_topic string, event_type string, key string, trace_id *string, payload []byte) *kafka.Message {
+	headers := []kafka.Header{ { Key: "event_type", Value: []byte(event_type) } }
+	if trace_id != nil {
+		headers = append(headers, kafka.Header{ Key: "trace-request-id", Value: []byte(*trace_id) })
+	}
+
+	return &kafka.Message{	TopicPartition: kafka.TopicPartition{	Topic: &event_topic,
+																	Partition: kafka.PartitionAny },
+							Key: []byte(key),
+							Value: payload,
+							Headers: headers }
+}
+
+// About publish an event into the topic of its type and wait for its delivery.
+// The headers are the event_type and the trace-request-id
+func (w *WorkerEvent) Producer(ctx context.Context, event_topic string, event_type string, key string, trace_id *string, payload []byte) error {
+	childLogger.Debug().Str("func","Producer").Str("topic", event_topic).Str("event_type", event_type).Str("key", key).Send()
+
+	deliveryChan := make(chan kafka.Event, 1)
+	err := w.producer.Produce(newMessage(event_topic, event_type, key, trace_id, payload), deliveryChan)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case e := <-deliveryChan:
+		m, ok := e.(*kafka.Message)
+		if !ok {
+			return fmt.Errorf("unexpected delivery event %v", e)
+		}
+		if m.TopicPartition.Error != nil {
+			childLogger.Error().Err(m.TopicPartition.Error).Str("topic", event_topic).Msg("delivery failed")
+			return m.TopicPartition.Error
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package event
+
+import (
+	"time"
+	"context"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// About the headers of a message by key
+func messageHeaders(message *kafka.Message) map[string]string {
+	headers := map[string]string{}
+	for _, header := range message.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	return headers
+}
+
+func TestNewMessage(t *testing.T) {
+	trace_id := "trace-1"
+
+	tests := []struct {
+		name		string
+		trace_id	*string
+		want		map[string]string
+	}{
+		{ name: "with trace", trace_id: &trace_id, want: map[string]string{ "event_type": "CREDIT", "trace-request-id": "trace-1" } },
+		{ name: "without trace", trace_id: nil, want: map[string]string{ "event_type": "CREDIT" } },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := newMessage("topic.credit", "CREDIT", "key-1", tt.trace_id, []byte(`{}`))
+
+			headers := messageHeaders(message)
+			if len(headers) != len(tt.want) {
+				t.Fatalf("headers = %v, want %v", headers, tt.want)
+			}
+			for key, value := range tt.want {
+				if headers[key] != value {
+					t.Errorf("header %s = %q, want %q", key, headers[key], value)
+				}
+			}
+			if *message.TopicPartition.Topic != "topic.credit" || string(message.Key) != "key-1" {
+				t.Errorf("message = %s %s, want topic.credit key-1", *message.TopicPartition.Topic, message.Key)
+			}
+		})
+	}
+}
+
+func TestProducerHeaders(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	if err != nil {
+		t.Fatalf("NewMockCluster: %v", err)
+	}
+	defer cluster.Close()
+
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{ "bootstrap.servers": cluster.BootstrapServers() })
+	if err != nil {
+		t.Fatalf("NewProducer: %v", err)
+	}
+	defer producer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Second)
+	defer cancel()
+
+	// the produced message carries the event type, read back from the topic
+	trace_id := "trace-1"
+	workerEvent := &WorkerEvent{ producer: producer }
+	err = workerEvent.Producer(ctx, "topic.debit", "DEBIT", "key-1", &trace_id, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("Producer: %v", err)
+	}
+
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{	"bootstrap.servers": cluster.BootstrapServers(),
+															"group.id": "test",
+															"auto.offset.reset": "earliest" })
+	if err != nil {
+		t.Fatalf("NewConsumer: %v", err)
+	}
+	defer consumer.Close()
+
+	err = consumer.Subscribe("topic.debit", nil)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	message, err := consumer.ReadMessage(10 * time.Second)
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+
+	headers := messageHeaders(message)
+	if headers["event_type"] != "DEBIT" || headers["trace-request-id"] != "trace-1" {
+		t.Errorf("headers = %v, want event_type DEBIT and trace-request-id trace-1", headers)
+	}
+}
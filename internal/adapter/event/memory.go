@@ -0,0 +1,147 @@
+package event
+
+import (
+	"sync"
+	"errors"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+var errTransactionOpen = errors.New("a transaction is already open")
+var errNoTransaction = errors.New("no transaction open")
+
+var _ port.EventPublisher = (*MemoryPublisher)(nil)
+
+// About a message recorded by the in memory publisher
+type MemoryMessage struct {
+	Topic		string
+	EventType	string
+	Key			string
+	TraceID		string
+	Payload		[]byte
+}
+
+// About a publisher that keeps the messages in memory (local run and tests without kafka).
+// The messages of a transaction are published on commit and recorded as aborted on abort,
+// outside a transaction they are published at once. Err forces a failure, KeyErr the failure of the messages of a key
+type MemoryPublisher struct {
+	mutex		sync.Mutex
+	Messages	[]MemoryMessage
+	aborted		[]MemoryMessage
+	pending		[]MemoryMessage
+	open		bool
+	Err			error
+	KeyErr		map[string]error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	childLogger.Info().Str("func","NewMemoryPublisher").Send()
+
+	return &MemoryPublisher{}
+}
+
+// About begin a transaction, like kafka only one is open at a time
+func (m *MemoryPublisher) BeginTransaction(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.open {
+		return errTransactionOpen
+	}
+	m.open = true
+	m.pending = nil
+
+	return nil
+}
+
+// About publish the messages of the transaction, Err forces a failure (the messages are then aborted)
+func (m *MemoryPublisher) CommitTransaction(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.open {
+		return errNoTransaction
+	}
+	if m.Err != nil {
+		m.aborted = append(m.aborted, m.pending...)
+		m.pending = nil
+		m.open = false
+		return m.Err
+	}
+	m.Messages = append(m.Messages, m.pending...)
+	m.pending = nil
+	m.open = false
+
+	return nil
+}
+
+// About drop the messages of the transaction, they are kept as aborted
+func (m *MemoryPublisher) AbortTransaction(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.open {
+		return errNoTransaction
+	}
+	m.aborted = append(m.aborted, m.pending...)
+	m.pending = nil
+	m.open = false
+
+	return nil
+}
+
+// About record a message, Err forces a failure
+func (m *MemoryPublisher) Producer(ctx context.Context, event_topic string, event_type string, key string, trace_id *string, payload []byte) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.Err != nil {
+		return m.Err
+	}
+	if m.KeyErr[key] != nil {
+		return m.KeyErr[key]
+	}
+
+	message := MemoryMessage{	Topic: event_topic,
+								EventType: event_type,
+								Key: key,
+								Payload: append([]byte(nil), payload...) }
+	if trace_id != nil {
+		message.TraceID = *trace_id
+	}
+	if m.open {
+		m.pending = append(m.pending, message)
+	} else {
+		m.Messages = append(m.Messages, message)
+	}
+
+	return nil
+}
+
+// About get a copy of the published messages
+func (m *MemoryPublisher) Published() []MemoryMessage {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]MemoryMessage{}, m.Messages...)
+}
+
+// About get a copy of the messages dropped by an aborted transaction
+func (m *MemoryPublisher) Aborted() []MemoryMessage {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]MemoryMessage{}, m.aborted...)
+}
+
+// About drop the recorded messages
+func (m *MemoryPublisher) Reset() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.Messages = nil
+	m.aborted = nil
+	m.pending = nil
+	m.open = false
+}
@@ -0,0 +1,95 @@
+package fx
+
+import (
+	"os"
+	"time"
+	"context"
+	"strings"
+	"math/big"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/rs/zerolog/log"
+)
+
+var childLogger = log.With().Str("component","go-fund-transfer").Str("package","internal.adapter.fx").Logger()
+
+// About the rates used when no file is given (local use only)
+var defaultRates = map[string]string{
+	"USD/BRL": "5.40",
+	"EUR/BRL": "5.85",
+	"GBP/BRL": "6.85",
+	"EUR/USD": "1.08",
+	"GBP/USD": "1.27",
+	"USD/MXN": "17.10",
+	"USD/JPY": "150.25",
+	"USD/CLP": "940.00",
+}
+
+// About a rate provider backed by a static table, a pair missing in a direction is resolved by its inverse
+type StaticRateProvider struct {
+	rates	map[string]string
+	rateAt	time.Time
+	source	string
+}
+
+// About create the provider from a json file ({"USD/BRL": "5.40", ...}), an empty path uses the default table
+func NewStaticRateProvider(path string) (*StaticRateProvider, error) {
+	childLogger.Info().Str("func","NewStaticRateProvider").Str("path", path).Send()
+
+	staticRateProvider := StaticRateProvider{	rates: defaultRates,
+												rateAt: time.Now(),
+												source: "static" }
+	if path == "" {
+		return &staticRateProvider, nil
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rates := map[string]string{}
+	err = json.Unmarshal(file, &rates)
+	if err != nil {
+		return nil, erro.ErrUnmarshal
+	}
+	for pair, rate := range rates {
+		if _, err := model.ParseRate(rate); err != nil {
+			childLogger.Error().Str("pair", pair).Str("rate", rate).Msg("invalid rate")
+			return nil, err
+		}
+	}
+
+	staticRateProvider.rates = rates
+	staticRateProvider.source = path
+	return &staticRateProvider, nil
+}
+
+// About get the rate of a currency pair
+func (p *StaticRateProvider) GetRate(ctx context.Context, currencyFrom string, currencyTo string) (*model.FxRate, error) {
+	currencyFrom = strings.ToUpper(currencyFrom)
+	currencyTo = strings.ToUpper(currencyTo)
+
+	fxRate := model.FxRate{	CurrencyFrom: currencyFrom,
+							CurrencyTo: currencyTo,
+							Source: p.source,
+							RateAt: p.rateAt }
+
+	if rate, ok := p.rates[currencyFrom + "/" + currencyTo]; ok {
+		fxRate.Rate = rate
+		return &fxRate, nil
+	}
+
+	if rate, ok := p.rates[currencyTo + "/" + currencyFrom]; ok {
+		rat, err := model.ParseRate(rate)
+		if err != nil {
+			return nil, err
+		}
+		fxRate.Rate = strings.TrimRight(strings.TrimRight(new(big.Rat).Inv(rat).FloatString(10), "0"), ".")
+		return &fxRate, nil
+	}
+
+	return nil, erro.ErrRateNotFound
+}
@@ -0,0 +1,154 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/rs/zerolog/log"
+)
+
+var childLogger = log.With().Str("component","go-fund-transfer").Str("package","internal.adapter.resilience").Logger()
+
+// About the outcome of a call let through by a breaker
+type Outcome int
+
+const (
+	// the dependency answered, even with an error of the caller (4xx)
+	OutcomeSuccess Outcome = iota
+	// a failure of the dependency (timeout, unavailable, 5xx)
+	OutcomeFailure
+	// the caller gave up on the call (context canceled or past its deadline), it tells nothing about the dependency
+	OutcomeCanceled
+)
+
+// About a circuit breaker (closed, open, half open) counting the consecutive failures of a dependency
+type CircuitBreaker struct {
+	mu					sync.Mutex
+	name				string
+	config				model.BreakerConfig
+	state				string
+	failures			int
+	openedAt			time.Time
+	halfOpenInFlight	int
+	halfOpenSuccesses	int
+	rejected			int64
+	now					func() time.Time
+}
+
+// About create a closed breaker, a config with no failures disables it
+func NewCircuitBreaker(name string, config model.BreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{	name: name,
+							config: config,
+							state: model.BreakerClosed,
+							now: time.Now }
+}
+
+// About let a call through or refuse it with erro.ErrCircuitOpen (retryable). The call must report its
+// outcome with the returned done
+func (b *CircuitBreaker) Allow() (func(outcome Outcome), error) {
+	if b.config.Failures <= 0 {
+		return func(Outcome) {}, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == model.BreakerOpen && !b.now().Before(b.halfOpenAt()) {
+		b.transition(model.BreakerHalfOpen)
+	}
+
+	switch b.state {
+	case model.BreakerOpen:
+		b.rejected = b.rejected + 1
+		return nil, erro.ErrCircuitOpen.WithDetail("retry_at", b.halfOpenAt())
+	case model.BreakerHalfOpen:
+		if b.halfOpenInFlight >= b.config.HalfOpenCalls {
+			b.rejected = b.rejected + 1
+			return nil, erro.ErrCircuitOpen
+		}
+		b.halfOpenInFlight = b.halfOpenInFlight + 1
+		return b.doneTrial, nil
+	default:
+		return b.done, nil
+	}
+}
+
+// About the outcome of a call let through by the closed breaker, a canceled call changes nothing
+func (b *CircuitBreaker) done(outcome Outcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// the breaker opened meanwhile, the outcome of an older call does not count
+	if b.state != model.BreakerClosed || outcome == OutcomeCanceled {
+		return
+	}
+	if outcome == OutcomeSuccess {
+		b.failures = 0
+		return
+	}
+	b.failures = b.failures + 1
+	if b.failures >= b.config.Failures {
+		b.transition(model.BreakerOpen)
+	}
+}
+
+// About the outcome of a trial call of the half open breaker, a canceled trial only frees its slot
+func (b *CircuitBreaker) doneTrial(outcome Outcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.halfOpenInFlight = b.halfOpenInFlight - 1
+	if b.state != model.BreakerHalfOpen || outcome == OutcomeCanceled {
+		return
+	}
+	if outcome == OutcomeFailure {
+		b.transition(model.BreakerOpen)
+		return
+	}
+	b.halfOpenSuccesses = b.halfOpenSuccesses + 1
+	if b.halfOpenSuccesses >= b.config.HalfOpenCalls {
+		b.transition(model.BreakerClosed)
+	}
+}
+
+// About move to a state (called with the lock held)
+func (b *CircuitBreaker) transition(state string) {
+	childLogger.Info().Str("breaker", b.name).Str("from", b.state).Str("to", state).Int("failures", b.failures).Msg("circuit breaker transition")
+
+	b.state = state
+	switch state {
+	case model.BreakerOpen:
+		b.openedAt = b.now()
+	case model.BreakerHalfOpen:
+		b.halfOpenSuccesses = 0
+	case model.BreakerClosed:
+		b.failures = 0
+	}
+}
+
+// About the time the open breaker lets the trial calls through (called with the lock held)
+func (b *CircuitBreaker) halfOpenAt() time.Time {
+	return b.openedAt.Add(time.Duration(b.config.OpenTimeout) * time.Millisecond)
+}
+
+// About the state of the breaker, an open one past its timeout is reported half open
+func (b *CircuitBreaker) Status(dependencyStatus *model.DependencyStatus) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dependencyStatus.State = b.state
+	dependencyStatus.Failures = b.failures
+	dependencyStatus.Rejected = dependencyStatus.Rejected + b.rejected
+	if b.state == model.BreakerOpen {
+		openedAt := b.openedAt
+		halfOpenAt := b.halfOpenAt()
+		dependencyStatus.OpenedAt = &openedAt
+		dependencyStatus.HalfOpenAt = &halfOpenAt
+		if !b.now().Before(halfOpenAt) {
+			dependencyStatus.State = model.BreakerHalfOpen
+		}
+	}
+}
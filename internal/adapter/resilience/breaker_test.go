@@ -0,0 +1,151 @@
+package resilience
+
+import (
+	"time"
+	"errors"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About a breaker on a clock moved by the test
+func newTestBreaker(config model.BreakerConfig) (*CircuitBreaker, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker("test", config)
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
+
+// About let a call through and report its outcome
+func call(t *testing.T, breaker *CircuitBreaker, outcome Outcome) {
+	t.Helper()
+
+	done, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	done(outcome)
+}
+
+// About the state reported by the breaker
+func state(breaker *CircuitBreaker) string {
+	dependencyStatus := model.DependencyStatus{}
+	breaker.Status(&dependencyStatus)
+	return dependencyStatus.State
+}
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	breaker, _ := newTestBreaker(model.BreakerConfig{ Failures: 3, OpenTimeout: 1000, HalfOpenCalls: 1 })
+
+	// a success resets the consecutive failures, a canceled call changes nothing
+	call(t, breaker, OutcomeFailure)
+	call(t, breaker, OutcomeFailure)
+	call(t, breaker, OutcomeSuccess)
+	call(t, breaker, OutcomeFailure)
+	call(t, breaker, OutcomeFailure)
+	call(t, breaker, OutcomeCanceled)
+	if state(breaker) != model.BreakerClosed || breaker.failures != 2 {
+		t.Fatalf("state = %s failures = %d, want %s and 2", state(breaker), breaker.failures, model.BreakerClosed)
+	}
+
+	call(t, breaker, OutcomeFailure)
+	if state(breaker) != model.BreakerOpen {
+		t.Fatalf("state = %s, want %s", state(breaker), model.BreakerOpen)
+	}
+
+	// the open breaker refuses the calls and counts them
+	_, err := breaker.Allow()
+	if !errors.Is(err, erro.ErrCircuitOpen) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrCircuitOpen.Code)
+	}
+	dependencyStatus := model.DependencyStatus{}
+	breaker.Status(&dependencyStatus)
+	if dependencyStatus.Rejected != 1 || dependencyStatus.OpenedAt == nil || dependencyStatus.HalfOpenAt == nil {
+		t.Errorf("status = %+v, want 1 rejected and the open times", dependencyStatus)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name	string
+		outcome	Outcome
+		state	string
+	}{
+		{ "trial succeeded", OutcomeSuccess, model.BreakerClosed },
+		{ "trial failed", OutcomeFailure, model.BreakerOpen },
+		{ "trial canceled", OutcomeCanceled, model.BreakerHalfOpen },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker, now := newTestBreaker(model.BreakerConfig{ Failures: 1, OpenTimeout: 1000, HalfOpenCalls: 1 })
+			call(t, breaker, OutcomeFailure)
+
+			// half open once the timeout passed, a single trial at a time
+			*now = now.Add(time.Second)
+			if state(breaker) != model.BreakerHalfOpen {
+				t.Fatalf("state = %s, want %s", state(breaker), model.BreakerHalfOpen)
+			}
+			done, err := breaker.Allow()
+			if err != nil {
+				t.Fatalf("Allow: %v", err)
+			}
+			_, err = breaker.Allow()
+			if !errors.Is(err, erro.ErrCircuitOpen) {
+				t.Fatalf("second trial err = %v, want %s", err, erro.ErrCircuitOpen.Code)
+			}
+
+			done(tt.outcome)
+			if state(breaker) != tt.state {
+				t.Fatalf("state = %s, want %s", state(breaker), tt.state)
+			}
+			if breaker.halfOpenInFlight != 0 {
+				t.Errorf("trials in flight = %d, want 0", breaker.halfOpenInFlight)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerCanceledTrial(t *testing.T) {
+	breaker, now := newTestBreaker(model.BreakerConfig{ Failures: 1, OpenTimeout: 1000, HalfOpenCalls: 2 })
+	call(t, breaker, OutcomeFailure)
+	*now = now.Add(time.Second)
+
+	// a canceled trial frees its slot, it does not count toward closing the breaker
+	call(t, breaker, OutcomeSuccess)
+	call(t, breaker, OutcomeCanceled)
+	if state(breaker) != model.BreakerHalfOpen {
+		t.Fatalf("state = %s, want %s", state(breaker), model.BreakerHalfOpen)
+	}
+	call(t, breaker, OutcomeSuccess)
+	if state(breaker) != model.BreakerClosed {
+		t.Fatalf("state = %s, want %s", state(breaker), model.BreakerClosed)
+	}
+}
+
+func TestCircuitBreakerStaleOutcome(t *testing.T) {
+	breaker, now := newTestBreaker(model.BreakerConfig{ Failures: 1, OpenTimeout: 1000, HalfOpenCalls: 1 })
+
+	// a call let through by the closed breaker ends after it opened, its outcome does not count
+	done, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	call(t, breaker, OutcomeFailure)
+	*now = now.Add(time.Second)
+	done(OutcomeSuccess)
+	if state(breaker) != model.BreakerHalfOpen {
+		t.Errorf("state = %s, want %s", state(breaker), model.BreakerHalfOpen)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	breaker, _ := newTestBreaker(model.BreakerConfig{})
+	for i := 0; i < 10; i++ {
+		call(t, breaker, OutcomeFailure)
+	}
+	if state(breaker) != model.BreakerClosed {
+		t.Errorf("state = %s, want %s", state(breaker), model.BreakerClosed)
+	}
+}
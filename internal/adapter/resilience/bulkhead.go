@@ -0,0 +1,67 @@
+package resilience
+
+import (
+	"time"
+	"context"
+	"sync/atomic"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About a bulkhead, it bounds the concurrent calls to a dependency so a slow one can not hold
+// all the http workers and database transactions of the service
+type Bulkhead struct {
+	slots		chan struct{}
+	wait		time.Duration
+	rejected	atomic.Int64
+}
+
+// About create a bulkhead of maxConcurrent calls (0 is unlimited), a call waits up to wait for a slot
+func NewBulkhead(maxConcurrent int, wait time.Duration) *Bulkhead {
+	bulkhead := Bulkhead{ wait: wait }
+	if maxConcurrent > 0 {
+		bulkhead.slots = make(chan struct{}, maxConcurrent)
+	}
+	return &bulkhead
+}
+
+// About take a slot or refuse the call with erro.ErrBulkheadFull (retryable), release frees the slot
+func (b *Bulkhead) Acquire(ctx context.Context) (func(), error) {
+	if b.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return b.release, nil
+	default:
+	}
+
+	if b.wait > 0 {
+		timer := time.NewTimer(b.wait)
+		defer timer.Stop()
+
+		select {
+		case b.slots <- struct{}{}:
+			return b.release, nil
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	b.rejected.Add(1)
+	return nil, erro.ErrBulkheadFull.WithDetail("max_concurrent", cap(b.slots))
+}
+
+func (b *Bulkhead) release() {
+	<-b.slots
+}
+
+// About the calls in flight and the limit of the bulkhead
+func (b *Bulkhead) Status(dependencyStatus *model.DependencyStatus) {
+	dependencyStatus.InFlight = len(b.slots)
+	dependencyStatus.MaxConcurrent = cap(b.slots)
+	dependencyStatus.Rejected = dependencyStatus.Rejected + b.rejected.Load()
+}
@@ -0,0 +1,85 @@
+package resilience
+
+import (
+	"time"
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+func TestBulkhead(t *testing.T) {
+	bulkhead := NewBulkhead(2, 0)
+	ctx := context.Background()
+
+	release_1, err := bulkhead.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	_, err = bulkhead.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	// full, a bulkhead with no wait refuses at once
+	_, err = bulkhead.Acquire(ctx)
+	if !errors.Is(err, erro.ErrBulkheadFull) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrBulkheadFull.Code)
+	}
+	dependencyStatus := model.DependencyStatus{}
+	bulkhead.Status(&dependencyStatus)
+	if dependencyStatus.InFlight != 2 || dependencyStatus.MaxConcurrent != 2 || dependencyStatus.Rejected != 1 {
+		t.Errorf("status = %+v, want 2 in flight of 2 and 1 rejected", dependencyStatus)
+	}
+
+	// a released slot is taken again
+	release_1()
+	_, err = bulkhead.Acquire(ctx)
+	if err != nil {
+		t.Errorf("Acquire after release: %v", err)
+	}
+}
+
+func TestBulkheadWait(t *testing.T) {
+	bulkhead := NewBulkhead(1, 50 * time.Millisecond)
+	release, err := bulkhead.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	// refused after the wait
+	_, err = bulkhead.Acquire(context.Background())
+	if !errors.Is(err, erro.ErrBulkheadFull) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrBulkheadFull.Code)
+	}
+
+	// the caller gives up while waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = bulkhead.Acquire(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want %v", err, context.Canceled)
+	}
+
+	// a slot released during the wait is taken
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	_, err = bulkhead.Acquire(context.Background())
+	if err != nil {
+		t.Errorf("Acquire during the wait: %v", err)
+	}
+}
+
+func TestBulkheadUnlimited(t *testing.T) {
+	bulkhead := NewBulkhead(0, 0)
+	for i := 0; i < 100; i++ {
+		_, err := bulkhead.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package resilience
+
+import (
+	"time"
+	"errors"
+	"context"
+	"math/rand/v2"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About the retry policy of a dependency, see model.RetryConfig
+type RetryPolicy struct {
+	config	model.RetryConfig
+	status	map[int]bool
+}
+
+// About create the retry policy, a config with no max never retries
+func NewRetryPolicy(config model.RetryConfig) *RetryPolicy {
+	retryPolicy := RetryPolicy{ config: config,
+								status: map[int]bool{} }
+	for _, status := range config.Status {
+		retryPolicy.status[status] = true
+	}
+	return &retryPolicy
+}
+
+// About the retries allowed after the first attempt
+func (r *RetryPolicy) Max() int {
+	return r.config.Max
+}
+
+// About a transient failure: a timeout, an unavailable service or an answer with a retryable status
+// (the "upstream_status" detail of erro.ErrServer). An open breaker or a full bulkhead is not retried,
+// trying again only adds load
+func (r *RetryPolicy) Retryable(err error) bool {
+	if errors.Is(err, erro.ErrUpstreamTimeout) || errors.Is(err, erro.ErrUpstreamUnavailable) {
+		return true
+	}
+	if !errors.Is(err, erro.ErrServer) {
+		return false
+	}
+	status, ok := erro.AsDomain(err).Details["upstream_status"].(int)
+	return ok && r.status[status]
+}
+
+// About the wait before the retry n (0 is the first retry), full jitter over the capped exponential backoff
+func (r *RetryPolicy) Backoff(retry int) time.Duration {
+	backoff := time.Duration(r.config.Backoff) * time.Millisecond
+	backoffMax := time.Duration(r.config.BackoffMax) * time.Millisecond
+	for i := 0; i < retry && backoff < backoffMax; i++ {
+		backoff = backoff * 2
+	}
+	if backoff > backoffMax {
+		backoff = backoffMax
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return rand.N(backoff + 1)
+}
+
+// About wait the backoff of the retry n, false when the deadline of the context would pass first
+func (r *RetryPolicy) Wait(ctx context.Context, retry int) bool {
+	backoff := r.Backoff(retry)
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= backoff {
+		return false
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
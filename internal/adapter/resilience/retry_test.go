@@ -0,0 +1,80 @@
+package resilience
+
+import (
+	"time"
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	retryPolicy := NewRetryPolicy(model.RetryConfig{ Max: 2, Backoff: 10, BackoffMax: 100, Status: []int{ 502, 503 } })
+
+	tests := []struct {
+		name		string
+		err			error
+		retryable	bool
+	}{
+		{ "timeout", erro.ErrUpstreamTimeout, true },
+		{ "unavailable", erro.ErrUpstreamUnavailable, true },
+		{ "retryable status", erro.ErrServer.WithDetail("upstream_status", 503), true },
+		{ "other status", erro.ErrServer.WithDetail("upstream_status", 500), false },
+		{ "server without status", erro.ErrServer, false },
+		{ "circuit open", erro.ErrCircuitOpen, false },
+		{ "bulkhead full", erro.ErrBulkheadFull, false },
+		{ "not a domain error", errors.New("boom"), false },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryPolicy.Retryable(tt.err); got != tt.retryable {
+				t.Errorf("Retryable = %v, want %v", got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	retryPolicy := NewRetryPolicy(model.RetryConfig{ Max: 5, Backoff: 10, BackoffMax: 40 })
+
+	// full jitter between 0 and the doubled backoff, capped
+	for retry, max := range []time.Duration{ 10, 20, 40, 40, 40 } {
+		for i := 0; i < 100; i++ {
+			backoff := retryPolicy.Backoff(retry)
+			if backoff < 0 || backoff > max * time.Millisecond {
+				t.Fatalf("backoff %d = %s, want between 0 and %s", retry, backoff, max * time.Millisecond)
+			}
+		}
+	}
+
+	if backoff := NewRetryPolicy(model.RetryConfig{}).Backoff(3); backoff != 0 {
+		t.Errorf("backoff without config = %s, want 0", backoff)
+	}
+}
+
+func TestRetryPolicyWait(t *testing.T) {
+	retryPolicy := NewRetryPolicy(model.RetryConfig{ Max: 1, Backoff: 5, BackoffMax: 5 })
+
+	if !retryPolicy.Wait(context.Background(), 0) {
+		t.Errorf("Wait = false, want true")
+	}
+
+	// the wait does not outlive the deadline of the caller
+	slowPolicy := NewRetryPolicy(model.RetryConfig{ Max: 1, Backoff: 60000, BackoffMax: 60000 })
+	ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	slowPolicy.Wait(ctx, 0)
+	if time.Since(started) > time.Second {
+		t.Errorf("Wait took %s, want to end by the deadline", time.Since(started))
+	}
+
+	ctx_canceled, cancel_canceled := context.WithCancel(context.Background())
+	cancel_canceled()
+	if slowPolicy.Wait(ctx_canceled, 0) {
+		t.Errorf("Wait on a canceled context = true, want false")
+	}
+}
@@ -0,0 +1,42 @@
+package risk
+
+import (
+	"os"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+
+	"github.com/rs/zerolog/log"
+)
+
+var childLogger = log.With().Str("component","go-fund-transfer").Str("package","internal.adapter.risk").Logger()
+
+// About load the risk rules from a json file ([{"name": "...", "type": "VELOCITY", ...}]), an empty path means no rules
+func LoadRuleFile(path string) ([]model.RiskRule, error) {
+	childLogger.Info().Str("func","LoadRuleFile").Str("path", path).Send()
+
+	if path == "" {
+		return []model.RiskRule{}, nil
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules := []model.RiskRule{}
+	err = json.Unmarshal(file, &rules)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("invalid rule file")
+		return nil, erro.ErrUnmarshal
+	}
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			childLogger.Error().Str("rule", rules[i].Name).Str("type", rules[i].Type).Msg("invalid rule")
+			return nil, err
+		}
+	}
+
+	childLogger.Info().Int("rules", len(rules)).Msg("risk rules loaded")
+	return rules, nil
+}
@@ -0,0 +1,79 @@
+package erro
+
+import (
+	"errors"
+)
+
+// About the code of an error that is not a domain error
+const CodeInternal = "INTERNAL_ERROR"
+
+// About a domain error: a stable code for the clients, the http status, if a retry can succeed and the details.
+// It wraps its cause, errors.Is matches the domain errors of the same code (the sentinels of this package)
+type DomainError struct {
+	Code		string
+	Status		int
+	Retryable	bool
+	Message		string
+	Details		map[string]interface{}
+	Cause		error
+}
+
+// About create a domain error
+func New(code string, status int, retryable bool, message string) *DomainError {
+	return &DomainError{	Code: code,
+							Status: status,
+							Retryable: retryable,
+							Message: message }
+}
+
+func (e *DomainError) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Cause
+}
+
+// About match the domain errors of the same code
+func (e *DomainError) Is(target error) bool {
+	t, ok := target.(*DomainError)
+	return ok && t.Code == e.Code
+}
+
+// About a copy of the error with its cause
+func (e *DomainError) Wrap(cause error) *DomainError {
+	c := *e
+	c.Cause = cause
+	return &c
+}
+
+// About a copy of the error with one more detail
+func (e *DomainError) WithDetail(key string, value interface{}) *DomainError {
+	c := *e
+	c.Details = make(map[string]interface{}, len(e.Details) + 1)
+	for k, v := range e.Details {
+		c.Details[k] = v
+	}
+	c.Details[key] = value
+	return &c
+}
+
+// About the domain error of an error chain, nil if there is none
+func AsDomain(err error) *DomainError {
+	var domainError *DomainError
+	if errors.As(err, &domainError) {
+		return domainError
+	}
+	return nil
+}
+
+// About the code of an error, INTERNAL_ERROR if it is not a domain error
+func CodeOf(err error) string {
+	if domainError := AsDomain(err); domainError != nil {
+		return domainError.Code
+	}
+	return CodeInternal
+}
@@ -1,21 +1,40 @@
-package erro
-
-import (
-	"errors"
-)
-
-var (
-	ErrNotFound 		= errors.New("item not found")
-	ErrInsert 			= errors.New("insert data error")
-	ErrUpdate			= errors.New("update unsuccessful")
-	ErrUpdateRows		= errors.New("update affect 0 rows")
-	ErrDelete 			= errors.New("delete data error")
-	ErrUnmarshal 		= errors.New("unmarshal json error")
-	ErrUnauthorized 	= errors.New("not authorized")
-	ErrServer		 	= errors.New("server identified error")
-	ErrHTTPForbiden		= errors.New("forbiden request")
-	ErrInvalid			= errors.New("invalid data")
-	ErrTransInvalid		= errors.New("transaction invalid")
-	ErrAmountInvalid	= errors.New("amount invalid")
-	ErrCurrencyInvalid	= errors.New("currency invalid")
-)
\ No newline at end of file
+package erro
+
+import (
+	"net/http"
+)
+
+var (
+	ErrNotFound 		= New("NOT_FOUND", http.StatusNotFound, false, "item not found")
+	ErrInsert 			= New("INSERT_FAILED", http.StatusInternalServerError, false, "insert data error")
+	ErrUpdate			= New("UPDATE_FAILED", http.StatusInternalServerError, false, "update unsuccessful")
+	ErrUpdateRows		= New("UPDATE_NO_ROWS", http.StatusConflict, false, "update affect 0 rows")
+	ErrDelete 			= New("DELETE_FAILED", http.StatusInternalServerError, false, "delete data error")
+	ErrUnmarshal 		= New("UNMARSHAL_FAILED", http.StatusInternalServerError, false, "unmarshal json error")
+	ErrUnauthorized 	= New("UNAUTHORIZED", http.StatusUnauthorized, false, "not authorized")
+	ErrServer		 	= New("UPSTREAM_ERROR", http.StatusBadGateway, true, "server identified error")
+	ErrHTTPForbiden		= New("FORBIDDEN", http.StatusForbidden, false, "forbiden request")
+	ErrInvalid			= New("INVALID_REQUEST", http.StatusBadRequest, false, "invalid data")
+	ErrTransInvalid		= New("TRANSACTION_INVALID", http.StatusConflict, false, "transaction invalid")
+	ErrAmountInvalid	= New("AMOUNT_INVALID", http.StatusConflict, false, "amount invalid")
+	ErrCurrencyInvalid	= New("CURRENCY_INVALID", http.StatusConflict, false, "currency invalid")
+	ErrStatusInvalid	= New("STATUS_INVALID", http.StatusBadRequest, false, "status invalid")
+	ErrStatusTransition	= New("STATUS_TRANSITION", http.StatusConflict, false, "status transition not allowed")
+	ErrIdempotencyKey	= New("IDEMPOTENCY_KEY", http.StatusUnprocessableEntity, false, "idempotency key already used with a different request")
+	ErrBatchInvalid		= New("BATCH_INVALID", http.StatusUnprocessableEntity, false, "batch has invalid items")
+	ErrRateNotFound		= New("FX_RATE_NOT_FOUND", http.StatusUnprocessableEntity, false, "fx rate not found")
+	ErrQuoteInvalid		= New("QUOTE_INVALID", http.StatusUnprocessableEntity, false, "quote invalid for this transfer")
+	ErrQuoteExpired		= New("QUOTE_EXPIRED", http.StatusUnprocessableEntity, false, "quote expired")
+	ErrLimitExceeded	= New("LIMIT_EXCEEDED", http.StatusUnprocessableEntity, false, "transfer limit exceeded")
+	ErrRiskDenied		= New("RISK_DENIED", http.StatusUnprocessableEntity, false, "transfer denied by risk rules")
+	ErrReviewExpired	= New("REVIEW_EXPIRED", http.StatusConflict, false, "review expired")
+	ErrSelfApproval		= New("SELF_APPROVAL", http.StatusForbidden, false, "a transfer can not be approved by the user who submitted it")
+	ErrAccountNotFound	= New("ACCOUNT_NOT_FOUND", http.StatusNotFound, false, "account not found")
+	ErrUpstreamTimeout	= New("UPSTREAM_TIMEOUT", http.StatusGatewayTimeout, true, "upstream service timeout")
+	ErrUpstreamUnavailable	= New("UPSTREAM_UNAVAILABLE", http.StatusServiceUnavailable, true, "upstream service unavailable")
+	ErrUpstreamContract	= New("UPSTREAM_CONTRACT", http.StatusBadGateway, false, "upstream service answered outside its contract")
+	ErrCircuitOpen		= New("CIRCUIT_OPEN", http.StatusServiceUnavailable, true, "upstream service circuit open")
+	ErrEventTooEarly	= New("EVENT_TOO_EARLY", http.StatusConflict, true, "transfer of the event not found yet")
+	ErrBulkheadFull		= New("BULKHEAD_FULL", http.StatusServiceUnavailable, true, "too many concurrent calls to the upstream service")
+	ErrStatementRejected	= New("STATEMENT_REJECTED", http.StatusUnprocessableEntity, false, "statement rejected by the upstream service")
+)
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	ApprovalPending		= "PENDING"
+	ApprovalApproved	= "APPROVED"
+	ApprovalRejected	= "REJECTED"
+)
+
+// About a transfer above the approval threshold (AWAITING_APPROVAL), submitted by a maker and decided by a checker,
+// a different user with the approver role. An approval resumes the flow it came from (REST saga or event)
+type TransferApproval struct {
+	ID				int			`json:"id,omitempty"`
+	FkTransferID	int			`json:"fk_transfer_id,omitempty"`
+	TransactionID	string		`json:"transaction_id,omitempty"`
+	Flow			string		`json:"flow,omitempty"`
+	Transfer		*Transfer	`json:"transfer,omitempty"`
+	Maker			string		`json:"maker,omitempty"`
+	Status			string		`json:"status,omitempty"`
+	Checker			string		`json:"checker,omitempty"`
+	CheckerRoles	[]string	`json:"-"`
+	Reason			string		`json:"reason,omitempty"`
+	DecidedAt		*time.Time	`json:"decided_at,omitempty"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+}
+
+type ApprovalConfig struct {
+	Role	string	`json:"role"`
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	BatchProcessing	= "PROCESSING"
+	BatchDone		= "DONE"
+	BatchPartial	= "PARTIAL"
+	BatchFailed		= "FAILED"
+	BatchRejected	= "REJECTED"
+
+	BatchItemPending	= "PENDING"
+	BatchItemDone		= "DONE"
+	BatchItemHeld		= "HELD"
+	BatchItemFailed		= "FAILED"
+	BatchItemInvalid	= "INVALID"
+)
+
+// About a batch of transfers (ex: payroll), each item runs as an /add/transferEvent
+type TransferBatch struct {
+	ID				int			`json:"id,omitempty"`
+	Status			string		`json:"status,omitempty"`
+	Total			int			`json:"total"`
+	Succeeded		int			`json:"succeeded"`
+	Held			int			`json:"held"`
+	Failed			int			`json:"failed"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+	FinishedAt		*time.Time	`json:"finished_at,omitempty"`
+	Transfers		[]*Transfer	`json:"transfers,omitempty"`
+	Items			[]TransferBatchItem	`json:"items,omitempty"`
+}
+
+// About the result of an item of a batch, HELD is an item stored as PENDING_REVIEW or AWAITING_APPROVAL
+type TransferBatchItem struct {
+	ID				int			`json:"id,omitempty"`
+	FkBatchID		int			`json:"fk_batch_id,omitempty"`
+	Sequence		int			`json:"sequence"`
+	Transfer		*Transfer	`json:"transfer,omitempty"`
+	FkTransferID	*int		`json:"fk_transfer_id,omitempty"`
+	TransactionID	*string		`json:"transaction_id,omitempty"`
+	Status			string		`json:"status,omitempty"`
+	ErrorCode		string		`json:"error_code,omitempty"`
+	ErrorMessage	string		`json:"error_message,omitempty"`
+}
+
+type BatchConfig struct {
+	Concurrency		int	`json:"concurrency"`
+	MaxItems		int	`json:"max_items"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+)
+
+// About the states of a circuit breaker
+const (
+	BreakerClosed	= "CLOSED"
+	BreakerOpen		= "OPEN"
+	BreakerHalfOpen	= "HALF_OPEN"
+)
+
+// About the circuit breaker of a dependency. Failures consecutive failures open it (0 disables it),
+// after OpenTimeout (ms) HalfOpenCalls trial calls are let through, it closes when all of them succeed
+type BreakerConfig struct {
+	Failures		int		`json:"failures"`
+	OpenTimeout		int		`json:"open_timeout_ms"`
+	HalfOpenCalls	int		`json:"half_open_calls"`
+}
+
+// About the retries of a call failing with a transient error: a timeout, a connection refused or reset,
+// or an answer with one of the Status codes. The wait before the retry n is a random duration between 0
+// and Backoff * 2^n (ms), capped at BackoffMax (full jitter)
+type RetryConfig struct {
+	Max				int		`json:"max"`
+	Backoff			int		`json:"backoff_ms"`
+	BackoffMax		int		`json:"backoff_max_ms"`
+	Status			[]int	`json:"status"`
+}
+
+// About the state of the breaker and of the bulkhead of a dependency
+type DependencyStatus struct {
+	Name			string		`json:"name"`
+	Service			string		`json:"service"`
+	State			string		`json:"state"`
+	Failures		int			`json:"consecutive_failures"`
+	OpenedAt		*time.Time	`json:"opened_at,omitempty"`
+	HalfOpenAt		*time.Time	`json:"half_open_at,omitempty"`
+	InFlight		int			`json:"in_flight"`
+	MaxConcurrent	int			`json:"max_concurrent"`
+	Rejected		int64		`json:"rejected"`
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"net/url"
+	"net/http"
+)
+
+// About the logical names of the endpoints
+const (
+	EndpointAccount	= "account"
+	EndpointDebit	= "debit"
+	EndpointCredit	= "credit"
+)
+
+// About the default timeout of a call (ms), the one of the go-core http client
+const DefaultEndpointTimeout = 29000
+
+// About the defaults of the breaker and of the bulkhead of an endpoint
+const (
+	DefaultBreakerFailures		= 5
+	DefaultBreakerOpenTimeout	= 30000
+	DefaultBreakerHalfOpenCalls	= 1
+	DefaultMaxConcurrent		= 20
+)
+
+// About the defaults of the retries of an endpoint
+const (
+	DefaultRetryMax			= 2
+	DefaultRetryBackoff		= 100
+	DefaultRetryBackoffMax	= 2000
+)
+
+// About the answers retried by default, too many requests and the transient 5xx
+var DefaultRetryStatus = []int{ http.StatusTooManyRequests,
+								http.StatusInternalServerError,
+								http.StatusBadGateway,
+								http.StatusServiceUnavailable,
+								http.StatusGatewayTimeout }
+
+// About an endpoint of a dependency, keyed by its logical name (ENDPOINT_<NAME>_*)
+type ApiService struct {
+	Name			string `json:"name_service"`
+	Url				string `json:"url"`
+	Method			string `json:"method"`
+	Header_x_apigw_api_id	string `json:"x-apigw-api-id"`
+	Headers			map[string]string	`json:"headers,omitempty"`
+	Timeout			int		`json:"timeout_ms"`
+	Idempotent		bool	`json:"idempotent"`
+	Retry			RetryConfig		`json:"retry"`
+	Breaker			BreakerConfig	`json:"breaker"`
+	MaxConcurrent	int		`json:"max_concurrent"`
+	BulkheadWait	int		`json:"bulkhead_wait_ms"`
+}
+
+// About check the settings of an endpoint
+func (a ApiService) Validate() error {
+	if a.Url == "" {
+		return fmt.Errorf("endpoint %s: url missing", a.Name)
+	}
+	target, err := url.Parse(a.Url)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("endpoint %s: url %q is not an absolute http(s) url", a.Name, a.Url)
+	}
+	switch a.Method {
+	case "", http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return fmt.Errorf("endpoint %s: method %q invalid", a.Name, a.Method)
+	}
+	if a.Timeout <= 0 {
+		return fmt.Errorf("endpoint %s: timeout must be a positive number of ms", a.Name)
+	}
+	if a.Retry.Max < 0 {
+		return fmt.Errorf("endpoint %s: retry max must be zero or positive", a.Name)
+	}
+	if a.Retry.Backoff < 0 || a.Retry.BackoffMax < a.Retry.Backoff {
+		return fmt.Errorf("endpoint %s: retry backoff must be zero or positive and not above the backoff max", a.Name)
+	}
+	for _, status := range a.Retry.Status {
+		if status < 400 || status > 599 {
+			return fmt.Errorf("endpoint %s: retry status %d is not an error status", a.Name, status)
+		}
+	}
+	if a.Breaker.Failures < 0 {
+		return fmt.Errorf("endpoint %s: breaker failures must be zero (disabled) or positive", a.Name)
+	}
+	if a.Breaker.Failures > 0 && (a.Breaker.OpenTimeout <= 0 || a.Breaker.HalfOpenCalls <= 0) {
+		return fmt.Errorf("endpoint %s: breaker open timeout and half open calls must be positive", a.Name)
+	}
+	if a.MaxConcurrent < 0 || a.BulkheadWait < 0 {
+		return fmt.Errorf("endpoint %s: max concurrent and bulkhead wait must be zero (unlimited, no wait) or positive", a.Name)
+	}
+	for key, value := range a.Headers {
+		if !isHeaderName(key) || value == "" {
+			return fmt.Errorf("endpoint %s: header %q invalid (Name:Value)", a.Name, key)
+		}
+	}
+	return nil
+}
+
+// About a header name, letters, digits and dashes
+func isHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	return strings.IndexFunc(name, func(r rune) bool {
+		return !(r == '-' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'))
+	}) < 0
+}
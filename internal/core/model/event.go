@@ -0,0 +1,166 @@
+package model
+
+import (
+	"fmt"
+	"time"
+	"strings"
+)
+
+// About the types of the events published by the service, sent in the event_type header.
+// A new type is added here and routed by TOPIC_<TYPE>
+const (
+	EventTypeCredit		= "CREDIT"
+	EventTypeDebit		= "DEBIT"
+	EventTypeTransfer	= "TRANSFER"
+)
+
+// About the event types the service publishes, each one must be routed to a topic
+var PublishedEventTypes = []string{ EventTypeCredit, EventTypeDebit, EventTypeTransfer }
+
+// About the topic of each published event type
+type EventRouting map[string]string
+
+// About get the topic of an event type
+func (e EventRouting) Topic(eventType string) (string, error) {
+	topic, ok := e[eventType]
+	if !ok || topic == "" {
+		return "", fmt.Errorf("event type %s has no topic", eventType)
+	}
+	return topic, nil
+}
+
+// About check that every published event type has a valid topic and that no unknown type is routed
+func (e EventRouting) Validate() error {
+	for _, eventType := range PublishedEventTypes {
+		topic, err := e.Topic(eventType)
+		if err != nil {
+			return fmt.Errorf("%w (TOPIC_%s)", err, eventType)
+		}
+		if !isTopicName(topic) {
+			return fmt.Errorf("event type %s: topic %q invalid", eventType, topic)
+		}
+	}
+	for eventType := range e {
+		if !isPublishedEventType(eventType) {
+			return fmt.Errorf("event type %s unknown", eventType)
+		}
+	}
+	return nil
+}
+
+func isPublishedEventType(eventType string) bool {
+	for _, published := range PublishedEventTypes {
+		if published == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// About a kafka topic name: up to 249 letters, digits, dots, underscores or dashes
+func isTopicName(topic string) bool {
+	if topic == "" || topic == "." || topic == ".." || len(topic) > 249 {
+		return false
+	}
+	return strings.IndexFunc(topic, func(r rune) bool {
+		return !(r == '.' || r == '_' || r == '-' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'))
+	}) < 0
+}
+
+const (
+	EventCreditDone			= "CREDIT_DONE"
+	EventDebitDone			= "DEBIT_DONE"
+	EventTransferDone		= "TRANSFER_DONE"
+	EventTransferFailed		= "TRANSFER_FAILED"
+)
+
+// About the status applied by each completion event
+var eventTypeStatus = map[string]TransferStatus{
+	EventCreditDone:		StatusCreditDone,
+	EventDebitDone:			StatusDebitDone,
+	EventTransferDone:		StatusTransferDone,
+	EventTransferFailed:	StatusTransferFailed,
+}
+
+// About a completion or failure event published by the downstream workers (go-worker-credit, go-worker-debit ...)
+type TransferEvent struct {
+	EventType		string		`json:"event_type"`
+	TransactionID	string		`json:"transaction_id"`
+	Reason			string		`json:"reason,omitempty"`
+	EventAt			time.Time	`json:"event_at,omitempty"`
+}
+
+// About get the status applied by an event type
+func StatusFromEventType(eventType string) (TransferStatus, bool) {
+	status, ok := eventTypeStatus[eventType]
+	return status, ok
+}
+
+type ConsumerConfig struct {
+	Enabled				bool		`json:"enabled"`
+	GroupID				string		`json:"group_id"`
+	Topics				[]string	`json:"topics"`
+	DeadLetterTopic		string		`json:"dead_letter_topic"`
+	MaxAttempts			int			`json:"max_attempts"`
+	RetryBackoff		int			`json:"retry_backoff_ms"`
+	RetryBackoffMax		int			`json:"retry_backoff_max_ms"`
+	MaxPollInterval		int			`json:"max_poll_interval_ms"`
+}
+
+// About the wait before each retry of an event (ms), doubled from RetryBackoff up to RetryBackoffMax
+func (c ConsumerConfig) Backoff(attempt int) time.Duration {
+	backoff := c.RetryBackoff
+	for i := 1; i < attempt && backoff < c.RetryBackoffMax; i++ {
+		backoff = backoff * 2
+	}
+	if backoff > c.RetryBackoffMax {
+		backoff = c.RetryBackoffMax
+	}
+	return time.Duration(backoff) * time.Millisecond
+}
+
+// About the total wait of the retries of an event, the consumer does not poll while it waits
+func (c ConsumerConfig) RetryWindow() time.Duration {
+	var window time.Duration
+	for attempt := 1; attempt < c.MaxAttempts; attempt++ {
+		window = window + c.Backoff(attempt)
+	}
+	return window
+}
+
+// About check the consumer settings. The retries of an event must end well before max.poll.interval.ms
+// (half of it, the other half is left to the handler calls), otherwise the consumer leaves the group
+func (c ConsumerConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.GroupID == "" {
+		return fmt.Errorf("consumer: group id is required")
+	}
+	for _, topic := range c.Topics {
+		if !isTopicName(topic) {
+			return fmt.Errorf("consumer: topic %q invalid", topic)
+		}
+	}
+	if !isTopicName(c.DeadLetterTopic) {
+		return fmt.Errorf("consumer: dead letter topic %q invalid (TOPIC_COMPLETION_DEAD_LETTER)", c.DeadLetterTopic)
+	}
+	for _, topic := range c.Topics {
+		if topic == c.DeadLetterTopic {
+			return fmt.Errorf("consumer: dead letter topic %s is consumed", topic)
+		}
+	}
+	if c.MaxAttempts <= 0 {
+		return fmt.Errorf("consumer: max attempts must be positive")
+	}
+	if c.RetryBackoff <= 0 || c.RetryBackoffMax < c.RetryBackoff {
+		return fmt.Errorf("consumer: retry backoff must be positive and not above the max backoff")
+	}
+	if c.MaxPollInterval <= 0 {
+		return fmt.Errorf("consumer: max poll interval must be a positive number of ms")
+	}
+	if c.RetryWindow() * 2 > time.Duration(c.MaxPollInterval) * time.Millisecond {
+		return fmt.Errorf("consumer: the retries of an event (%s) exceed half of the max poll interval (%d ms)", c.RetryWindow(), c.MaxPollInterval)
+	}
+	return nil
+}
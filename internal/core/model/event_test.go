@@ -0,0 +1,86 @@
+package model
+
+import (
+	"time"
+	"testing"
+)
+
+func TestEventRoutingValidate(t *testing.T) {
+	tests := []struct {
+		name		string
+		routing		EventRouting
+		valid		bool
+	}{
+		{ "all types routed", EventRouting{ EventTypeCredit: "topic.credit", EventTypeDebit: "topic.debit", EventTypeTransfer: "topic.transfer" }, true },
+		{ "type missing", EventRouting{ EventTypeCredit: "topic.credit", EventTypeDebit: "topic.debit" }, false },
+		{ "empty topic", EventRouting{ EventTypeCredit: "", EventTypeDebit: "topic.debit", EventTypeTransfer: "topic.transfer" }, false },
+		{ "invalid topic", EventRouting{ EventTypeCredit: "topic credit", EventTypeDebit: "topic.debit", EventTypeTransfer: "topic.transfer" }, false },
+		{ "unknown type", EventRouting{ EventTypeCredit: "topic.credit", EventTypeDebit: "topic.debit", EventTypeTransfer: "topic.transfer", "REFUND": "topic.refund" }, false },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.routing.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("Validate = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestConsumerConfigValidate(t *testing.T) {
+	valid := ConsumerConfig{	Enabled: true,
+								GroupID: "GROUP-01",
+								Topics: []string{ "topic.completion" },
+								DeadLetterTopic: "topic.completion.dlq",
+								MaxAttempts: 5,
+								RetryBackoff: 1000,
+								RetryBackoffMax: 30000,
+								MaxPollInterval: 300000 }
+
+	tests := []struct {
+		name		string
+		change		func(c *ConsumerConfig)
+		valid		bool
+	}{
+		{ "valid", func(c *ConsumerConfig) {}, true },
+		{ "disabled", func(c *ConsumerConfig) { c.DeadLetterTopic = "" ; c.MaxAttempts = 0 ; c.Enabled = false }, true },
+		{ "no group", func(c *ConsumerConfig) { c.GroupID = "" }, false },
+		{ "invalid topic", func(c *ConsumerConfig) { c.Topics = []string{ "topic completion" } }, false },
+		{ "no dead letter topic", func(c *ConsumerConfig) { c.DeadLetterTopic = "" }, false },
+		{ "dead letter topic consumed", func(c *ConsumerConfig) { c.DeadLetterTopic = "topic.completion" }, false },
+		{ "no attempts", func(c *ConsumerConfig) { c.MaxAttempts = 0 }, false },
+		{ "no backoff", func(c *ConsumerConfig) { c.RetryBackoff = 0 }, false },
+		{ "max backoff below backoff", func(c *ConsumerConfig) { c.RetryBackoffMax = 500 }, false },
+		{ "no max poll interval", func(c *ConsumerConfig) { c.MaxPollInterval = 0 }, false },
+		{ "retries above the max poll interval", func(c *ConsumerConfig) { c.MaxAttempts = 20 }, false },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consumerConfig := valid
+			tt.change(&consumerConfig)
+
+			err := consumerConfig.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("Validate = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestConsumerConfigBackoff(t *testing.T) {
+	consumerConfig := ConsumerConfig{ MaxAttempts: 5, RetryBackoff: 1000, RetryBackoffMax: 3000 }
+
+	for attempt, want := range []time.Duration{ 1: time.Second, 2: 2 * time.Second, 3: 3 * time.Second, 4: 3 * time.Second } {
+		if attempt == 0 {
+			continue
+		}
+		if consumerConfig.Backoff(attempt) != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempt, consumerConfig.Backoff(attempt), want)
+		}
+	}
+	if consumerConfig.RetryWindow() != 9 * time.Second {
+		t.Errorf("RetryWindow = %s, want 9s", consumerConfig.RetryWindow())
+	}
+}
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"time"
+	"math/big"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About a conversion rate, 1 unit of CurrencyFrom = Rate units of CurrencyTo
+type FxRate struct {
+	CurrencyFrom	string		`json:"currency_from"`
+	CurrencyTo		string		`json:"currency_to"`
+	Rate			string		`json:"rate"`
+	Source			string		`json:"source,omitempty"`
+	RateAt			time.Time	`json:"rate_at"`
+}
+
+// About the conversion applied to the credit leg of a transfer
+type FxConversion struct {
+	Rate				string		`json:"rate"`
+	SourceCurrency		string		`json:"source_currency"`
+	SourceAmount		Money		`json:"source_amount"`
+	DestinationCurrency	string		`json:"destination_currency"`
+	DestinationAmount	Money		`json:"destination_amount"`
+	Fee					Money		`json:"fee"`
+	RateAt				time.Time	`json:"rate_at"`
+	QuoteID				string		`json:"quote_id,omitempty"`
+}
+
+type FxConfig struct {
+	RateFile	string	`json:"rate_file"`
+	QuoteTTL	int		`json:"quote_ttl"`
+	FeeBps		int		`json:"fee_bps"`
+}
+
+// About decode a conversion binding each amount to its currency
+func (f *FxConversion) UnmarshalJSON(data []byte) error {
+	type fxConversionAlias FxConversion
+	aux := struct {
+		*fxConversionAlias
+		SourceAmount		json.Number	`json:"source_amount,omitempty"`
+		DestinationAmount	json.Number	`json:"destination_amount,omitempty"`
+		Fee					json.Number	`json:"fee,omitempty"`
+	}{ fxConversionAlias: (*fxConversionAlias)(f) }
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	f.SourceCurrency = NormalizeCurrency(f.SourceCurrency)
+	f.DestinationCurrency = NormalizeCurrency(f.DestinationCurrency)
+
+	var err error
+	f.SourceAmount, err = decodeAmount(aux.SourceAmount, f.SourceCurrency)
+	if err != nil {
+		return err
+	}
+	f.DestinationAmount, err = decodeAmount(aux.DestinationAmount, f.DestinationCurrency)
+	if err != nil {
+		return err
+	}
+	f.Fee, err = decodeAmount(aux.Fee, f.SourceCurrency)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// About parse a decimal rate, it must be positive
+func ParseRate(rate string) (*big.Rat, error) {
+	rat, ok := new(big.Rat).SetString(rate)
+	if !ok || rat.Sign() <= 0 {
+		return nil, erro.ErrRateNotFound
+	}
+	return rat, nil
+}
+
+// About convert a money into another currency, rounded half to even (banker's rounding) to the minor unit
+func ConvertMoney(money Money, rate string, currency string) (Money, error) {
+	rat, err := ParseRate(rate)
+	if err != nil {
+		return Money{}, err
+	}
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// source minor units -> major units -> destination major units -> destination minor units
+	value := new(big.Rat).SetFrac(big.NewInt(money.Minor), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(money.Exponent())), nil))
+	value.Mul(value, rat)
+	value.Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)))
+
+	minor := roundHalfEven(value)
+	if !minor.IsInt64() {
+		return Money{}, erro.ErrAmountInvalid
+	}
+	return NewMoney(minor.Int64(), currency)
+}
+
+// About the fee of an amount in basis points (1 bps = 0.01%), rounded half to even to the minor unit
+func FeeOf(money Money, bps int) Money {
+	value := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(money.Minor), big.NewInt(int64(bps))), big.NewInt(10000))
+	return Money{Minor: roundHalfEven(value).Int64(), Currency: money.Currency}
+}
+
+// About round a rational to the nearest integer, ties to even
+func roundHalfEven(rat *big.Rat) *big.Int {
+	quo, rem := new(big.Int).QuoRem(rat.Num(), rat.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	// compare 2*|rem| with the denominator
+	twice := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2))
+	cmp := twice.Cmp(rat.Denom())
+	if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+		if rat.Sign() < 0 {
+			return quo.Sub(quo, big.NewInt(1))
+		}
+		return quo.Add(quo, big.NewInt(1))
+	}
+	return quo
+}
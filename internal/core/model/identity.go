@@ -0,0 +1,19 @@
+package model
+
+import (
+	"fmt"
+)
+
+// About the identity of the user set by the api gateway (X-User-Id, X-User-Roles), signed with a secret shared
+// with the gateway (X-User-Signature) so a client can not claim a user or a role
+type IdentityConfig struct {
+	Secret		string	`json:"-"`
+}
+
+// About check the identity settings, the secret is required
+func (c IdentityConfig) Validate() error {
+	if c.Secret == "" {
+		return fmt.Errorf("identity: secret missing (IDENTITY_SECRET or /var/pod/secret/identity_secret)")
+	}
+	return nil
+}
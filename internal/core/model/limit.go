@@ -0,0 +1,196 @@
+package model
+
+import (
+	"time"
+	"strconv"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+const (
+	LimitScopeAccount	= "ACCOUNT"
+	LimitScopeTenant	= "TENANT"
+
+	LimitMaxPerTransaction	= "MAX_PER_TRANSACTION"
+	LimitDailyAmount		= "DAILY_AMOUNT"
+	LimitMonthlyAmount		= "MONTHLY_AMOUNT"
+	LimitDailyCount			= "DAILY_COUNT"
+	LimitMonthlyCount		= "MONTHLY_COUNT"
+)
+
+// About the limits of the outgoing transfers (transfer and debit) of an account or a tenant in a currency, zero means no limit.
+// A transfer above the approval threshold needs a second user to approve it (maker-checker)
+type TransferLimit struct {
+	ID					int			`json:"id,omitempty"`
+	Scope				string		`json:"scope"`
+	ScopeID				string		`json:"scope_id"`
+	Currency			string		`json:"currency"`
+	MaxPerTransaction	Money		`json:"max_per_transaction"`
+	DailyAmount			Money		`json:"daily_amount"`
+	MonthlyAmount		Money		`json:"monthly_amount"`
+	DailyCount			int			`json:"daily_count"`
+	MonthlyCount		int			`json:"monthly_count"`
+	ApprovalThreshold	Money		`json:"approval_threshold"`
+	UpdatedAt			time.Time	`json:"updated_at,omitempty"`
+}
+
+// About the amounts and counts already used in the current day and month (UTC)
+type LimitUsage struct {
+	DailyAmount		Money	`json:"daily_amount"`
+	MonthlyAmount	Money	`json:"monthly_amount"`
+	DailyCount		int		`json:"daily_count"`
+	MonthlyCount	int		`json:"monthly_count"`
+}
+
+// About a limit breached by a transfer and what is still allowed
+type LimitBreach struct {
+	Scope			string	`json:"scope"`
+	ScopeID			string	`json:"scope_id"`
+	Limit			string	`json:"limit"`
+	RemainingAmount	*Money	`json:"remaining_amount,omitempty"`
+	RemainingCount	*int	`json:"remaining_count,omitempty"`
+}
+
+// About the error of a breached limit, it unwraps to erro.ErrLimitExceeded
+type LimitExceededError struct {
+	Breach	*LimitBreach
+}
+
+func (e *LimitExceededError) Error() string {
+	return erro.ErrLimitExceeded.Error() + ": " + e.Breach.String()
+}
+
+func (e *LimitExceededError) Unwrap() error {
+	return erro.ErrLimitExceeded
+}
+
+// About decode a limit binding the amounts to its currency
+func (l *TransferLimit) UnmarshalJSON(data []byte) error {
+	type transferLimitAlias TransferLimit
+	aux := struct {
+		*transferLimitAlias
+		MaxPerTransaction	json.Number	`json:"max_per_transaction,omitempty"`
+		DailyAmount			json.Number	`json:"daily_amount,omitempty"`
+		MonthlyAmount		json.Number	`json:"monthly_amount,omitempty"`
+		ApprovalThreshold	json.Number	`json:"approval_threshold,omitempty"`
+	}{ transferLimitAlias: (*transferLimitAlias)(l) }
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	l.Currency = NormalizeCurrency(l.Currency)
+
+	var err error
+	l.MaxPerTransaction, err = decodeAmount(aux.MaxPerTransaction, l.Currency)
+	if err != nil {
+		return err
+	}
+	l.DailyAmount, err = decodeAmount(aux.DailyAmount, l.Currency)
+	if err != nil {
+		return err
+	}
+	l.MonthlyAmount, err = decodeAmount(aux.MonthlyAmount, l.Currency)
+	if err != nil {
+		return err
+	}
+	l.ApprovalThreshold, err = decodeAmount(aux.ApprovalThreshold, l.Currency)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// About the remaining allowance as text (ex: DAILY_AMOUNT remaining 150.00 BRL)
+func (b *LimitBreach) String() string {
+	remaining := ""
+	if b.RemainingAmount != nil {
+		remaining = b.RemainingAmount.String() + " " + b.RemainingAmount.Currency
+	}
+	if b.RemainingCount != nil {
+		remaining = strconv.Itoa(*b.RemainingCount)
+	}
+	return b.Scope + " " + b.ScopeID + " " + b.Limit + " remaining " + remaining
+}
+
+// About an outgoing amount (positive) above the approval threshold
+func (l *TransferLimit) RequiresApproval(amount Money) (bool, error) {
+	if !l.ApprovalThreshold.IsPositive() {
+		return false, nil
+	}
+	over, err := amount.Add(l.ApprovalThreshold.Neg())
+	if err != nil {
+		return false, err
+	}
+	return over.IsPositive(), nil
+}
+
+// About check an outgoing amount (positive) against the limit and the usage, the first breach is returned
+func (l *TransferLimit) Check(amount Money, usage *LimitUsage) (*LimitBreach, error) {
+	breach := LimitBreach{	Scope: l.Scope,
+							ScopeID: l.ScopeID }
+
+	if l.MaxPerTransaction.IsPositive() {
+		over, err := amount.Add(l.MaxPerTransaction.Neg())
+		if err != nil {
+			return nil, err
+		}
+		if over.IsPositive() {
+			remaining := l.MaxPerTransaction
+			breach.Limit = LimitMaxPerTransaction
+			breach.RemainingAmount = &remaining
+			return &breach, nil
+		}
+	}
+
+	amountLimits := []struct {
+		limit	string
+		max		Money
+		used	Money
+	}{
+		{ LimitDailyAmount, l.DailyAmount, usage.DailyAmount },
+		{ LimitMonthlyAmount, l.MonthlyAmount, usage.MonthlyAmount },
+	}
+	for _, amountLimit := range amountLimits {
+		if !amountLimit.max.IsPositive() {
+			continue
+		}
+		remaining, err := amountLimit.max.Add(amountLimit.used.Neg())
+		if err != nil {
+			return nil, err
+		}
+		over, err := amount.Add(remaining.Neg())
+		if err != nil {
+			return nil, err
+		}
+		if over.IsPositive() {
+			if remaining.IsNegative() {
+				remaining.Minor = 0
+			}
+			breach.Limit = amountLimit.limit
+			breach.RemainingAmount = &remaining
+			return &breach, nil
+		}
+	}
+
+	countLimits := []struct {
+		limit	string
+		max		int
+		used	int
+	}{
+		{ LimitDailyCount, l.DailyCount, usage.DailyCount },
+		{ LimitMonthlyCount, l.MonthlyCount, usage.MonthlyCount },
+	}
+	for _, countLimit := range countLimits {
+		if countLimit.max <= 0 || countLimit.used < countLimit.max {
+			continue
+		}
+		remaining := 0
+		breach.Limit = countLimit.limit
+		breach.RemainingCount = &remaining
+		return &breach, nil
+	}
+
+	return nil, nil
+}
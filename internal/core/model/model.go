@@ -2,6 +2,8 @@ package model
 
 import (
 	"time"
+	"encoding/json"
+
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"
 	go_core_observ "github.com/eliezerraj/go-core/observability"
 	go_core_event "github.com/eliezerraj/go-core/event/kafka" 
@@ -13,8 +15,18 @@ type AppServer struct {
 	ConfigOTEL		*go_core_observ.ConfigOTEL	`json:"otel_config"`
 	DatabaseConfig	*go_core_pg.DatabaseConfig  `json:"database"`
 	KafkaConfigurations	*go_core_event.KafkaConfigurations  `json:"kafka_configurations"`
-	ApiService 		[]ApiService				`json:"api_endpoints"`
-	Topics 			[]string					`json:"topics"`
+	Endpoints 		map[string]ApiService		`json:"api_endpoints"`
+	EventRouting	EventRouting				`json:"event_routing"`
+	SagaConfig		*SagaConfig					`json:"saga_config"`
+	OutboxConfig	*OutboxConfig				`json:"outbox_config"`
+	ConsumerConfig	*ConsumerConfig				`json:"consumer_config"`
+	SchedulerConfig	*SchedulerConfig			`json:"scheduler_config"`
+	BatchConfig		*BatchConfig				`json:"batch_config"`
+	FxConfig		*FxConfig					`json:"fx_config"`
+	RiskConfig		*RiskConfig					`json:"risk_config"`
+	ReviewConfig	*ReviewConfig				`json:"review_config"`
+	ApprovalConfig	*ApprovalConfig				`json:"approval_config"`
+	IdentityConfig	*IdentityConfig				`json:"identity_config"`
 }
 
 type InfoPod struct {
@@ -36,27 +48,33 @@ type Server struct {
 	CtxTimeout		int `json:"ctxTimeout"`
 }
 
-type ApiService struct {
-	Name			string `json:"name_service"`
-	Url				string `json:"url"`
-	Method			string `json:"method"`
-	Header_x_apigw_api_id	string `json:"x-apigw-api-id"`
-}
-
 type MessageRouter struct {
 	Message			string `json:"message"`
 }
 
+// About the prefix of the idempotency keys of the internal workers (scheduler, standing orders), refused from the clients
+const IdempotencyInternalPrefix = "internal:"
+
+type Idempotency struct {
+	Key				string			`json:"idempotency_key"`
+	RequestHash		string			`json:"request_hash"`
+	Response		json.RawMessage	`json:"response,omitempty"`
+	CreatedAt		time.Time		`json:"created_at,omitempty"`
+}
+
 type Transfer struct {
 	ID				int			`json:"id,omitempty"`
 	AccountFrom		*AccountStatement	`json:"account_from,omitempty"`
 	AccountTo		*AccountStatement	`json:"account_to,omitempty"`
 	Currency		string  	`json:"currency,omitempty"`
-	Amount			float64 	`json:"amount,omitempty"`
+	Amount			Money 		`json:"amount"`
 	TransferAt		time.Time 	`json:"transfer_at,omitempty"`
 	Type			string  	`json:"type_charge,omitempty"`
-	Status			string  	`json:"status,omitempty"`
+	Status			TransferStatus	`json:"status,omitempty"`
 	TransactionID	*string  	`json:"transaction_id,omitempty"`
+	Fx				*FxConversion	`json:"fx,omitempty"`
+	QuoteID			string		`json:"quote_id,omitempty"`
+	RequestedBy		string		`json:"requested_by,omitempty"`
 }
 
 type AccountStatement struct {
@@ -66,8 +84,80 @@ type AccountStatement struct {
 	Type			string  	`json:"type_charge,omitempty"`
 	ChargeAt		time.Time 	`json:"charged_at,omitempty"`
 	Currency		string  	`json:"currency,omitempty"`
-	Amount			float64 	`json:"amount,omitempty"`
+	Amount			Money 		`json:"amount"`
 	TenantID		string  	`json:"tenant_id,omitempty"`
 	Obs				string  	`json:"obs,omitempty"`
 	TransactionID	*string  	`json:"transaction_id,omitempty"`
-}
\ No newline at end of file
+	IdempotencyKey	string		`json:"-"`
+}
+
+// About an account as answered by go-account
+type Account struct {
+	ID				int			`json:"id"`
+	AccountID		string		`json:"account_id"`
+	PersonID		string		`json:"person_id,omitempty"`
+	Currency		string		`json:"currency,omitempty"`
+	TenantID		string		`json:"tenant_id,omitempty"`
+}
+
+// About decode a transfer binding the amount to its currency
+func (t *Transfer) UnmarshalJSON(data []byte) error {
+	type transferAlias Transfer
+	aux := struct {
+		*transferAlias
+		Amount	json.Number	`json:"amount,omitempty"`
+	}{ transferAlias: (*transferAlias)(t) }
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t.Currency = NormalizeCurrency(t.Currency)
+
+	amount, err := decodeAmount(aux.Amount, t.Currency)
+	if err != nil {
+		return err
+	}
+	t.Amount = amount
+
+	return nil
+}
+
+// About decode an account statement binding the amount to its currency
+func (a *AccountStatement) UnmarshalJSON(data []byte) error {
+	type accountStatementAlias AccountStatement
+	aux := struct {
+		*accountStatementAlias
+		Amount	json.Number	`json:"amount,omitempty"`
+	}{ accountStatementAlias: (*accountStatementAlias)(a) }
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	a.Currency = NormalizeCurrency(a.Currency)
+
+	amount, err := decodeAmount(aux.Amount, a.Currency)
+	if err != nil {
+		return err
+	}
+	a.Amount = amount
+
+	return nil
+}
+
+// About convert a json number into money, an absent amount is zero. A zero amount without a currency
+// (a leg not filled yet, encoded as 0.00) is the zero money
+func decodeAmount(number json.Number, currency string) (Money, error) {
+	if number == "" {
+		return Money{Currency: currency}, nil
+	}
+	if currency == "" {
+		rat, err := parseDecimal(number.String())
+		if err != nil {
+			return Money{}, err
+		}
+		if rat.Sign() == 0 {
+			return Money{}, nil
+		}
+	}
+	return ParseMoney(number.String(), currency)
+}
@@ -0,0 +1,203 @@
+package model
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"math/big"
+	"strings"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About the number of decimal places (ISO 4217 exponent) allowed per currency
+var currencyExponent = map[string]int32{
+	"BRL": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"ARS": 2,
+	"MXN": 2,
+	"CLP": 0,
+	"JPY": 0,
+	"KWD": 3,
+	"BHD": 3,
+}
+
+const defaultExponent = int32(2)
+
+// About the bounds of a decimal literal, checked before it is parsed: a huge exponent (1e-1000000)
+// would allocate and compute a huge power of 10 before being refused
+const (
+	maxDecimalLength	= 64
+	maxDecimalExponent	= 40
+)
+
+var decimalLiteral = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE]([+-]?[0-9]+))?$`)
+
+// Money is an exact amount held in the minor unit of its currency (ex: cents for BRL)
+type Money struct {
+	Minor		int64	`json:"-"`
+	Currency	string	`json:"-"`
+}
+
+// About get the exponent of a currency
+func CurrencyExponent(currency string) (int32, error) {
+	exp, ok := currencyExponent[strings.ToUpper(currency)]
+	if !ok {
+		return 0, erro.ErrCurrencyInvalid
+	}
+	return exp, nil
+}
+
+// About create a money from minor units
+func NewMoney(minor int64, currency string) (Money, error) {
+	if _, err := CurrencyExponent(currency); err != nil {
+		return Money{}, err
+	}
+	return Money{Minor: minor, Currency: strings.ToUpper(currency)}, nil
+}
+
+// About parse a decimal literal (ex: "10.50") into a money
+func ParseMoney(value string, currency string) (Money, error) {
+	rat, err := parseDecimal(value)
+	if err != nil {
+		return Money{}, err
+	}
+	return moneyFromRat(rat, currency)
+}
+
+// About parse a decimal literal (digits, a point and an exponent) into an exact rational
+func parseDecimal(value string) (*big.Rat, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > maxDecimalLength {
+		return nil, erro.ErrAmountInvalid
+	}
+	match := decimalLiteral.FindStringSubmatch(value)
+	if match == nil {
+		return nil, erro.ErrAmountInvalid
+	}
+	if match[4] != "" {
+		exp, err := strconv.Atoi(match[4])
+		if err != nil || exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return nil, erro.ErrAmountInvalid
+		}
+	}
+
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, erro.ErrAmountInvalid
+	}
+	return rat, nil
+}
+
+// About a currency code as stored and compared (upper case, "brl" is BRL)
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// About create a money from an unscaled integer and a base 10 exponent (value = unscaled * 10^exp)
+func MoneyFromDecimal(unscaled *big.Int, exp int32, currency string) (Money, error) {
+	if unscaled == nil {
+		return NewMoney(0, currency)
+	}
+	rat := new(big.Rat).SetInt(unscaled)
+	pow := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(exp))), nil))
+	if exp >= 0 {
+		rat.Mul(rat, pow)
+	} else {
+		rat.Quo(rat, pow)
+	}
+	return moneyFromRat(rat, currency)
+}
+
+// About scale an exact rational to minor units, rejecting extra decimal places
+func moneyFromRat(rat *big.Rat, currency string) (Money, error) {
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	scaled := new(big.Rat).Mul(rat, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)))
+	if !scaled.IsInt() {
+		return Money{}, erro.ErrAmountInvalid
+	}
+	minor := scaled.Num()
+	if !minor.IsInt64() {
+		return Money{}, erro.ErrAmountInvalid
+	}
+
+	return Money{Minor: minor.Int64(), Currency: strings.ToUpper(currency)}, nil
+}
+
+// About the exponent of the money currency
+func (m Money) Exponent() int32 {
+	exp, err := CurrencyExponent(m.Currency)
+	if err != nil {
+		return defaultExponent
+	}
+	return exp
+}
+
+// About negate a money
+func (m Money) Neg() Money {
+	return Money{Minor: -m.Minor, Currency: m.Currency}
+}
+
+// About add two moneys of the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, erro.ErrCurrencyInvalid
+	}
+	if (other.Minor > 0 && m.Minor > math.MaxInt64 - other.Minor) ||
+		(other.Minor < 0 && m.Minor < math.MinInt64 - other.Minor) {
+		return Money{}, erro.ErrAmountInvalid
+	}
+	return Money{Minor: m.Minor + other.Minor, Currency: m.Currency}, nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Minor < 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Minor > 0
+}
+
+// About the decimal representation (ex: -10.50)
+func (m Money) String() string {
+	exp := m.Exponent()
+	minor := big.NewInt(m.Minor)
+
+	sign := ""
+	if minor.Sign() < 0 {
+		sign = "-"
+		minor.Neg(minor)
+	}
+
+	digits := minor.String()
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= int(exp) {
+		digits = strings.Repeat("0", int(exp) - len(digits) + 1) + digits
+	}
+	cut := len(digits) - int(exp)
+	return sign + digits[:cut] + "." + digits[cut:]
+}
+
+// About encode the money as a json number keeping the currency decimal places
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,225 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"math/big"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name		string
+		value		string
+		currency	string
+		minor		int64
+		err			error
+	}{
+		{ "cents", "10.50", "BRL", 1050, nil },
+		{ "integer", "10", "BRL", 1000, nil },
+		{ "negative", "-0.01", "BRL", -1, nil },
+		{ "trailing zeros", "10.500", "BRL", 1050, nil },
+		{ "exponent", "1.5e2", "BRL", 15000, nil },
+		{ "negative exponent", "150e-2", "BRL", 150, nil },
+		{ "lower case currency", "1", "brl", 100, nil },
+		{ "no decimal places", "150", "JPY", 150, nil },
+		{ "three decimal places", "1.234", "KWD", 1234, nil },
+		{ "too many decimal places", "10.505", "BRL", 0, erro.ErrAmountInvalid },
+		{ "decimal places of JPY", "1.5", "JPY", 0, erro.ErrAmountInvalid },
+		{ "above int64", "92233720368547758.08", "BRL", 0, erro.ErrAmountInvalid },
+		{ "huge negative exponent", "1e-1000000", "BRL", 0, erro.ErrAmountInvalid },
+		{ "huge exponent", "1e1000000", "BRL", 0, erro.ErrAmountInvalid },
+		{ "too long", "1.00000000000000000000000000000000000000000000000000000000000000000", "BRL", 0, erro.ErrAmountInvalid },
+		{ "fraction", "1/3", "BRL", 0, erro.ErrAmountInvalid },
+		{ "hexadecimal", "0x10", "BRL", 0, erro.ErrAmountInvalid },
+		{ "not a number", "ten", "BRL", 0, erro.ErrAmountInvalid },
+		{ "unknown currency", "10", "XXX", 0, erro.ErrCurrencyInvalid },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			money, err := ParseMoney(tt.value, tt.currency)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMoney: %v", err)
+			}
+			if money.Minor != tt.minor || money.Currency != NormalizeCurrency(tt.currency) {
+				t.Errorf("money = %d %s, want %d %s", money.Minor, money.Currency, tt.minor, NormalizeCurrency(tt.currency))
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		minor		int64
+		currency	string
+		want		string
+	}{
+		{ 1050, "BRL", "10.50" },
+		{ -1050, "BRL", "-10.50" },
+		{ 5, "BRL", "0.05" },
+		{ -5, "BRL", "-0.05" },
+		{ 0, "BRL", "0.00" },
+		{ 150, "JPY", "150" },
+		{ 1, "KWD", "0.001" },
+		{ 0, "", "0.00" },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			money := Money{ Minor: tt.minor, Currency: tt.currency }
+			if money.String() != tt.want {
+				t.Errorf("String = %s, want %s", money.String(), tt.want)
+			}
+
+			// a json number keeping the decimal places
+			data, err := json.Marshal(money)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("MarshalJSON = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyAdd(t *testing.T) {
+	tests := []struct {
+		name	string
+		a		Money
+		b		Money
+		minor	int64
+		err		error
+	}{
+		{ "same currency", Money{ Minor: 1050, Currency: "BRL" }, Money{ Minor: -50, Currency: "BRL" }, 1000, nil },
+		{ "mixed currencies", Money{ Minor: 1050, Currency: "BRL" }, Money{ Minor: 50, Currency: "USD" }, 0, erro.ErrCurrencyInvalid },
+		{ "overflow", Money{ Minor: 1 << 62, Currency: "BRL" }, Money{ Minor: 1 << 62, Currency: "BRL" }, 0, erro.ErrAmountInvalid },
+		{ "underflow", Money{ Minor: -(1 << 62), Currency: "BRL" }, Money{ Minor: -(1 << 62) - 1, Currency: "BRL" }, 0, erro.ErrAmountInvalid },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			money, err := tt.a.Add(tt.b)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil || money.Minor != tt.minor || money.Currency != tt.a.Currency {
+				t.Errorf("Add = %d %s, %v, want %d %s", money.Minor, money.Currency, err, tt.minor, tt.a.Currency)
+			}
+		})
+	}
+}
+
+// About the NUMERIC columns: a money is bound as its minor units and the exponent of its currency,
+// and read back from the unscaled integer and the exponent of the column
+func TestMoneyFromDecimal(t *testing.T) {
+	tests := []struct {
+		name		string
+		unscaled	*big.Int
+		exp			int32
+		currency	string
+		minor		int64
+		err			error
+	}{
+		{ "scale of the currency", big.NewInt(1050), -2, "BRL", 1050, nil },
+		{ "more scale, zeros", big.NewInt(105000), -4, "BRL", 1050, nil },
+		{ "less scale", big.NewInt(105), -1, "BRL", 1050, nil },
+		{ "positive exponent", big.NewInt(15), 1, "JPY", 150, nil },
+		{ "null", nil, 0, "BRL", 0, nil },
+		{ "extra decimal places", big.NewInt(1), -3, "BRL", 0, erro.ErrAmountInvalid },
+		{ "above int64", new(big.Int).Lsh(big.NewInt(1), 70), 0, "BRL", 0, erro.ErrAmountInvalid },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			money, err := MoneyFromDecimal(tt.unscaled, tt.exp, tt.currency)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil || money.Minor != tt.minor || money.Currency != tt.currency {
+				t.Errorf("money = %d %s, %v, want %d %s", money.Minor, money.Currency, err, tt.minor, tt.currency)
+			}
+		})
+	}
+
+	// a bound money is read back as it was
+	for _, money := range []Money{ { Minor: -1050, Currency: "BRL" }, { Minor: 150, Currency: "JPY" }, { Minor: 1234, Currency: "KWD" } } {
+		res_money, err := MoneyFromDecimal(big.NewInt(money.Minor), -money.Exponent(), money.Currency)
+		if err != nil || res_money != money {
+			t.Errorf("round trip of %v = %v, %v", money, res_money, err)
+		}
+	}
+}
+
+func TestDecodeAmount(t *testing.T) {
+	tests := []struct {
+		name		string
+		json		string
+		currency	string
+		minor		int64
+		err			error
+	}{
+		{ "amount", `{"currency":"BRL","amount":10.50}`, "BRL", 1050, nil },
+		{ "lower case currency", `{"currency":"brl","amount":10.50}`, "BRL", 1050, nil },
+		{ "no amount", `{"currency":"BRL"}`, "BRL", 0, nil },
+		{ "leg not filled", `{"account_id":"ACC-1","amount":0.00}`, "", 0, nil },
+		{ "no currency", `{"account_id":"ACC-1","amount":10.50}`, "", 0, erro.ErrCurrencyInvalid },
+		{ "huge exponent", `{"currency":"BRL","amount":1e-1000000}`, "", 0, erro.ErrAmountInvalid },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accountStatement AccountStatement
+			err := json.Unmarshal([]byte(tt.json), &accountStatement)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if accountStatement.Currency != tt.currency || accountStatement.Amount.Minor != tt.minor || accountStatement.Amount.Currency != tt.currency {
+				t.Errorf("statement = %s %d %s, want %s %d", accountStatement.Currency, accountStatement.Amount.Minor, accountStatement.Amount.Currency, tt.currency, tt.minor)
+			}
+		})
+	}
+}
+
+func TestTransferRoundTrip(t *testing.T) {
+	// a transfer with a leg not filled yet (no currency, zero amount) is encoded and decoded back
+	transfer := Transfer{	Type: "TRANSFER",
+							Currency: "BRL",
+							Amount: Money{ Minor: 1050, Currency: "BRL" },
+							AccountFrom: &AccountStatement{ AccountID: "ACC-1" },
+							AccountTo: &AccountStatement{ AccountID: "ACC-2", Currency: "BRL", Amount: Money{ Minor: 1050, Currency: "BRL" } } }
+
+	data, err := json.Marshal(transfer)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var res_transfer Transfer
+	err = json.Unmarshal(data, &res_transfer)
+	if err != nil {
+		t.Fatalf("Unmarshal %s: %v", data, err)
+	}
+	if res_transfer.Amount != transfer.Amount || res_transfer.AccountFrom.Amount != (Money{}) || res_transfer.AccountTo.Amount != transfer.AccountTo.Amount {
+		t.Errorf("transfer = %s, want %s", data, data)
+	}
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	OutboxPending	= "PENDING"
+	OutboxSent		= "SENT"
+)
+
+// About an event stored in the same transaction of the transfer, published later by the relay
+type Outbox struct {
+	ID				int			`json:"id,omitempty"`
+	Topic			string		`json:"topic"`
+	EventType		string		`json:"event_type"`
+	Key				string		`json:"key"`
+	TraceID			string		`json:"trace_id,omitempty"`
+	Payload			[]byte		`json:"payload,omitempty"`
+	Status			string		`json:"status,omitempty"`
+	Attempts		int			`json:"attempts"`
+	LastError		string		`json:"last_error,omitempty"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+	SentAt			*time.Time	`json:"sent_at,omitempty"`
+}
+
+type OutboxConfig struct {
+	Enabled			bool	`json:"enabled"`
+	RelayInterval	int		`json:"relay_interval"`
+	BatchSize		int		`json:"batch_size"`
+	Publisher		string	`json:"publisher"`
+}
+
+// About check the outbox relay settings, the relay ticks every RelayInterval seconds and reads BatchSize rows
+func (c OutboxConfig) Validate() error {
+	if c.RelayInterval <= 0 {
+		return fmt.Errorf("outbox: relay interval must be a positive number of seconds")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("outbox: batch size must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+	"encoding/json"
+)
+
+const (
+	QuoteOpen	= "OPEN"
+	QuoteUsed	= "USED"
+)
+
+// About a locked fx rate (with its fee), redeemable by one transfer until expires_at
+type Quote struct {
+	ID					string		`json:"quote_id,omitempty"`
+	Currency			string		`json:"currency"`
+	Amount				Money		`json:"amount"`
+	CurrencyTo			string		`json:"currency_to"`
+	Rate				string		`json:"rate,omitempty"`
+	Fee					Money		`json:"fee"`
+	DestinationAmount	Money		`json:"destination_amount"`
+	Status				string		`json:"status,omitempty"`
+	CreatedAt			time.Time	`json:"created_at"`
+	ExpiresAt			time.Time	`json:"expires_at"`
+	UsedAt				*time.Time	`json:"used_at,omitempty"`
+	TransactionID		*string		`json:"transaction_id,omitempty"`
+}
+
+// About decode a quote binding each amount to its currency
+func (q *Quote) UnmarshalJSON(data []byte) error {
+	type quoteAlias Quote
+	aux := struct {
+		*quoteAlias
+		Amount				json.Number	`json:"amount,omitempty"`
+		Fee					json.Number	`json:"fee,omitempty"`
+		DestinationAmount	json.Number	`json:"destination_amount,omitempty"`
+	}{ quoteAlias: (*quoteAlias)(q) }
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	q.Currency = NormalizeCurrency(q.Currency)
+	q.CurrencyTo = NormalizeCurrency(q.CurrencyTo)
+
+	var err error
+	q.Amount, err = decodeAmount(aux.Amount, q.Currency)
+	if err != nil {
+		return err
+	}
+	q.Fee, err = decodeAmount(aux.Fee, q.Currency)
+	if err != nil {
+		return err
+	}
+	q.DestinationAmount, err = decodeAmount(aux.DestinationAmount, q.CurrencyTo)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
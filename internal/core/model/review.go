@@ -0,0 +1,61 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	ReviewPending	= "PENDING"
+	ReviewApproved	= "APPROVED"
+	ReviewRejected	= "REJECTED"
+	ReviewExpired	= "EXPIRED"
+
+	// the flow a held transfer (review or approval) resumes
+	FlowTransferRest	= "TRANSFER-REST"
+	FlowTransferEvent	= "TRANSFER-EVENT"
+	FlowCreditEvent		= "CREDIT-EVENT"
+	FlowDebitEvent		= "DEBIT-EVENT"
+)
+
+// About a transfer held (PENDING_REVIEW) by the risk rules. The transfer is kept as submitted, an approval
+// resumes the flow it came from (REST saga or event), a rejection or the expiry closes it
+type TransferReview struct {
+	ID				int				`json:"id,omitempty"`
+	FkTransferID	int				`json:"fk_transfer_id,omitempty"`
+	TransactionID	string			`json:"transaction_id,omitempty"`
+	Flow			string			`json:"flow,omitempty"`
+	Transfer		*Transfer		`json:"transfer,omitempty"`
+	Reasons			[]RiskReason	`json:"reasons,omitempty"`
+	Status			string			`json:"status,omitempty"`
+	Reviewer		string			`json:"reviewer,omitempty"`
+	ReviewerRoles	[]string		`json:"-"`
+	Reason			string			`json:"reason,omitempty"`
+	ExpiresAt		time.Time		`json:"expires_at,omitempty"`
+	DecidedAt		*time.Time		`json:"decided_at,omitempty"`
+	CreatedAt		time.Time		`json:"created_at,omitempty"`
+}
+
+type ReviewConfig struct {
+	TTL				int		`json:"ttl"`
+	ExpiryInterval	int		`json:"expiry_interval"`
+	BatchSize		int		`json:"batch_size"`
+	Role			string	`json:"role"`
+}
+
+// About check the review settings, the expiry worker ticks every ExpiryInterval seconds and expires BatchSize reviews
+func (c ReviewConfig) Validate() error {
+	if c.TTL <= 0 {
+		return fmt.Errorf("review: ttl must be a positive number of seconds")
+	}
+	if c.ExpiryInterval <= 0 {
+		return fmt.Errorf("review: expiry interval must be a positive number of seconds")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("review: expiry batch size must be positive")
+	}
+	if c.Role == "" {
+		return fmt.Errorf("review: reviewer role missing")
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"time"
+	"strings"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+const (
+	RiskAllow	= "ALLOW"
+	RiskReview	= "REVIEW"
+	RiskDeny	= "DENY"
+
+	RiskRuleVelocity			= "VELOCITY"
+	RiskRuleNewDestination		= "NEW_DESTINATION_LARGE_AMOUNT"
+	RiskRuleRoundAmountBurst	= "ROUND_AMOUNT_BURST"
+	RiskRuleBlocklist			= "BLOCKLIST"
+
+	RiskOperationTransfer	= "TRANSFER"
+	RiskOperationCredit		= "CREDIT"
+	RiskOperationDebit		= "DEBIT"
+)
+
+type RiskConfig struct {
+	RuleFile	string	`json:"rule_file"`
+}
+
+// About a declarative risk rule (loaded from RISK_RULE_FILE), a matched rule gives its decision (REVIEW or DENY).
+// Operations and currency restrict where the rule applies, empty means all
+type RiskRule struct {
+	Name			string		`json:"name"`
+	Type			string		`json:"type"`
+	Decision		string		`json:"decision"`
+	Operations		[]string	`json:"operations,omitempty"`
+	Currency		string		`json:"currency,omitempty"`
+	MaxCount		int			`json:"max_count,omitempty"`
+	WindowMinutes	int			`json:"window_minutes,omitempty"`
+	MinAmount		Money		`json:"min_amount"`
+	RoundTo			Money		`json:"round_to"`
+	Accounts		[]string	`json:"accounts,omitempty"`
+}
+
+// About why a rule matched a transfer
+type RiskReason struct {
+	Rule		string	`json:"rule"`
+	Type		string	`json:"type"`
+	Decision	string	`json:"decision"`
+	Reason		string	`json:"reason"`
+}
+
+// About the decision of the rules on a transfer, stored by transaction_id
+type RiskDecision struct {
+	ID				int				`json:"id,omitempty"`
+	TransactionID	string			`json:"transaction_id"`
+	Operation		string			`json:"operation"`
+	AccountFrom		string			`json:"account_from,omitempty"`
+	AccountTo		string			`json:"account_to,omitempty"`
+	Amount			Money			`json:"amount"`
+	Currency		string			`json:"currency"`
+	Decision		string			`json:"decision"`
+	Reasons			[]RiskReason	`json:"reasons"`
+	CreatedAt		time.Time		`json:"created_at"`
+}
+
+// About the error of a transfer denied by the risk rules, it unwraps to erro.ErrRiskDenied
+type RiskDeniedError struct {
+	Decision	*RiskDecision
+}
+
+func (e *RiskDeniedError) Error() string {
+	rules := []string{}
+	for _, reason := range e.Decision.Reasons {
+		if reason.Decision == RiskDeny {
+			rules = append(rules, reason.Rule)
+		}
+	}
+	return erro.ErrRiskDenied.Error() + ": " + strings.Join(rules, ", ")
+}
+
+func (e *RiskDeniedError) Unwrap() error {
+	return erro.ErrRiskDenied
+}
+
+// About decode a rule binding the amounts to the currency of the rule
+func (r *RiskRule) UnmarshalJSON(data []byte) error {
+	type riskRuleAlias RiskRule
+	aux := struct {
+		*riskRuleAlias
+		MinAmount	json.Number	`json:"min_amount,omitempty"`
+		RoundTo		json.Number	`json:"round_to,omitempty"`
+	}{ riskRuleAlias: (*riskRuleAlias)(r) }
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.Currency = NormalizeCurrency(r.Currency)
+
+	var err error
+	if aux.MinAmount != "" || aux.RoundTo != "" {
+		if _, err = CurrencyExponent(r.Currency); err != nil {
+			return err
+		}
+	}
+	r.MinAmount, err = decodeAmount(aux.MinAmount, r.Currency)
+	if err != nil {
+		return err
+	}
+	r.RoundTo, err = decodeAmount(aux.RoundTo, r.Currency)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// About check the parameters required by the type of the rule
+func (r *RiskRule) Validate() error {
+	if r.Name == "" || (r.Decision != RiskReview && r.Decision != RiskDeny) {
+		return erro.ErrInvalid
+	}
+
+	switch r.Type {
+	case RiskRuleVelocity:
+		if r.MaxCount <= 0 || r.WindowMinutes <= 0 {
+			return erro.ErrInvalid
+		}
+	case RiskRuleNewDestination:
+		if !r.MinAmount.IsPositive() {
+			return erro.ErrInvalid
+		}
+	case RiskRuleRoundAmountBurst:
+		if !r.RoundTo.IsPositive() || r.MaxCount <= 0 || r.WindowMinutes <= 0 {
+			return erro.ErrInvalid
+		}
+	case RiskRuleBlocklist:
+		if len(r.Accounts) == 0 {
+			return erro.ErrInvalid
+		}
+	default:
+		return erro.ErrInvalid
+	}
+	return nil
+}
+
+// About the rule applies to the operation and currency
+func (r *RiskRule) Applies(operation string, currency string) bool {
+	if r.Currency != "" && !strings.EqualFold(r.Currency, currency) {
+		return false
+	}
+	if len(r.Operations) == 0 {
+		return true
+	}
+	for _, op := range r.Operations {
+		if strings.EqualFold(op, operation) {
+			return true
+		}
+	}
+	return false
+}
+
+// About the most severe of two decisions (DENY > REVIEW > ALLOW)
+func RiskSeverest(a string, b string) string {
+	severity := map[string]int{ RiskAllow: 0, RiskReview: 1, RiskDeny: 2 }
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
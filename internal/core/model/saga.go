@@ -0,0 +1,65 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type SagaStatus string
+
+const (
+	SagaRunning				SagaStatus = "RUNNING"
+	SagaCompleted			SagaStatus = "COMPLETED"
+	SagaCompensating		SagaStatus = "COMPENSATING"
+	SagaCompensated			SagaStatus = "COMPENSATED"
+	SagaCompensationFailed	SagaStatus = "COMPENSATION_FAILED"
+)
+
+const (
+	SagaStepExecute			= "EXECUTE"
+	SagaStepCompensate		= "COMPENSATE"
+
+	SagaStepPending			= "PENDING"
+	SagaStepDone			= "DONE"
+	SagaStepFailed			= "FAILED"
+)
+
+// About a saga persisted to survive a crash, Transfer is the payload needed to rebuild the steps
+type Saga struct {
+	ID				int				`json:"id,omitempty"`
+	TransactionID	*string			`json:"transaction_id,omitempty"`
+	Type			string			`json:"type,omitempty"`
+	Status			SagaStatus		`json:"status,omitempty"`
+	Transfer		*Transfer		`json:"transfer,omitempty"`
+	Steps			[]SagaStep		`json:"steps,omitempty"`
+	CreatedAt		time.Time		`json:"created_at,omitempty"`
+	UpdatedAt		time.Time		`json:"updated_at,omitempty"`
+}
+
+// About each execution or compensation of a saga step
+type SagaStep struct {
+	ID				int			`json:"id,omitempty"`
+	FkSagaID		int			`json:"fk_saga_id,omitempty"`
+	Name			string		`json:"name"`
+	Action			string		`json:"action"`
+	Status			string		`json:"status"`
+	Error			string		`json:"error,omitempty"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+	UpdatedAt		time.Time	`json:"updated_at,omitempty"`
+}
+
+type SagaConfig struct {
+	RecoveryInterval	int	`json:"recovery_interval"`
+	RecoveryAge			int	`json:"recovery_age"`
+}
+
+// About check the saga recovery settings, the worker ticks every RecoveryInterval seconds
+func (c SagaConfig) Validate() error {
+	if c.RecoveryInterval <= 0 {
+		return fmt.Errorf("saga: recovery interval must be a positive number of seconds")
+	}
+	if c.RecoveryAge <= 0 {
+		return fmt.Errorf("saga: recovery age must be a positive number of seconds")
+	}
+	return nil
+}
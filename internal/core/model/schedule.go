@@ -0,0 +1,56 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	ScheduleCredit		= "CREDIT"
+	ScheduleDebit		= "DEBIT"
+	ScheduleTransfer	= "TRANSFER"
+
+	SchedulePending		= "PENDING"
+	ScheduleDone		= "DONE"
+	ScheduleFailed		= "FAILED"
+	ScheduleCanceled	= "CANCELED"
+)
+
+// About a future-dated credit, debit or transfer, executed by the scheduler at execute_at
+type Schedule struct {
+	ID				int			`json:"id,omitempty"`
+	Type			string		`json:"type,omitempty"`
+	Transfer		*Transfer	`json:"transfer,omitempty"`
+	ExecuteAt		time.Time	`json:"execute_at"`
+	Status			string		`json:"status,omitempty"`
+	Attempts		int			`json:"attempts"`
+	LastError		string		`json:"last_error,omitempty"`
+	FkTransferID	*int		`json:"fk_transfer_id,omitempty"`
+	LockedUntil		*time.Time	`json:"locked_until,omitempty"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+	UpdatedAt		time.Time	`json:"updated_at,omitempty"`
+}
+
+type SchedulerConfig struct {
+	Interval		int	`json:"interval"`
+	BatchSize		int	`json:"batch_size"`
+	MaxAttempts		int	`json:"max_attempts"`
+	Lease			int	`json:"lease"`
+}
+
+// About check the scheduler settings, the worker ticks every Interval seconds and claims BatchSize schedules for Lease seconds
+func (c SchedulerConfig) Validate() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("scheduler: interval must be a positive number of seconds")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("scheduler: batch size must be positive")
+	}
+	if c.MaxAttempts <= 0 {
+		return fmt.Errorf("scheduler: max attempts must be positive")
+	}
+	if c.Lease <= 0 {
+		return fmt.Errorf("scheduler: lease must be a positive number of seconds")
+	}
+	return nil
+}
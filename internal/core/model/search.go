@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// About the position of the last transfer of a page (keyset), the transfers are ordered by transfer_at desc, id desc
+type TransferCursor struct {
+	TransferAt		time.Time	`json:"t"`
+	ID				int			`json:"i"`
+}
+
+// About the filters of the transfer search, empty fields are not applied
+type TransferFilter struct {
+	AccountID		string			`json:"account_id,omitempty"`
+	Status			TransferStatus	`json:"status,omitempty"`
+	Currency		string			`json:"currency,omitempty"`
+	From			*time.Time		`json:"from,omitempty"`
+	To				*time.Time		`json:"to,omitempty"`
+	Cursor			*TransferCursor	`json:"-"`
+	Limit			int				`json:"limit,omitempty"`
+}
+
+type TransferPage struct {
+	Transfers		[]Transfer		`json:"transfers"`
+	NextCursor		string			`json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	FrequencyDaily		= "DAILY"
+	FrequencyWeekly		= "WEEKLY"
+	FrequencyMonthly	= "MONTHLY"
+
+	StandingOrderActive		= "ACTIVE"
+	StandingOrderCompleted	= "COMPLETED"
+	StandingOrderCanceled	= "CANCELED"
+
+	FailurePolicySkip	= "SKIP"
+	FailurePolicyRetry	= "RETRY"
+
+	OccurrenceDone		= "DONE"
+	OccurrenceSkipped	= "SKIPPED"
+	OccurrenceHeld		= "HELD"
+	OccurrenceRejected	= "REJECTED"
+)
+
+// About a recurring transfer (ex: ACC-511 pays ACC-500 BRL 50 every 5th of the month)
+type StandingOrder struct {
+	ID				int			`json:"id,omitempty"`
+	Transfer		*Transfer	`json:"transfer,omitempty"`
+	Frequency		string		`json:"frequency,omitempty"`
+	Interval		int			`json:"interval,omitempty"`
+	StartAt			time.Time	`json:"start_at"`
+	EndAt			*time.Time	`json:"end_at,omitempty"`
+	MaxOccurrences	int			`json:"max_occurrences,omitempty"`
+	FailurePolicy	string		`json:"failure_policy,omitempty"`
+	Occurrences		int			`json:"occurrences"`
+	Attempts		int			`json:"attempts"`
+	NextExecuteAt	time.Time	`json:"next_execute_at"`
+	Status			string		`json:"status,omitempty"`
+	LastError		string		`json:"last_error,omitempty"`
+	LockedUntil		*time.Time	`json:"locked_until,omitempty"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+	UpdatedAt		time.Time	`json:"updated_at,omitempty"`
+	History			*[]StandingOrderOccurrence	`json:"history,omitempty"`
+}
+
+// About an occurrence materialized by the runner, done (with its transfer) or skipped. An occurrence whose transfer
+// is held for review or approval is HELD until the decision, then DONE or REJECTED (rejected or expired)
+type StandingOrderOccurrence struct {
+	ID					int			`json:"id,omitempty"`
+	FkStandingOrderID	int			`json:"fk_standing_order_id,omitempty"`
+	Sequence			int			`json:"sequence"`
+	ScheduledAt			time.Time	`json:"scheduled_at"`
+	Status				string		`json:"status,omitempty"`
+	Attempts			int			`json:"attempts"`
+	LastError			string		`json:"last_error,omitempty"`
+	FkTransferID		*int		`json:"fk_transfer_id,omitempty"`
+	ExecutedAt			time.Time	`json:"executed_at"`
+}
+
+// About check the frequency
+func IsValidFrequency(frequency string) bool {
+	switch frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+		return true
+	}
+	return false
+}
+
+// About the date of an occurrence (sequence starts at 1), always computed from the start_at to avoid drift.
+// A monthly day missing in a month is the last day of that month (ex: the 31st is the 30th in april)
+func (s *StandingOrder) OccurrenceAt(sequence int) time.Time {
+	step := (sequence - 1) * s.Interval
+
+	switch s.Frequency {
+	case FrequencyDaily:
+		return s.StartAt.AddDate(0, 0, step)
+	case FrequencyWeekly:
+		return s.StartAt.AddDate(0, 0, 7 * step)
+	default:
+		year, month, day := s.StartAt.Date()
+		first := time.Date(year, month + time.Month(step), 1, s.StartAt.Hour(), s.StartAt.Minute(), s.StartAt.Second(), 0, s.StartAt.Location())
+		lastDay := first.AddDate(0, 1, -1).Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		return first.AddDate(0, 0, day - 1)
+	}
+}
+
+// About check if an occurrence is beyond the end date or the max occurrences
+func (s *StandingOrder) IsOver(sequence int) bool {
+	if s.MaxOccurrences > 0 && sequence > s.MaxOccurrences {
+		return true
+	}
+	if s.EndAt != nil && s.OccurrenceAt(sequence).After(*s.EndAt) {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"time"
+)
+
+type TransferStatus string
+
+const (
+	StatusTransferRestDone		TransferStatus = "TRANSFER-REST-DONE"
+	StatusTransferEventCreated	TransferStatus = "TRANSFER-EVENT-CREATED"
+	StatusCreditEventCreated	TransferStatus = "CREDIT_EVENT_CREATED"
+	StatusDebitEventCreated		TransferStatus = "DEBIT_EVENT_CREATED"
+	StatusCreditDone			TransferStatus = "CREDIT_DONE"
+	StatusDebitDone				TransferStatus = "DEBIT_DONE"
+	StatusTransferDone			TransferStatus = "TRANSFER_DONE"
+	StatusTransferFailed		TransferStatus = "TRANSFER_FAILED"
+	StatusTransferReversed		TransferStatus = "TRANSFER_REVERSED"
+	StatusPendingReview			TransferStatus = "PENDING_REVIEW"
+	StatusReviewRejected		TransferStatus = "REVIEW_REJECTED"
+	StatusReviewExpired			TransferStatus = "REVIEW_EXPIRED"
+	StatusAwaitingApproval		TransferStatus = "AWAITING_APPROVAL"
+	StatusApprovalRejected		TransferStatus = "APPROVAL_REJECTED"
+)
+
+// About the allowed transitions per transfer type (type_charge), a status not listed as key is terminal.
+// The legs of a transfer complete in any order (DEBIT_DONE, CREDIT_DONE), the last one completes the transfer
+// (see NextStatus). A credit or a debit has a single leg, it never reaches the status of the other leg.
+// A done transfer can still fail (a leg reported failed later) or be reversed.
+// PENDING_REVIEW and AWAITING_APPROVAL are left only by the review and approval workflows
+var statusTransitions = map[string]map[TransferStatus][]TransferStatus{
+	"TRANSFER": {
+		StatusTransferEventCreated:	{ StatusDebitDone, StatusCreditDone, StatusTransferDone, StatusTransferFailed },
+		StatusTransferRestDone:		{ StatusTransferFailed, StatusTransferReversed },
+		StatusCreditDone:			{ StatusDebitDone, StatusTransferDone, StatusTransferFailed, StatusTransferReversed },
+		StatusDebitDone:			{ StatusCreditDone, StatusTransferDone, StatusTransferFailed, StatusTransferReversed },
+		StatusTransferDone:			{ StatusTransferReversed },
+	},
+	"CREDIT": {
+		StatusCreditEventCreated:	{ StatusCreditDone, StatusTransferFailed },
+		StatusCreditDone:			{ StatusTransferFailed, StatusTransferReversed },
+	},
+	"DEBIT": {
+		StatusDebitEventCreated:	{ StatusDebitDone, StatusTransferFailed },
+		StatusDebitDone:			{ StatusTransferFailed, StatusTransferReversed },
+	},
+}
+
+// About the other leg of a transfer, reaching it once a leg is done completes the transfer
+var legsDone = map[TransferStatus]TransferStatus{
+	StatusDebitDone:	StatusCreditDone,
+	StatusCreditDone:	StatusDebitDone,
+}
+
+// About all known status
+var statusList = []TransferStatus{
+	StatusTransferRestDone,
+	StatusTransferEventCreated,
+	StatusCreditEventCreated,
+	StatusDebitEventCreated,
+	StatusCreditDone,
+	StatusDebitDone,
+	StatusTransferDone,
+	StatusTransferFailed,
+	StatusTransferReversed,
+	StatusPendingReview,
+	StatusReviewRejected,
+	StatusReviewExpired,
+	StatusAwaitingApproval,
+	StatusApprovalRejected,
+}
+
+// About check if the status is known
+func (s TransferStatus) IsValid() bool {
+	for _, status := range statusList {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// About the status reached by a transfer of a type (type_charge) asked to move to another one, false when the
+// transition is not allowed (an unknown type has none). The second leg of a transfer is TRANSFER_DONE
+func (s TransferStatus) NextStatus(transferType string, to TransferStatus) (TransferStatus, bool) {
+	for _, status := range statusTransitions[transferType][s] {
+		if status != to {
+			continue
+		}
+		if legsDone[s] == to {
+			return StatusTransferDone, true
+		}
+		return to, true
+	}
+	return s, false
+}
+
+// About a status change of a transfer, StatusFrom is empty on creation
+type StatusTransition struct {
+	ID				int				`json:"id,omitempty"`
+	FkTransferID	int				`json:"fk_transfer_id,omitempty"`
+	StatusFrom		TransferStatus	`json:"status_from,omitempty"`
+	StatusTo		TransferStatus	`json:"status"`
+	Reason			string			`json:"reason,omitempty"`
+	ChangedAt		time.Time		`json:"changed_at,omitempty"`
+}
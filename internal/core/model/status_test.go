@@ -0,0 +1,122 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestTransferStatusNextStatus(t *testing.T) {
+	tests := []struct {
+		transferType	string
+		from			TransferStatus
+		to				TransferStatus
+		want			TransferStatus
+		allowed			bool
+	}{
+		{ "TRANSFER", StatusTransferEventCreated, StatusTransferDone, StatusTransferDone, true },
+		{ "TRANSFER", StatusTransferEventCreated, StatusTransferFailed, StatusTransferFailed, true },
+		{ "TRANSFER", StatusTransferEventCreated, StatusDebitDone, StatusDebitDone, true },
+		{ "TRANSFER", StatusTransferEventCreated, StatusCreditDone, StatusCreditDone, true },
+		{ "TRANSFER", StatusTransferEventCreated, StatusTransferReversed, StatusTransferEventCreated, false },
+		{ "TRANSFER", StatusTransferRestDone, StatusTransferFailed, StatusTransferFailed, true },
+		{ "TRANSFER", StatusTransferRestDone, StatusTransferReversed, StatusTransferReversed, true },
+		{ "TRANSFER", StatusTransferRestDone, StatusTransferDone, StatusTransferRestDone, false },
+		// the last leg completes the transfer
+		{ "TRANSFER", StatusCreditDone, StatusDebitDone, StatusTransferDone, true },
+		{ "TRANSFER", StatusDebitDone, StatusCreditDone, StatusTransferDone, true },
+		{ "TRANSFER", StatusCreditDone, StatusTransferDone, StatusTransferDone, true },
+		{ "TRANSFER", StatusCreditDone, StatusTransferReversed, StatusTransferReversed, true },
+		{ "TRANSFER", StatusDebitDone, StatusTransferFailed, StatusTransferFailed, true },
+		{ "TRANSFER", StatusDebitDone, StatusTransferReversed, StatusTransferReversed, true },
+		{ "TRANSFER", StatusTransferDone, StatusTransferReversed, StatusTransferReversed, true },
+		{ "TRANSFER", StatusTransferDone, StatusTransferFailed, StatusTransferDone, false },
+		{ "TRANSFER", StatusCreditEventCreated, StatusCreditDone, StatusCreditEventCreated, false },
+		// a credit or a debit has a single leg
+		{ "CREDIT", StatusCreditEventCreated, StatusCreditDone, StatusCreditDone, true },
+		{ "CREDIT", StatusCreditEventCreated, StatusTransferFailed, StatusTransferFailed, true },
+		{ "CREDIT", StatusCreditEventCreated, StatusDebitDone, StatusCreditEventCreated, false },
+		{ "CREDIT", StatusCreditDone, StatusDebitDone, StatusCreditDone, false },
+		{ "CREDIT", StatusCreditDone, StatusTransferDone, StatusCreditDone, false },
+		{ "CREDIT", StatusCreditDone, StatusTransferReversed, StatusTransferReversed, true },
+		{ "DEBIT", StatusDebitEventCreated, StatusDebitDone, StatusDebitDone, true },
+		{ "DEBIT", StatusDebitEventCreated, StatusTransferFailed, StatusTransferFailed, true },
+		{ "DEBIT", StatusDebitEventCreated, StatusCreditDone, StatusDebitEventCreated, false },
+		{ "DEBIT", StatusDebitDone, StatusCreditDone, StatusDebitDone, false },
+		{ "DEBIT", StatusDebitDone, StatusTransferDone, StatusDebitDone, false },
+		{ "DEBIT", StatusDebitDone, StatusTransferFailed, StatusTransferFailed, true },
+		{ "", StatusTransferEventCreated, StatusTransferDone, StatusTransferEventCreated, false },
+		// terminal
+		{ "TRANSFER", StatusTransferFailed, StatusTransferDone, StatusTransferFailed, false },
+		{ "TRANSFER", StatusTransferReversed, StatusTransferDone, StatusTransferReversed, false },
+		{ "TRANSFER", StatusReviewRejected, StatusTransferDone, StatusReviewRejected, false },
+		{ "TRANSFER", StatusReviewExpired, StatusTransferDone, StatusReviewExpired, false },
+		{ "TRANSFER", StatusApprovalRejected, StatusTransferDone, StatusApprovalRejected, false },
+		// held, left only by the review and approval workflows
+		{ "TRANSFER", StatusPendingReview, StatusTransferRestDone, StatusPendingReview, false },
+		{ "TRANSFER", StatusPendingReview, StatusAwaitingApproval, StatusPendingReview, false },
+		{ "TRANSFER", StatusAwaitingApproval, StatusTransferEventCreated, StatusAwaitingApproval, false },
+		// back to a created status
+		{ "TRANSFER", StatusTransferDone, StatusTransferEventCreated, StatusTransferDone, false },
+		{ "CREDIT", StatusCreditDone, StatusCreditEventCreated, StatusCreditDone, false },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transferType + ":" + string(tt.from) + "=>" + string(tt.to), func(t *testing.T) {
+			got, allowed := tt.from.NextStatus(tt.transferType, tt.to)
+			if got != tt.want || allowed != tt.allowed {
+				t.Errorf("NextStatus = %s %v, want %s %v", got, allowed, tt.want, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestTransferStatusTransitionsAreKnown(t *testing.T) {
+	for transferType, transitions := range statusTransitions {
+		for from, list := range transitions {
+			if !from.IsValid() {
+				t.Errorf("%s transition from unknown status %s", transferType, from)
+			}
+			for _, to := range list {
+				if !to.IsValid() {
+					t.Errorf("%s transition from %s to unknown status %s", transferType, from, to)
+				}
+				if to == from {
+					t.Errorf("%s transition from %s to itself", transferType, from)
+				}
+			}
+		}
+	}
+}
+
+// About the status reached never leads back to a status already left, a transfer always ends
+func TestTransferStatusTransitionsNoCycle(t *testing.T) {
+	for transferType, transitions := range statusTransitions {
+		var visit func(status TransferStatus, path map[TransferStatus]bool)
+		visit = func(status TransferStatus, path map[TransferStatus]bool) {
+			if path[status] {
+				t.Fatalf("%s cycle through %s", transferType, status)
+			}
+			path[status] = true
+			for _, to := range transitions[status] {
+				next, _ := status.NextStatus(transferType, to)
+				visit(next, path)
+			}
+			delete(path, status)
+		}
+		for from := range transitions {
+			visit(from, map[TransferStatus]bool{})
+		}
+	}
+}
+
+func TestTransferStatusIsValid(t *testing.T) {
+	for _, status := range statusList {
+		if !status.IsValid() {
+			t.Errorf("%s not valid", status)
+		}
+	}
+	for _, status := range []TransferStatus{ "", "DONE", "transfer_done" } {
+		if status.IsValid() {
+			t.Errorf("%q valid", status)
+		}
+	}
+}
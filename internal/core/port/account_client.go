@@ -0,0 +1,18 @@
+package port
+
+import(
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About the client of the account services: go-account (lookup), go-debit and go-credit (statements).
+// The answers are checked against the contract, an answer outside of it is erro.ErrUpstreamContract
+// (account.AccountClient over http, accounttest.Server fakes the three services for the tests).
+// Status is the breaker and the bulkhead of each service
+type AccountClient interface {
+	GetAccount(ctx context.Context, accountID string) (*model.Account, error)
+	PostDebit(ctx context.Context, accountStatement *model.AccountStatement) (*model.AccountStatement, error)
+	PostCredit(ctx context.Context, accountStatement *model.AccountStatement) (*model.AccountStatement, error)
+	Status() []model.DependencyStatus
+}
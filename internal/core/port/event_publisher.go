@@ -0,0 +1,17 @@
+package port
+
+import(
+	"context"
+)
+
+// About the publisher of the events. The messages produced between BeginTransaction and CommitTransaction
+// are delivered together, AbortTransaction drops them; one transaction is open at a time.
+// A non transactional publisher delivers each message at once and refuses the transactions.
+// The event type of a message is sent in its event_type header, the consumers route by it
+// (event.WorkerEvent over kafka, event.MemoryPublisher records the messages for the tests and local runs)
+type EventPublisher interface {
+	BeginTransaction(ctx context.Context) error
+	CommitTransaction(ctx context.Context) error
+	AbortTransaction(ctx context.Context) error
+	Producer(ctx context.Context, event_topic string, event_type string, key string, trace_id *string, payload []byte) error
+}
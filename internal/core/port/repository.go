@@ -0,0 +1,155 @@
+package port
+
+import(
+	"time"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About a unit of work: the operations given the same Tx are committed or rolled back together.
+// Commit and Rollback end the unit of work (and free its resources), a Rollback after a Commit does nothing
+type Tx interface {
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// About begin a unit of work, the methods of the stores given its Tx run in it
+type UnitOfWork interface {
+	Begin(ctx context.Context) (Tx, error)
+}
+
+// About the transfers (transfer_moviment) and their status history
+type TransferStore interface {
+	GetTransactionUUID(ctx context.Context) (*string, error)
+	AddTransfer(ctx context.Context, tx Tx, transfer *model.Transfer) (*model.Transfer, error)
+	GetTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error)
+	GetTransferByTransactionID(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error)
+	ListTransfer(ctx context.Context, transferFilter *model.TransferFilter) (*[]model.Transfer, error)
+
+	// status
+	GetTransferForUpdate(ctx context.Context, tx Tx, transfer *model.Transfer) (*model.Transfer, error)
+	GetTransferIDByTransactionID(ctx context.Context, tx Tx, transfer *model.Transfer) (*model.Transfer, error)
+	UpdateTransferStatus(ctx context.Context, tx Tx, transfer *model.Transfer) (int64, error)
+	AddStatusTransition(ctx context.Context, tx Tx, statusTransition *model.StatusTransition) (*model.StatusTransition, error)
+	ListStatusTransition(ctx context.Context, transfer *model.Transfer) (*[]model.StatusTransition, error)
+}
+
+// About the idempotency keys of the requests and the dedupe markers of the completion events
+type IdempotencyStore interface {
+	AddIdempotencyKey(ctx context.Context, tx Tx, idempotency *model.Idempotency) (bool, error)
+	GetIdempotencyKey(ctx context.Context, tx Tx, idempotency *model.Idempotency) (*model.Idempotency, error)
+	UpdateIdempotencyResponse(ctx context.Context, tx Tx, idempotency *model.Idempotency) (int64, error)
+	AddProcessedEvent(ctx context.Context, tx Tx, transferEvent *model.TransferEvent) (bool, error)
+}
+
+// About the sagas of the REST transfers and their steps
+type SagaStore interface {
+	AddSaga(ctx context.Context, saga *model.Saga) (*model.Saga, error)
+	UpdateSaga(ctx context.Context, saga *model.Saga) (int64, error)
+	TouchSaga(ctx context.Context, saga *model.Saga) (int64, error)
+	CompleteSaga(ctx context.Context, tx Tx, saga *model.Saga) (int64, error)
+	AddSagaStep(ctx context.Context, sagaStep *model.SagaStep) (*model.SagaStep, error)
+	UpdateSagaStep(ctx context.Context, sagaStep *model.SagaStep) (int64, error)
+	ClaimSagaRecovery(ctx context.Context, olderThan time.Time, limit int) (*[]model.Saga, error)
+	ListSagaStep(ctx context.Context, saga *model.Saga) (*[]model.SagaStep, error)
+}
+
+// About the events stored with the transfers, published by the relay
+type OutboxStore interface {
+	AddOutbox(ctx context.Context, tx Tx, outbox *model.Outbox) (*model.Outbox, error)
+	ListOutboxPending(ctx context.Context, tx Tx, limit int) (*[]model.Outbox, error)
+	UpdateOutbox(ctx context.Context, tx Tx, outbox *model.Outbox) (int64, error)
+}
+
+// About the future-dated credits, debits and transfers
+type ScheduleStore interface {
+	AddSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error)
+	ListSchedule(ctx context.Context, schedule *model.Schedule, limit int) (*[]model.Schedule, error)
+	CancelSchedule(ctx context.Context, schedule *model.Schedule) (int64, error)
+	ClaimScheduleDue(ctx context.Context, lockedUntil time.Time, limit int) (*[]model.Schedule, error)
+	UpdateSchedule(ctx context.Context, schedule *model.Schedule) (int64, error)
+}
+
+// About the recurring transfers and their occurrences
+type StandingOrderStore interface {
+	AddStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (*model.StandingOrder, error)
+	GetStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (*model.StandingOrder, error)
+	ListStandingOrder(ctx context.Context, standingOrder *model.StandingOrder, limit int) (*[]model.StandingOrder, error)
+	CancelStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (int64, error)
+	ClaimStandingOrderDue(ctx context.Context, lockedUntil time.Time, limit int) (*[]model.StandingOrder, error)
+	UpdateStandingOrder(ctx context.Context, tx Tx, standingOrder *model.StandingOrder) (int64, error)
+	AddStandingOrderOccurrence(ctx context.Context, tx Tx, occurrence *model.StandingOrderOccurrence) (*model.StandingOrderOccurrence, error)
+	ListStandingOrderOccurrence(ctx context.Context, standingOrder *model.StandingOrder) (*[]model.StandingOrderOccurrence, error)
+	ResolveStandingOrderOccurrence(ctx context.Context, tx Tx, occurrence *model.StandingOrderOccurrence) (int64, error)
+}
+
+// About the batches of transfers and their items
+type BatchStore interface {
+	AddTransferBatch(ctx context.Context, tx Tx, transferBatch *model.TransferBatch) (*model.TransferBatch, error)
+	UpdateTransferBatchItem(ctx context.Context, item *model.TransferBatchItem) (int64, error)
+	UpdateTransferBatch(ctx context.Context, transferBatch *model.TransferBatch) (int64, error)
+	GetTransferBatch(ctx context.Context, transferBatch *model.TransferBatch) (*model.TransferBatch, error)
+}
+
+// About the fx quotes
+type QuoteStore interface {
+	AddQuote(ctx context.Context, quote *model.Quote) (*model.Quote, error)
+	GetQuoteForUpdate(ctx context.Context, tx Tx, quote *model.Quote) (*model.Quote, error)
+	UpdateQuote(ctx context.Context, tx Tx, quote *model.Quote) (int64, error)
+}
+
+// About the transfer limits and their usage
+type LimitStore interface {
+	SetTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) (*model.TransferLimit, error)
+	ListTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) (*[]model.TransferLimit, error)
+	DeleteTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) (int64, error)
+	GetTransferLimitForUpdate(ctx context.Context, tx Tx, accountID string, tenantID string, currency string) (*[]model.TransferLimit, error)
+	GetLimitUsage(ctx context.Context, tx Tx, transferLimit *model.TransferLimit, dayStart time.Time, monthStart time.Time) (*model.LimitUsage, error)
+}
+
+// About the inputs of the risk rules and their decisions
+type RiskStore interface {
+	CountTransferSince(ctx context.Context, tx Tx, accountID string, since time.Time, roundTo *model.Money) (int, error)
+	HasTransferTo(ctx context.Context, tx Tx, accountIDFrom string, accountIDTo string) (bool, error)
+	AddRiskDecision(ctx context.Context, riskDecision *model.RiskDecision) (*model.RiskDecision, error)
+	GetRiskDecision(ctx context.Context, riskDecision *model.RiskDecision) (*model.RiskDecision, error)
+	ListRiskDecision(ctx context.Context, riskDecision *model.RiskDecision, limit int) (*[]model.RiskDecision, error)
+}
+
+// About the manual reviews of the held transfers
+type ReviewStore interface {
+	AddTransferReview(ctx context.Context, tx Tx, transferReview *model.TransferReview) (*model.TransferReview, error)
+	GetTransferReview(ctx context.Context, transferReview *model.TransferReview) (*model.TransferReview, error)
+	GetTransferReviewForUpdate(ctx context.Context, tx Tx, transferReview *model.TransferReview) (*model.TransferReview, error)
+	ListTransferReview(ctx context.Context, transferReview *model.TransferReview, limit int) (*[]model.TransferReview, error)
+	ListTransferReviewExpired(ctx context.Context, tx Tx, limit int) (*[]model.TransferReview, error)
+	UpdateTransferReview(ctx context.Context, tx Tx, transferReview *model.TransferReview) (int64, error)
+}
+
+// About the approvals (maker-checker) of the held transfers
+type ApprovalStore interface {
+	AddTransferApproval(ctx context.Context, tx Tx, transferApproval *model.TransferApproval) (*model.TransferApproval, error)
+	GetTransferApprovalForUpdate(ctx context.Context, tx Tx, transferApproval *model.TransferApproval) (*model.TransferApproval, error)
+	ListTransferApproval(ctx context.Context, transferApproval *model.TransferApproval, limit int) (*[]model.TransferApproval, error)
+	UpdateTransferApproval(ctx context.Context, tx Tx, transferApproval *model.TransferApproval) (int64, error)
+}
+
+// About the store of the transfers and of everything the use cases keep with them, all the stores in one
+// (database.WorkerRepository for postgres, memory.TransferRepository for the tests). The use cases depend
+// only on the stores of their subsystem. The methods with a Tx run in the unit of work, the others run on their own
+type TransferRepository interface {
+	UnitOfWork
+	TransferStore
+	IdempotencyStore
+	SagaStore
+	OutboxStore
+	ScheduleStore
+	StandingOrderStore
+	BatchStore
+	QuoteStore
+	LimitStore
+	RiskStore
+	ReviewStore
+	ApprovalStore
+}
@@ -0,0 +1,304 @@
+package service
+
+import(
+	"fmt"
+	"time"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+// About an outgoing transfer (transfer or debit) above the approval threshold of the limits of the source account
+// or of its tenant needs a checker, a credit never does
+func (s *WorkerService) requiresApproval(ctx context.Context, tx port.Tx, flow string, transfer *model.Transfer) (bool, error) {
+	if flow == model.FlowCreditEvent {
+		return false, nil
+	}
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.requiresApproval")
+	defer span.End()
+
+	res_list, err := s.limitStore.GetTransferLimitForUpdate(ctx, tx, transfer.AccountFrom.AccountID, transfer.AccountFrom.TenantID, transfer.Currency)
+	if err != nil {
+		return false, err
+	}
+
+	amount := outgoingAmount(transfer)
+	for i := range *res_list {
+		approval, err := (*res_list)[i].RequiresApproval(amount)
+		if err != nil {
+			return false, err
+		}
+		if approval {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// About add the approval of a transfer held as AWAITING_APPROVAL, the maker is the user who submitted it.
+// A transfer without a known maker can not go through dual control, it is refused
+func (s *WorkerService) addTransferApproval(ctx context.Context, tx port.Tx, transfer *model.Transfer, flow string) (*model.TransferApproval, error){
+	if transfer.RequestedBy == "" {
+		return nil, erro.ErrUnauthorized.WithDetail("reason", "transfer above the approval threshold without a maker (X-User-Id)")
+	}
+	transferApproval := model.TransferApproval{	FkTransferID: transfer.ID,
+												Flow: flow,
+												Transfer: transfer,
+												Maker: transfer.RequestedBy,
+												Status: model.ApprovalPending }
+	if transfer.TransactionID != nil {
+		transferApproval.TransactionID = *transfer.TransactionID
+	}
+	return s.approvalStore.AddTransferApproval(ctx, tx, &transferApproval)
+}
+
+// About hold a transfer for the approval of a second user instead of moving the money: it is stored as
+// AWAITING_APPROVAL with the flow to resume on approval, nothing is sent to go-debit/go-credit nor published
+func (s *WorkerService) holdForApproval(ctx context.Context, tx port.Tx, transfer *model.Transfer, flow string) (*model.Transfer, error){
+	childLogger.Info().Str("func","holdForApproval").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("flow", flow).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.holdForApproval")
+	defer span.End()
+
+	transfer.Status = model.StatusAwaitingApproval
+
+	// Add transfer
+	res_transfer, err := s.transferStore.AddTransfer(ctx, tx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add approval
+	_, err = s.addTransferApproval(ctx, tx, res_transfer, flow)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the response of the idempotency key
+	err = s.saveIdempotency(ctx, tx, res_transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	return res_transfer, nil
+}
+
+// About check the checker of a decision, an authenticated user with the approver role
+func (s *WorkerService) checkApprover(transferApproval *model.TransferApproval) error {
+	if transferApproval.Checker == "" {
+		return erro.ErrUnauthorized
+	}
+	for _, role := range transferApproval.CheckerRoles {
+		if role == s.approvalConfig.Role {
+			return nil
+		}
+	}
+	return erro.ErrHTTPForbiden
+}
+
+// About get the pending approval of a transfer locked
+func (s *WorkerService) lockPendingApproval(ctx context.Context, tx port.Tx, transferApproval *model.TransferApproval) (*model.TransferApproval, error){
+	res_approval, err := s.approvalStore.GetTransferApprovalForUpdate(ctx, tx, transferApproval)
+	if err != nil {
+		return nil, err
+	}
+	if res_approval.Status != model.ApprovalPending {
+		return nil, erro.ErrStatusTransition
+	}
+	return res_approval, nil
+}
+
+// About approve a transfer awaiting approval, the checker must have the approver role and must not be the maker.
+// The flow it was submitted with is resumed: the saga (debit and credit) of the REST transfer or the event of the event flows
+func (s *WorkerService) ApproveTransfer(ctx context.Context, transferApproval *model.TransferApproval) (_ *model.TransferApproval, err error){
+	childLogger.Info().Str("func","ApproveTransfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferApproval", transferApproval).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ApproveTransfer")
+	ctx = s.withTransferDeadline(ctx)
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	// Business rule
+	err = s.checkApprover(transferApproval)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Get the database connection
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Start Kafka transaction (the outbox mode only needs the database transaction)
+	err = s.beginEventTransaction(ctx)
+	if err != nil {
+		childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("failed to kafka BeginTransaction")
+		tx.Rollback(ctx)
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			childLogger.Info().Str("trace-resquest-id", trace_id ).Msg("ROLLBACK !!!!")
+			err :=  s.abortEventTransaction(ctx)
+			if err != nil {
+				childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Kafka AbortTransaction")
+			}
+			tx.Rollback(ctx)
+		} else {
+			err =  s.commitEventTransaction(ctx)
+			if err != nil {
+				childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Kafka CommitTransaction")
+				tx.Rollback(ctx)
+			} else {
+				err = tx.Commit(ctx)
+				if err != nil {
+					childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Commit, the events are already committed")
+				}
+			}
+		}
+		span.End()
+	}()
+
+	res_approval, err := s.lockPendingApproval(ctx, tx, transferApproval)
+	if err != nil {
+		return nil, err
+	}
+	// an unknown maker can not be told apart from the checker
+	if res_approval.Maker == "" || res_approval.Maker == transferApproval.Checker {
+		err = erro.ErrSelfApproval
+		return nil, err
+	}
+
+	resume, ok := heldFlowResume[res_approval.Flow]
+	if !ok || res_approval.Transfer == nil {
+		err = erro.ErrTransInvalid
+		return nil, err
+	}
+
+	transfer := res_approval.Transfer
+	transfer.ID = res_approval.FkTransferID
+
+	err = s.applyHeldTransition(ctx, tx, transfer.ID, model.StatusAwaitingApproval, resume.status, "approved by " + transferApproval.Checker)
+	if err != nil {
+		return nil, err
+	}
+
+	decided_at := time.Now()
+	res_approval.Status = model.ApprovalApproved
+	res_approval.Checker = transferApproval.Checker
+	res_approval.Reason = transferApproval.Reason
+	res_approval.DecidedAt = &decided_at
+	_, err = s.approvalStore.UpdateTransferApproval(ctx, tx, res_approval)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resume the flow
+	err = s.resumeHeldFlow(ctx, tx, res_approval.Flow, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	return res_approval, nil
+}
+
+// About reject a transfer awaiting approval with a reason, it ends as APPROVAL_REJECTED and no money is moved
+func (s *WorkerService) RejectTransfer(ctx context.Context, transferApproval *model.TransferApproval) (_ *model.TransferApproval, err error){
+	childLogger.Info().Str("func","RejectTransfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferApproval", transferApproval).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.RejectTransfer")
+
+	// Business rule
+	err = s.checkApprover(transferApproval)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	if transferApproval.Reason == "" {
+		span.End()
+		return nil, erro.ErrInvalid
+	}
+
+	// Get the database connection
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	res_approval, err := s.lockPendingApproval(ctx, tx, transferApproval)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.applyHeldTransition(ctx, tx, res_approval.FkTransferID, model.StatusAwaitingApproval, model.StatusApprovalRejected, "rejected by " + transferApproval.Checker + ": " + transferApproval.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	decided_at := time.Now()
+	res_approval.Status = model.ApprovalRejected
+	res_approval.Checker = transferApproval.Checker
+	res_approval.Reason = transferApproval.Reason
+	res_approval.DecidedAt = &decided_at
+	_, err = s.approvalStore.UpdateTransferApproval(ctx, tx, res_approval)
+	if err != nil {
+		return nil, err
+	}
+
+	return res_approval, nil
+}
+
+// About list the approval queue by status (default PENDING), the oldest first
+func (s *WorkerService) ListTransferApproval(ctx context.Context, transferApproval *model.TransferApproval, limit int) (*[]model.TransferApproval, error){
+	childLogger.Info().Str("func","ListTransferApproval").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferApproval", transferApproval).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListTransferApproval")
+	defer span.End()
+
+	// Business rule
+	switch transferApproval.Status {
+	case "":
+		transferApproval.Status = model.ApprovalPending
+	case model.ApprovalPending, model.ApprovalApproved, model.ApprovalRejected:
+	default:
+		return nil, erro.ErrStatusInvalid
+	}
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	// List approval
+	res, err := s.approvalStore.ListTransferApproval(ctx, transferApproval, limit)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
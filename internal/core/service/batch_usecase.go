@@ -0,0 +1,259 @@
+package service
+
+import(
+	"sync"
+	"time"
+	"strconv"
+	"context"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+// About validate an item of a batch before running any of them
+func validateBatchTransfer(transfer *model.Transfer) error {
+	if transfer == nil || transfer.Type != "TRANSFER" ||
+		transfer.AccountFrom == nil || transfer.AccountFrom.AccountID == "" ||
+		transfer.AccountTo == nil || transfer.AccountTo.AccountID == "" {
+		return erro.ErrTransInvalid
+	}
+	if transfer.AccountFrom.AccountID == transfer.AccountTo.AccountID {
+		return erro.ErrTransInvalid
+	}
+	if !transfer.Amount.IsPositive() {
+		return erro.ErrAmountInvalid
+	}
+	if _, err := model.CurrencyExponent(transfer.Currency); err != nil {
+		return erro.ErrCurrencyInvalid
+	}
+	return nil
+}
+
+// About add a batch of transfers. All the items are validated up front (an invalid item rejects the batch),
+// then they run as /add/transferEvent with bounded concurrency and the outcome of each one is persisted.
+// A batch resent with the same Idempotency-Key answers the batch already stored, nothing runs again
+func (s *WorkerService) AddTransferBatch(ctx context.Context, transferBatch *model.TransferBatch) (*model.TransferBatch, error){
+	childLogger.Info().Str("func","AddTransferBatch").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("total", len(transferBatch.Transfers)).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddTransferBatch")
+	defer span.End()
+
+	// Business rule
+	if len(transferBatch.Transfers) == 0 || len(transferBatch.Transfers) > s.batchConfig.MaxItems {
+		return nil, erro.ErrInvalid
+	}
+
+	transferBatch.Total = len(transferBatch.Transfers)
+	transferBatch.Items = make([]model.TransferBatchItem, transferBatch.Total)
+
+	rejected := false
+	for i, transfer := range transferBatch.Transfers {
+		item := &transferBatch.Items[i]
+		item.Sequence = i + 1
+		item.Transfer = transfer
+		item.Status = model.BatchItemPending
+
+		err := validateBatchTransfer(transfer)
+		if err != nil {
+			rejected = true
+			item.Status = model.BatchItemInvalid
+			item.ErrorCode = erro.CodeOf(err)
+			item.ErrorMessage = err.Error()
+		}
+	}
+	transferBatch.Transfers = nil
+
+	if rejected {
+		transferBatch.Status = model.BatchRejected
+		for i := range transferBatch.Items {
+			transferBatch.Items[i].Transfer = nil
+		}
+		return transferBatch, erro.ErrBatchInvalid
+	}
+
+	// Persist the batch, from here the outcome can be queried
+	transferBatch.Status = model.BatchProcessing
+
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res_replay, err := s.checkBatchIdempotency(ctx, tx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+	if res_replay != nil {
+		tx.Rollback(ctx)
+		return s.GetTransferBatch(ctx, res_replay)
+	}
+	_, err = s.batchStore.AddTransferBatch(ctx, tx, transferBatch)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+	err = s.saveBatchIdempotency(ctx, tx, transferBatch)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the items keep running when the client gives up
+	ctx_batch := context.WithoutCancel(ctx)
+
+	// a kafka transactional producer holds one transaction at a time, only the outbox mode runs items in parallel
+	concurrency := s.batchConfig.Concurrency
+	if !s.isOutbox() || concurrency < 1 {
+		concurrency = 1
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+
+	for i := range transferBatch.Items {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(item *model.TransferBatchItem) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			s.runBatchItem(ctx_batch, item)
+		}(&transferBatch.Items[i])
+	}
+	wg.Wait()
+
+	// Outcome
+	for _, item := range transferBatch.Items {
+		switch item.Status {
+		case model.BatchItemDone:
+			transferBatch.Succeeded = transferBatch.Succeeded + 1
+		case model.BatchItemHeld:
+			transferBatch.Held = transferBatch.Held + 1
+		default:
+			transferBatch.Failed = transferBatch.Failed + 1
+		}
+	}
+	switch {
+	case transferBatch.Failed == 0:
+		transferBatch.Status = model.BatchDone
+	case transferBatch.Succeeded == 0 && transferBatch.Held == 0:
+		transferBatch.Status = model.BatchFailed
+	default:
+		transferBatch.Status = model.BatchPartial
+	}
+	finished_at := time.Now()
+	transferBatch.FinishedAt = &finished_at
+
+	_, err = s.batchStore.UpdateTransferBatch(ctx_batch, transferBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	return transferBatch, nil
+}
+
+// About run an item of a batch and persist its result.
+// With an Idempotency-Key on the batch each item gets its own key, so a resent batch does not duplicate the transfers
+func (s *WorkerService) runBatchItem(ctx context.Context, item *model.TransferBatchItem) {
+	ctx_item := ctx
+	idempotency := idempotencyFromContext(ctx)
+	if idempotency != nil {
+		ctx_item = context.WithValue(ctx, "idempotency-key", &model.Idempotency{	Key: idempotency.Key + "-" + strconv.Itoa(item.Sequence),
+																					RequestHash: idempotency.RequestHash })
+	}
+
+	res_transfer, err := s.AddTransferEvent(ctx_item, item.Transfer)
+	if err != nil {
+		childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Err(err).Int("batch", item.FkBatchID).Int("sequence", item.Sequence).Msg("failed to run the batch item")
+		item.Status = model.BatchItemFailed
+		item.ErrorCode = erro.CodeOf(err)
+		item.ErrorMessage = err.Error()
+	} else {
+		item.Status = model.BatchItemDone
+		if res_transfer.Status == model.StatusPendingReview || res_transfer.Status == model.StatusAwaitingApproval {
+			item.Status = model.BatchItemHeld
+		}
+		item.FkTransferID = &res_transfer.ID
+		item.TransactionID = res_transfer.TransactionID
+	}
+	item.Transfer = nil
+
+	_, err = s.batchStore.UpdateTransferBatchItem(ctx, item)
+	if err != nil {
+		childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Err(err).Int("item", item.ID).Msg("failed to update the batch item")
+	}
+}
+
+// About reserve the Idempotency-Key of a batch, returns the batch already stored when the request is a replay
+func (s *WorkerService) checkBatchIdempotency(ctx context.Context, tx port.Tx) (*model.TransferBatch, error){
+	idempotency := idempotencyFromContext(ctx)
+	if idempotency == nil {
+		return nil, nil
+	}
+
+	inserted, err := s.idempotencyStore.AddIdempotencyKey(ctx, tx, idempotency)
+	if err != nil {
+		return nil, err
+	}
+	if inserted {
+		return nil, nil
+	}
+
+	res_idempotency, err := s.idempotencyStore.GetIdempotencyKey(ctx, tx, idempotency)
+	if err != nil {
+		return nil, err
+	}
+	if res_idempotency.RequestHash != idempotency.RequestHash {
+		return nil, erro.ErrIdempotencyKey
+	}
+
+	childLogger.Info().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("idempotency-key", idempotency.Key).Msg("replay, returning the stored batch")
+
+	var transferBatch model.TransferBatch
+	err = json.Unmarshal(res_idempotency.Response, &transferBatch)
+	if err != nil || transferBatch.ID == 0 {
+		return nil, erro.ErrUnmarshal
+	}
+	return &transferBatch, nil
+}
+
+// About store the batch (its id) as the response of the Idempotency-Key
+func (s *WorkerService) saveBatchIdempotency(ctx context.Context, tx port.Tx, transferBatch *model.TransferBatch) error{
+	idempotency := idempotencyFromContext(ctx)
+	if idempotency == nil {
+		return nil
+	}
+
+	response, err := json.Marshal(model.TransferBatch{ID: transferBatch.ID})
+	if err != nil {
+		return err
+	}
+	idempotency.Response = response
+
+	_, err = s.idempotencyStore.UpdateIdempotencyResponse(ctx, tx, idempotency)
+	return err
+}
+
+// About get a batch with the results of its items
+func (s *WorkerService) GetTransferBatch(ctx context.Context, transferBatch *model.TransferBatch) (*model.TransferBatch, error){
+	childLogger.Info().Str("func","GetTransferBatch").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferBatch", transferBatch).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.GetTransferBatch")
+	defer span.End()
+
+	// Get batch
+	res, err := s.batchStore.GetTransferBatch(ctx, transferBatch)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"time"
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/adapter/account/accounttest"
+)
+
+// About a batch of transfers from ACC-1 to each destination
+func newTestBatch(t *testing.T, accountTo ...string) *model.TransferBatch {
+	t.Helper()
+
+	transferBatch := model.TransferBatch{}
+	for _, account := range accountTo {
+		transferBatch.Transfers = append(transferBatch.Transfers, newTestTransfer(t, "ACC-1", account, 500))
+	}
+	return &transferBatch
+}
+
+// About the status of the items of a batch, in their sequence
+func batchItemStatus(transferBatch *model.TransferBatch) []string {
+	status := []string{}
+	for _, item := range transferBatch.Items {
+		status = append(status, item.Status)
+	}
+	return status
+}
+
+func TestAddTransferBatch(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	res_batch, err := ts.service.AddTransferBatch(ctx, newTestBatch(t, "ACC-2", "ACC-2"))
+	if err != nil {
+		t.Fatalf("AddTransferBatch: %v", err)
+	}
+	if res_batch.Status != model.BatchDone || res_batch.Succeeded != 2 || res_batch.Failed != 0 || res_batch.FinishedAt == nil {
+		t.Fatalf("batch = %+v, want DONE with 2 items", res_batch)
+	}
+
+	// the outcome of each item is stored
+	res_stored, err := ts.service.GetTransferBatch(ctx, &model.TransferBatch{ID: res_batch.ID})
+	if err != nil {
+		t.Fatalf("GetTransferBatch: %v", err)
+	}
+	if res_stored.Status != model.BatchDone || len(res_stored.Items) != 2 {
+		t.Fatalf("stored = %+v, want DONE with 2 items", res_stored)
+	}
+	for _, item := range res_stored.Items {
+		if item.Status != model.BatchItemDone || item.FkTransferID == nil || item.TransactionID == nil {
+			t.Errorf("item %d = %+v, want DONE with its transfer", item.Sequence, item)
+		}
+	}
+}
+
+func TestAddTransferBatchPartial(t *testing.T) {
+	tests := []struct {
+		name		string
+		accountTo	[]string
+		status		string
+		items		[]string
+	}{
+		{ "partial", []string{ "ACC-2", "ACC-9", "ACC-2" }, model.BatchPartial, []string{ model.BatchItemDone, model.BatchItemFailed, model.BatchItemDone } },
+		{ "failed", []string{ "ACC-8", "ACC-9" }, model.BatchFailed, []string{ model.BatchItemFailed, model.BatchItemFailed } },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, false)
+			ctx := context.Background()
+
+			// a failed item does not stop the next ones
+			res_batch, err := ts.service.AddTransferBatch(ctx, newTestBatch(t, tt.accountTo...))
+			if err != nil {
+				t.Fatalf("AddTransferBatch: %v", err)
+			}
+			if res_batch.Status != tt.status || res_batch.Failed + res_batch.Succeeded != len(tt.accountTo) {
+				t.Errorf("batch = %s %d/%d, want %s", res_batch.Status, res_batch.Succeeded, res_batch.Failed, tt.status)
+			}
+
+			res_stored, err := ts.service.GetTransferBatch(ctx, &model.TransferBatch{ID: res_batch.ID})
+			if err != nil {
+				t.Fatalf("GetTransferBatch: %v", err)
+			}
+			status := batchItemStatus(res_stored)
+			for i := range tt.items {
+				if i >= len(status) || status[i] != tt.items[i] {
+					t.Fatalf("items = %v, want %v", status, tt.items)
+				}
+			}
+			for _, item := range res_stored.Items {
+				if item.Status == model.BatchItemFailed && (item.ErrorCode != erro.ErrAccountNotFound.Code || item.FkTransferID != nil) {
+					t.Errorf("item %d = %+v, want the error %s and no transfer", item.Sequence, item, erro.ErrAccountNotFound.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestAddTransferBatchRejected(t *testing.T) {
+	ts := newTestService(t, false)
+
+	// an invalid item rejects the batch before any item runs
+	transferBatch := newTestBatch(t, "ACC-2", "ACC-1", "ACC-2")
+	res_batch, err := ts.service.AddTransferBatch(context.Background(), transferBatch)
+	if !errors.Is(err, erro.ErrBatchInvalid) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrBatchInvalid.Code)
+	}
+	if res_batch.Status != model.BatchRejected {
+		t.Errorf("status = %s, want %s", res_batch.Status, model.BatchRejected)
+	}
+	status := batchItemStatus(res_batch)
+	for i, want := range []string{ model.BatchItemPending, model.BatchItemInvalid, model.BatchItemPending } {
+		if i >= len(status) || status[i] != want {
+			t.Fatalf("items = %v, want the second one %s", status, model.BatchItemInvalid)
+		}
+	}
+	if ts.accounts.Calls(accounttest.ServiceAccount) != 0 {
+		t.Errorf("account calls = %d, want 0", ts.accounts.Calls(accounttest.ServiceAccount))
+	}
+
+	_, err = ts.service.AddTransferBatch(context.Background(), newTestBatch(t))
+	if !errors.Is(err, erro.ErrInvalid) {
+		t.Errorf("empty batch err = %v, want %s", err, erro.ErrInvalid.Code)
+	}
+}
+
+func TestAddTransferBatchConcurrency(t *testing.T) {
+	// each item gets its two accounts
+	const delay = 100 * time.Millisecond
+
+	tests := []struct {
+		name	string
+		outbox	bool
+		min		time.Duration
+		max		time.Duration
+	}{
+		// 4 items 2 at a time
+		{ "outbox", true, 4 * delay, 8 * delay },
+		// a kafka transaction at a time, the items run one by one
+		{ "kafka", false, 8 * delay, time.Minute },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, tt.outbox)
+			ts.service.batchConfig.Concurrency = 2
+			ts.accounts.Delay(accounttest.ServiceAccount, delay)
+
+			start := time.Now()
+			res_batch, err := ts.service.AddTransferBatch(context.Background(), newTestBatch(t, "ACC-2", "ACC-2", "ACC-2", "ACC-2"))
+			elapsed := time.Since(start)
+			if err != nil {
+				t.Fatalf("AddTransferBatch: %v", err)
+			}
+			if res_batch.Status != model.BatchDone || res_batch.Succeeded != 4 {
+				t.Fatalf("batch = %s %d, want DONE with 4 items", res_batch.Status, res_batch.Succeeded)
+			}
+			if elapsed < tt.min || elapsed >= tt.max {
+				t.Errorf("elapsed = %s, want from %s to %s", elapsed, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestAddTransferBatchReplay(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := withTestIdempotencyKey(context.Background(), "batch-1", "hash-1")
+
+	res_batch, err := ts.service.AddTransferBatch(ctx, newTestBatch(t, "ACC-2", "ACC-9"))
+	if err != nil {
+		t.Fatalf("AddTransferBatch: %v", err)
+	}
+	calls := ts.accounts.Calls(accounttest.ServiceAccount)
+
+	// the stored batch is answered, no item runs again
+	res_replay, err := ts.service.AddTransferBatch(withTestIdempotencyKey(context.Background(), "batch-1", "hash-1"), newTestBatch(t, "ACC-2", "ACC-9"))
+	if err != nil {
+		t.Fatalf("AddTransferBatch replay: %v", err)
+	}
+	if res_replay.ID != res_batch.ID || res_replay.Status != model.BatchPartial || len(res_replay.Items) != 2 {
+		t.Errorf("replay = %+v, want the batch %d", res_replay, res_batch.ID)
+	}
+	if ts.accounts.Calls(accounttest.ServiceAccount) != calls {
+		t.Errorf("account calls = %d, want %d", ts.accounts.Calls(accounttest.ServiceAccount), calls)
+	}
+
+	_, err = ts.service.AddTransferBatch(withTestIdempotencyKey(context.Background(), "batch-1", "hash-2"), newTestBatch(t, "ACC-2"))
+	if !errors.Is(err, erro.ErrIdempotencyKey) {
+		t.Errorf("err = %v, want %s", err, erro.ErrIdempotencyKey.Code)
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import(
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About the circuit breaker and the bulkhead of each downstream service
+func (s *WorkerService) ListDependencyStatus(ctx context.Context) (*[]model.DependencyStatus, error){
+	childLogger.Info().Str("func","ListDependencyStatus").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListDependencyStatus")
+	defer span.End()
+
+	res := s.accountClient.Status()
+	return &res, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import(
+	"errors"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About apply a completion event (credit done, debit done, transfer failed ...) into the transfer status.
+// An event that can not be applied (unknown type, transition not allowed) is logged and skipped returning nil,
+// its dedupe marker is committed. A transfer not found is erro.ErrEventTooEarly (retryable): in the kafka
+// transaction flows the event is committed before the transfer, so the event is retried until it shows up
+// (up to the consumer max attempts, then it is dead lettered).
+// A failed commit is returned, the event is delivered again
+func (s *WorkerService) ApplyTransferEvent(ctx context.Context, transferEvent *model.TransferEvent) (err error){
+	childLogger.Info().Str("func","ApplyTransferEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferEvent", transferEvent).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ApplyTransferEvent")
+
+	// Business rule
+	status, ok := model.StatusFromEventType(transferEvent.EventType)
+	if !ok || transferEvent.TransactionID == "" {
+		childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferEvent", transferEvent).Msg("event invalid, skipped")
+		span.End()
+		return nil
+	}
+
+	// Get the database connection
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		span.End()
+		return err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	// Dedupe, the marker is committed with the status update
+	inserted, err := s.idempotencyStore.AddProcessedEvent(ctx, tx, transferEvent)
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		childLogger.Info().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferEvent", transferEvent).Msg("event duplicated, skipped")
+		return nil
+	}
+
+	res_transfer, err := s.transferStore.GetTransferIDByTransactionID(ctx, tx, &model.Transfer{TransactionID: &transferEvent.TransactionID})
+	if err != nil {
+		if errors.Is(err, erro.ErrNotFound) {
+			childLogger.Info().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferEvent", transferEvent).Msg("transfer not found yet, event retried")
+			err = erro.ErrEventTooEarly.Wrap(err).WithDetail("transaction_id", transferEvent.TransactionID)
+		}
+		return err
+	}
+
+	reason := transferEvent.Reason
+	if reason == "" {
+		reason = "event " + transferEvent.EventType
+	}
+	statusTransition := model.StatusTransition{	FkTransferID: res_transfer.ID,
+												StatusTo: status,
+												Reason: reason }
+
+	_, err = s.applyStatusTransition(ctx, tx, &statusTransition)
+	if err != nil {
+		if errors.Is(err, erro.ErrNotFound) {
+			err = erro.ErrEventTooEarly.Wrap(err).WithDetail("transaction_id", transferEvent.TransactionID)
+			return err
+		}
+		if errors.Is(err, erro.ErrStatusTransition) {
+			// skipped for good, the dedupe marker is committed
+			childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferEvent", transferEvent).Err(err).Msg("event skipped")
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
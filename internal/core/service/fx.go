@@ -0,0 +1,181 @@
+package service
+
+import(
+	"time"
+	"errors"
+	"context"
+	"strings"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+// About the source of the conversion rates (fx.StaticRateProvider for local use)
+type RateProvider interface {
+	GetRate(ctx context.Context, currencyFrom string, currencyTo string) (*model.FxRate, error)
+}
+
+// About convert an amount at a rate, the fee (in the source currency) is taken before the conversion
+func convertAmount(amount model.Money, currencyTo string, fxRate *model.FxRate, feeBps int) (*model.FxConversion, error) {
+	fee := model.FeeOf(amount, feeBps)
+	net, err := amount.Add(fee.Neg())
+	if err != nil {
+		return nil, err
+	}
+	amountTo, err := model.ConvertMoney(net, fxRate.Rate, currencyTo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.FxConversion{	Rate: fxRate.Rate,
+								SourceCurrency: amount.Currency,
+								SourceAmount: amount,
+								DestinationCurrency: amountTo.Currency,
+								DestinationAmount: amountTo,
+								Fee: fee,
+								RateAt: fxRate.RateAt }, nil
+}
+
+// About convert the credit leg into the currency of the destination account, the debit leg stays in the transfer currency.
+// A transfer with a quote_id uses the locked rate of the quote
+func (s *WorkerService) applyFx(ctx context.Context, tx port.Tx, transfer *model.Transfer, currencyTo string) error {
+	if currencyTo == "" || strings.EqualFold(currencyTo, transfer.Currency) {
+		transfer.Fx = nil
+		if transfer.QuoteID != "" {
+			return erro.ErrQuoteInvalid
+		}
+		return nil
+	}
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.applyFx")
+	defer span.End()
+
+	var fxConversion *model.FxConversion
+	var err error
+	if transfer.QuoteID != "" {
+		fxConversion, err = s.redeemQuote(ctx, tx, transfer, currencyTo)
+	} else {
+		if s.rateProvider == nil {
+			return erro.ErrRateNotFound
+		}
+		var fxRate *model.FxRate
+		fxRate, err = s.rateProvider.GetRate(ctx, transfer.Currency, currencyTo)
+		if err != nil {
+			return err
+		}
+		fxConversion, err = convertAmount(transfer.Amount, currencyTo, fxRate, s.fxConfig.FeeBps)
+	}
+	if err != nil {
+		return err
+	}
+
+	transfer.AccountTo.Currency = fxConversion.DestinationAmount.Currency
+	transfer.AccountTo.Amount = fxConversion.DestinationAmount
+	transfer.Fx = fxConversion
+
+	childLogger.Info().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("fx", transfer.Fx).Msg("fx applied")
+
+	return nil
+}
+
+// About redeem a quote in the transaction of the transfer (a rollback frees it), it must be open, not expired
+// and match the transfer (currencies and amount)
+func (s *WorkerService) redeemQuote(ctx context.Context, tx port.Tx, transfer *model.Transfer, currencyTo string) (*model.FxConversion, error) {
+	res_quote, err := s.quoteStore.GetQuoteForUpdate(ctx, tx, &model.Quote{ID: transfer.QuoteID})
+	if errors.Is(err, erro.ErrNotFound) {
+		return nil, erro.ErrQuoteInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if res_quote.Status != model.QuoteOpen {
+		return nil, erro.ErrQuoteInvalid
+	}
+	if !time.Now().Before(res_quote.ExpiresAt) {
+		return nil, erro.ErrQuoteExpired
+	}
+	if !strings.EqualFold(res_quote.Currency, transfer.Currency) ||
+		!strings.EqualFold(res_quote.CurrencyTo, currencyTo) ||
+		res_quote.Amount != transfer.Amount {
+		return nil, erro.ErrQuoteInvalid
+	}
+
+	used_at := time.Now()
+	res_quote.Status = model.QuoteUsed
+	res_quote.UsedAt = &used_at
+	res_quote.TransactionID = transfer.TransactionID
+	_, err = s.quoteStore.UpdateQuote(ctx, tx, res_quote)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.FxConversion{	Rate: res_quote.Rate,
+								SourceCurrency: res_quote.Amount.Currency,
+								SourceAmount: res_quote.Amount,
+								DestinationCurrency: res_quote.DestinationAmount.Currency,
+								DestinationAmount: res_quote.DestinationAmount,
+								Fee: res_quote.Fee,
+								RateAt: res_quote.CreatedAt,
+								QuoteID: res_quote.ID }, nil
+}
+
+// About create a quote, the rate, fee and destination amount are locked for the quote ttl
+func (s *WorkerService) AddQuote(ctx context.Context, quote *model.Quote) (*model.Quote, error){
+	childLogger.Info().Str("func","AddQuote").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("quote", quote).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddQuote")
+	defer span.End()
+
+	// Business rule
+	if _, err := model.CurrencyExponent(quote.Currency); err != nil {
+		return nil, erro.ErrCurrencyInvalid
+	}
+	if _, err := model.CurrencyExponent(quote.CurrencyTo); err != nil {
+		return nil, erro.ErrCurrencyInvalid
+	}
+	if strings.EqualFold(quote.Currency, quote.CurrencyTo) {
+		return nil, erro.ErrCurrencyInvalid
+	}
+	if !quote.Amount.IsPositive() {
+		return nil, erro.ErrAmountInvalid
+	}
+	if s.rateProvider == nil {
+		return nil, erro.ErrRateNotFound
+	}
+
+	fxRate, err := s.rateProvider.GetRate(ctx, quote.Currency, quote.CurrencyTo)
+	if err != nil {
+		return nil, err
+	}
+	fxConversion, err := convertAmount(quote.Amount, quote.CurrencyTo, fxRate, s.fxConfig.FeeBps)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get quote UUID
+	res_uuid, err := s.transferStore.GetTransactionUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.ID = *res_uuid
+	quote.Currency = fxConversion.SourceCurrency
+	quote.CurrencyTo = fxConversion.DestinationCurrency
+	quote.Rate = fxConversion.Rate
+	quote.Fee = fxConversion.Fee
+	quote.DestinationAmount = fxConversion.DestinationAmount
+	quote.Status = model.QuoteOpen
+	quote.CreatedAt = time.Now()
+	quote.ExpiresAt = quote.CreatedAt.Add(time.Duration(s.fxConfig.QuoteTTL) * time.Second)
+
+	// Add quote
+	res, err := s.quoteStore.AddQuote(ctx, quote)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About the rates of the tests by pair (BRL/USD), a missing pair is not found
+type testRateProvider map[string]string
+
+func (p testRateProvider) GetRate(ctx context.Context, currencyFrom string, currencyTo string) (*model.FxRate, error) {
+	rate, ok := p[currencyFrom + "/" + currencyTo]
+	if !ok {
+		return nil, erro.ErrRateNotFound
+	}
+	return &model.FxRate{ CurrencyFrom: currencyFrom, CurrencyTo: currencyTo, Rate: rate, Source: "test" }, nil
+}
+
+// About a service converting BRL into USD with a fee in basis points, ACC-USD is a known account (USD)
+func newTestFxService(t *testing.T, feeBps int) *testService {
+	t.Helper()
+
+	ts := newTestService(t, false)
+	ts.accounts.AddAccount(model.Account{ AccountID: "ACC-USD", Currency: "USD", TenantID: "TENANT-1" })
+	ts.service.rateProvider = testRateProvider{ "BRL/USD": "0.185" }
+	ts.service.fxConfig = &model.FxConfig{ QuoteTTL: 60, FeeBps: feeBps }
+	return ts
+}
+
+func TestConvertAmount(t *testing.T) {
+	tests := []struct {
+		name		string
+		minor		int64
+		currency	string
+		currencyTo	string
+		rate		string
+		feeBps		int
+		fee			int64
+		minorTo		int64
+	}{
+		{ "no fee", 10000, "USD", "BRL", "5.40", 0, 0, 54000 },
+		{ "fee before the conversion", 10000, "USD", "BRL", "5.40", 25, 25, 53865 },
+		{ "fee half rounded down to even", 200, "USD", "BRL", "1", 25, 0, 200 },
+		{ "fee half rounded up to even", 600, "USD", "BRL", "1", 25, 2, 598 },
+		{ "fee below half", 100, "USD", "BRL", "1", 25, 0, 100 },
+		{ "conversion half rounded down to even", 100, "USD", "JPY", "2.5", 0, 0, 2 },
+		{ "conversion half rounded up to even", 100, "USD", "JPY", "3.5", 0, 0, 4 },
+		{ "conversion above half", 101, "USD", "JPY", "150.25", 0, 0, 152 },
+		{ "to a currency of 3 decimals", 1000, "USD", "KWD", "0.3075", 0, 0, 3075 },
+		{ "from a currency of no decimals", 1000, "JPY", "USD", "0.006655", 0, 0, 666 },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := model.NewMoney(tt.minor, tt.currency)
+			if err != nil {
+				t.Fatalf("NewMoney: %v", err)
+			}
+			fxConversion, err := convertAmount(amount, tt.currencyTo, &model.FxRate{ Rate: tt.rate }, tt.feeBps)
+			if err != nil {
+				t.Fatalf("convertAmount: %v", err)
+			}
+			if fxConversion.Fee.Minor != tt.fee || fxConversion.Fee.Currency != tt.currency {
+				t.Errorf("fee = %d %s, want %d %s", fxConversion.Fee.Minor, fxConversion.Fee.Currency, tt.fee, tt.currency)
+			}
+			if fxConversion.DestinationAmount.Minor != tt.minorTo || fxConversion.DestinationAmount.Currency != tt.currencyTo {
+				t.Errorf("destination = %d %s, want %d %s", fxConversion.DestinationAmount.Minor, fxConversion.DestinationAmount.Currency, tt.minorTo, tt.currencyTo)
+			}
+			if fxConversion.SourceAmount != amount || fxConversion.Rate != tt.rate {
+				t.Errorf("conversion = %+v, want the source amount and the rate", fxConversion)
+			}
+		})
+	}
+}
+
+func TestAddTransferFx(t *testing.T) {
+	ts := newTestFxService(t, 25)
+
+	// the debit leg stays in BRL, the credit leg is converted net of the fee: (10000 - 25) * 0.185 = 1845.375
+	res_transfer, err := ts.service.AddTransfer(context.Background(), newTestTransfer(t, "ACC-1", "ACC-USD", 10000))
+	if err != nil {
+		t.Fatalf("AddTransfer: %v", err)
+	}
+	if res_transfer.Fx == nil || res_transfer.Fx.Fee.Minor != 25 || res_transfer.Fx.DestinationAmount.Minor != 1845 {
+		t.Fatalf("fx = %+v, want a fee of 25 and 1845 USD", res_transfer.Fx)
+	}
+
+	debits, credits := ts.accounts.Debits(), ts.accounts.Credits()
+	if len(debits) != 1 || len(credits) != 1 {
+		t.Fatalf("debits = %d, credits = %d, want 1 and 1", len(debits), len(credits))
+	}
+	if debits[0].Amount.Minor != -10000 || debits[0].Amount.Currency != "BRL" {
+		t.Errorf("debit = %d %s, want -10000 BRL", debits[0].Amount.Minor, debits[0].Amount.Currency)
+	}
+	if credits[0].Amount.Minor != 1845 || credits[0].Amount.Currency != "USD" {
+		t.Errorf("credit = %d %s, want 1845 USD", credits[0].Amount.Minor, credits[0].Amount.Currency)
+	}
+}
+
+func TestAddTransferFxError(t *testing.T) {
+	tests := []struct {
+		name		string
+		accountTo	string
+		quoteID		string
+		rates		testRateProvider
+		err			error
+	}{
+		{ "rate not found", "ACC-USD", "", testRateProvider{}, erro.ErrRateNotFound },
+		{ "quote of a transfer in one currency", "ACC-2", "quote-1", testRateProvider{ "BRL/USD": "0.185" }, erro.ErrQuoteInvalid },
+		{ "unknown quote", "ACC-USD", "quote-1", testRateProvider{ "BRL/USD": "0.185" }, erro.ErrQuoteInvalid },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestFxService(t, 25)
+			ts.service.rateProvider = tt.rates
+
+			transfer := newTestTransfer(t, "ACC-1", tt.accountTo, 10000)
+			transfer.QuoteID = tt.quoteID
+			_, err := ts.service.AddTransfer(context.Background(), transfer)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %s", err, erro.AsDomain(tt.err).Code)
+			}
+			assertNoTransfer(t, ts)
+			if len(ts.accounts.Debits()) != 0 {
+				t.Errorf("debits = %d, want 0", len(ts.accounts.Debits()))
+			}
+		})
+	}
+}
+
+func TestAddTransferSameCurrency(t *testing.T) {
+	ts := newTestFxService(t, 25)
+
+	// no conversion nor fee between accounts of the same currency
+	res_transfer, err := ts.service.AddTransfer(context.Background(), newTestTransfer(t, "ACC-1", "ACC-2", 10000))
+	if err != nil {
+		t.Fatalf("AddTransfer: %v", err)
+	}
+	if res_transfer.Fx != nil || res_transfer.AccountTo.Amount.Minor != 10000 {
+		t.Errorf("transfer = %+v, want no fx and 10000 credited", res_transfer)
+	}
+}
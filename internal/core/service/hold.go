@@ -0,0 +1,119 @@
+package service
+
+import(
+	"fmt"
+	"strconv"
+	"context"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+// About the status and the event type of the flow a held transfer resumes (the REST transfer publishes no event)
+var heldFlowResume = map[string]struct{
+	status		model.TransferStatus
+	eventType	string
+}{
+	model.FlowTransferRest:		{ model.StatusTransferRestDone, "" },
+	model.FlowTransferEvent:	{ model.StatusTransferEventCreated, model.EventTypeTransfer },
+	model.FlowCreditEvent:		{ model.StatusCreditEventCreated, model.EventTypeCredit },
+	model.FlowDebitEvent:		{ model.StatusDebitEventCreated, model.EventTypeDebit },
+}
+
+// About move a held transfer out of its held status (PENDING_REVIEW or AWAITING_APPROVAL) and record the transition
+func (s *WorkerService) applyHeldTransition(ctx context.Context, tx port.Tx, transferID int, statusFrom model.TransferStatus, statusTo model.TransferStatus, reason string) error {
+	// Lock the transfer
+	res_transfer, err := s.transferStore.GetTransferForUpdate(ctx, tx, &model.Transfer{ID: transferID})
+	if err != nil {
+		return err
+	}
+	if res_transfer.Status != statusFrom {
+		return erro.ErrStatusTransition
+	}
+
+	res_transfer.Status = statusTo
+	_, err = s.transferStore.UpdateTransferStatus(ctx, tx, res_transfer)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.transferStore.AddStatusTransition(ctx, tx, &model.StatusTransition{	FkTransferID: transferID,
+																						StatusFrom: statusFrom,
+																						StatusTo: statusTo,
+																						Reason: reason })
+	if err != nil {
+		return err
+	}
+
+	// A standing order occurrence held with the transfer follows the decision (a review may still lead to an approval)
+	if statusTo == model.StatusPendingReview || statusTo == model.StatusAwaitingApproval {
+		return nil
+	}
+	occurrence := model.StandingOrderOccurrence{	FkTransferID: &transferID,
+													Status: model.OccurrenceDone }
+	switch statusTo {
+	case model.StatusReviewRejected, model.StatusReviewExpired, model.StatusApprovalRejected:
+		occurrence.Status = model.OccurrenceRejected
+		occurrence.LastError = reason
+	}
+	_, err = s.standingOrderStore.ResolveStandingOrderOccurrence(ctx, tx, &occurrence)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// About resume the flow of a released transfer: the saga (debit and credit) of the REST transfer
+// or the event of the event flows, in the transaction (database and kafka) of the decision
+func (s *WorkerService) resumeHeldFlow(ctx context.Context, tx port.Tx, flow string, transfer *model.Transfer) error {
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	resume, ok := heldFlowResume[flow]
+	if !ok {
+		return erro.ErrTransInvalid
+	}
+	transfer.Status = resume.status
+
+	if flow == model.FlowTransferRest {
+		return s.resumeTransferRest(ctx, tx, transfer)
+	}
+
+	payload_bytes, err := json.Marshal(transfer)
+	if err != nil {
+		return err
+	}
+
+	childSpanKafka := tracerProvider.Span(ctx, "workerKafka.Producer")
+	defer childSpanKafka.End()
+
+	return s.publishEvent(ctx, tx, resume.eventType, strconv.Itoa(transfer.ID), &trace_id, payload_bytes)
+}
+
+// About run the saga (debit and credit) of a released REST transfer, completed in the transaction of the decision
+func (s *WorkerService) resumeTransferRest(ctx context.Context, tx port.Tx, transfer *model.Transfer) error {
+	saga := model.Saga{	TransactionID: transfer.TransactionID,
+						Type: sagaTypeTransferRest,
+						Status: model.SagaRunning,
+						Transfer: transfer }
+	_, err := s.sagaStore.AddSaga(ctx, &saga)
+	if err != nil {
+		return err
+	}
+
+	steps := s.transferSagaSteps(transfer)
+	err = s.runSaga(ctx, &saga, steps)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.sagaStore.CompleteSaga(ctx, tx, &saga)
+	if err != nil {
+		s.compensateSaga(ctx, &saga, steps)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import(
+	"context"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+// About get the idempotency key bound by the http adapter, nil when the client did not send one
+func idempotencyFromContext(ctx context.Context) *model.Idempotency {
+	idempotency, ok := ctx.Value("idempotency-key").(*model.Idempotency)
+	if !ok || idempotency == nil || idempotency.Key == "" {
+		return nil
+	}
+	return idempotency
+}
+
+// About reserve the idempotency key, returns the original response when the request is a replay
+func (s *WorkerService) checkIdempotency(ctx context.Context, tx port.Tx) (*model.Transfer, error){
+	idempotency := idempotencyFromContext(ctx)
+	if idempotency == nil {
+		return nil, nil
+	}
+
+	inserted, err := s.idempotencyStore.AddIdempotencyKey(ctx, tx, idempotency)
+	if err != nil {
+		return nil, err
+	}
+	if inserted {
+		return nil, nil
+	}
+
+	res_idempotency, err := s.idempotencyStore.GetIdempotencyKey(ctx, tx, idempotency)
+	if err != nil {
+		return nil, err
+	}
+	if res_idempotency.RequestHash != idempotency.RequestHash {
+		return nil, erro.ErrIdempotencyKey
+	}
+
+	childLogger.Info().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("idempotency-key", idempotency.Key).Msg("replay, returning the original response")
+
+	var transfer model.Transfer
+	err = json.Unmarshal(res_idempotency.Response, &transfer)
+	if err != nil {
+		return nil, erro.ErrUnmarshal
+	}
+	return &transfer, nil
+}
+
+// About store the response of the idempotency key in the same transaction of the transfer
+func (s *WorkerService) saveIdempotency(ctx context.Context, tx port.Tx, transfer *model.Transfer) error{
+	idempotency := idempotencyFromContext(ctx)
+	if idempotency == nil {
+		return nil
+	}
+
+	response, err := json.Marshal(transfer)
+	if err != nil {
+		return err
+	}
+	idempotency.Response = response
+
+	_, err = s.idempotencyStore.UpdateIdempotencyResponse(ctx, tx, idempotency)
+	return err
+}
@@ -0,0 +1,123 @@
+package service
+
+import(
+	"time"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+// About check the outgoing amount of a transfer (or debit) against the limits of the account and of its tenant,
+// in the transaction of the transfer. A breach returns a model.LimitExceededError (erro.ErrLimitExceeded) with the remaining allowance
+func (s *WorkerService) checkLimits(ctx context.Context, tx port.Tx, transfer *model.Transfer, tenantID string) error {
+	// Trace
+	span := tracerProvider.Span(ctx, "service.checkLimits")
+	defer span.End()
+
+	amount := outgoingAmount(transfer)
+
+	res_list, err := s.limitStore.GetTransferLimitForUpdate(ctx, tx, transfer.AccountFrom.AccountID, tenantID, transfer.Currency)
+	if err != nil {
+		return err
+	}
+	if len(*res_list) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	for i := range *res_list {
+		transferLimit := &(*res_list)[i]
+
+		res_usage, err := s.limitStore.GetLimitUsage(ctx, tx, transferLimit, dayStart, monthStart)
+		if err != nil {
+			return err
+		}
+		breach, err := transferLimit.Check(amount, res_usage)
+		if err != nil {
+			return err
+		}
+		if breach != nil {
+			childLogger.Info().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("breach", breach).Msg("transfer limit exceeded")
+			return &model.LimitExceededError{Breach: breach}
+		}
+	}
+
+	return nil
+}
+
+// About add or replace the limit of an account or a tenant in a currency, a zero value means no limit
+func (s *WorkerService) SetTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) (*model.TransferLimit, error){
+	childLogger.Info().Str("func","SetTransferLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferLimit", transferLimit).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.SetTransferLimit")
+	defer span.End()
+
+	// Business rule
+	if transferLimit.Scope != model.LimitScopeAccount && transferLimit.Scope != model.LimitScopeTenant {
+		return nil, erro.ErrInvalid
+	}
+	if transferLimit.ScopeID == "" {
+		return nil, erro.ErrInvalid
+	}
+	if _, err := model.CurrencyExponent(transferLimit.Currency); err != nil {
+		return nil, erro.ErrCurrencyInvalid
+	}
+	transferLimit.Currency = model.NormalizeCurrency(transferLimit.Currency)
+	if transferLimit.MaxPerTransaction.IsNegative() || transferLimit.DailyAmount.IsNegative() || transferLimit.MonthlyAmount.IsNegative() ||
+		transferLimit.ApprovalThreshold.IsNegative() {
+		return nil, erro.ErrAmountInvalid
+	}
+	if transferLimit.DailyCount < 0 || transferLimit.MonthlyCount < 0 {
+		return nil, erro.ErrInvalid
+	}
+
+	// Set limit
+	res, err := s.limitStore.SetTransferLimit(ctx, transferLimit)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About list the limits of an account or a tenant
+func (s *WorkerService) ListTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) (*[]model.TransferLimit, error){
+	childLogger.Info().Str("func","ListTransferLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferLimit", transferLimit).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListTransferLimit")
+	defer span.End()
+
+	// Business rule
+	if transferLimit.Scope != model.LimitScopeAccount && transferLimit.Scope != model.LimitScopeTenant {
+		return nil, erro.ErrInvalid
+	}
+
+	// List limit
+	res, err := s.limitStore.ListTransferLimit(ctx, transferLimit)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About delete the limit of an account or a tenant in a currency
+func (s *WorkerService) DeleteTransferLimit(ctx context.Context, transferLimit *model.TransferLimit) error{
+	childLogger.Info().Str("func","DeleteTransferLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferLimit", transferLimit).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.DeleteTransferLimit")
+	defer span.End()
+
+	// Delete limit
+	_, err := s.limitStore.DeleteTransferLimit(ctx, transferLimit)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About set a limit of the account ACC-1, the amounts in minor units of BRL
+func setTestLimit(t *testing.T, ts *testService, currency string, maxPerTransaction int64, dailyAmount int64) {
+	t.Helper()
+
+	_, err := ts.service.SetTransferLimit(context.Background(), &model.TransferLimit{	Scope: model.LimitScopeAccount,
+																						ScopeID: "ACC-1",
+																						Currency: currency,
+																						MaxPerTransaction: newTestMoney(t, maxPerTransaction),
+																						DailyAmount: newTestMoney(t, dailyAmount) })
+	if err != nil {
+		t.Fatalf("SetTransferLimit: %v", err)
+	}
+}
+
+// About force the status of a stored transfer, as the completion events or a reversal would
+func setTestStatus(t *testing.T, ts *testService, transferID int, status model.TransferStatus) {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := ts.repository.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	_, err = ts.repository.UpdateTransferStatus(ctx, tx, &model.Transfer{ ID: transferID, Status: status })
+	if err != nil {
+		t.Fatalf("UpdateTransferStatus: %v", err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+}
+
+func TestLimitCurrencyCase(t *testing.T) {
+	tests := []struct {
+		name			string
+		limitCurrency	string
+		currency		string
+	}{
+		{ "limit upper, transfer lower", "BRL", "brl" },
+		{ "limit lower, transfer upper", "brl", "BRL" },
+		{ "limit lower, transfer lower", "brl", "brl" },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, false)
+			setTestLimit(t, ts, tt.limitCurrency, 100, 0)
+
+			// the limit applies whatever the case of the currency
+			transfer := newTestTransfer(t, "ACC-1", "ACC-2", 500)
+			transfer.Currency = tt.currency
+			_, err := ts.service.AddTransfer(context.Background(), transfer)
+			if !errors.Is(err, erro.ErrLimitExceeded) {
+				t.Errorf("err = %v, want %s", err, erro.ErrLimitExceeded.Code)
+			}
+		})
+	}
+}
+
+func TestLimitUsage(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+	setTestLimit(t, ts, "BRL", 0, 1000)
+
+	// a credit is not counted, even once its status left the credit ones
+	res_credit, err := ts.service.CreditTransferEvent(ctx, newTestStatement(t, "ACC-1", 900))
+	if err != nil {
+		t.Fatalf("CreditTransferEvent: %v", err)
+	}
+	setTestStatus(t, ts, res_credit.ID, model.StatusTransferDone)
+
+	res_transfer, err := ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 500))
+	if err != nil {
+		t.Fatalf("AddTransfer: %v", err)
+	}
+
+	// 500 used of 1000
+	_, err = ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 600))
+	if !errors.Is(err, erro.ErrLimitExceeded) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrLimitExceeded.Code)
+	}
+
+	// a reversed transfer gives its allowance back
+	setTestStatus(t, ts, res_transfer.ID, model.StatusTransferReversed)
+	_, err = ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 600))
+	if err != nil {
+		t.Errorf("AddTransfer after the reversal: %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import(
+	"time"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+// About check if the events go through the outbox instead of a kafka transaction
+func (s *WorkerService) isOutbox() bool {
+	return s.outboxConfig != nil && s.outboxConfig.Enabled
+}
+
+// About begin the transaction of the event publisher, in outbox mode the database transaction is enough
+func (s *WorkerService) beginEventTransaction(ctx context.Context) error {
+	if s.isOutbox() {
+		return nil
+	}
+	return s.eventPublisher.BeginTransaction(ctx)
+}
+
+// About commit the transaction of the event publisher
+func (s *WorkerService) commitEventTransaction(ctx context.Context) error {
+	if s.isOutbox() {
+		return nil
+	}
+	return s.eventPublisher.CommitTransaction(ctx)
+}
+
+// About abort the transaction of the event publisher
+func (s *WorkerService) abortEventTransaction(ctx context.Context) error {
+	if s.isOutbox() {
+		return nil
+	}
+	return s.eventPublisher.AbortTransaction(ctx)
+}
+
+// About publish an event to the topic of its type, in outbox mode it is stored in the transaction of the transfer
+// and published by the relay
+func (s *WorkerService) publishEvent(ctx context.Context, tx port.Tx, eventType string, key string, trace_id *string, payload []byte) error {
+	topic, err := s.eventRouting.Topic(eventType)
+	if err != nil {
+		return err
+	}
+
+	if !s.isOutbox() {
+		return s.eventPublisher.Producer(ctx, topic, eventType, key, trace_id, payload)
+	}
+
+	outbox := model.Outbox{	Topic: topic,
+							EventType: eventType,
+							Key: key,
+							Payload: payload }
+	if trace_id != nil {
+		outbox.TraceID = *trace_id
+	}
+	_, err = s.outboxStore.AddOutbox(ctx, tx, &outbox)
+	return err
+}
+
+type OutboxRelay struct {
+	unitOfWork			port.UnitOfWork
+	outboxStore			port.OutboxStore
+	publisher			port.EventPublisher
+	outboxConfig		*model.OutboxConfig
+}
+
+func NewOutboxRelay(unitOfWork port.UnitOfWork,
+					outboxStore port.OutboxStore,
+					publisher port.EventPublisher,
+					outboxConfig *model.OutboxConfig) *OutboxRelay{
+	childLogger.Info().Str("func","NewOutboxRelay").Send()
+
+	return &OutboxRelay{
+		unitOfWork: unitOfWork,
+		outboxStore: outboxStore,
+		publisher: publisher,
+		outboxConfig: outboxConfig,
+	}
+}
+
+// About publish a batch of pending events and mark them as sent. A failed event keeps its attempts and its error
+// and is retried by the next batch, the batch moves on with the events of other keys (the events of the key of a
+// failed event wait, to keep their order). A failed commit is returned, the events are published again (at least once)
+func (o *OutboxRelay) Relay(ctx context.Context) (sent int, err error){
+	childLogger.Debug().Str("func","Relay").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.OutboxRelay.Relay")
+
+	// Get the database connection
+	tx, err := o.unitOfWork.Begin(ctx)
+	if err != nil {
+		span.End()
+		return 0, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err != nil {
+				childLogger.Error().Err(err).Int("sent", sent).Msg("failed to commit the outbox, the events will be published again")
+			}
+		}
+		span.End()
+	}()
+
+	res_list, err := o.outboxStore.ListOutboxPending(ctx, tx, o.outboxConfig.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	failed_keys := map[string]bool{}
+	for i := range *res_list {
+		outbox := &(*res_list)[i]
+		if failed_keys[outbox.Key] {
+			continue
+		}
+		outbox.Attempts = outbox.Attempts + 1
+
+		trace_id := outbox.TraceID
+		errPublish := o.publisher.Producer(ctx, outbox.Topic, outbox.EventType, outbox.Key, &trace_id, outbox.Payload)
+		if errPublish != nil {
+			childLogger.Error().Str("trace-resquest-id", trace_id).Err(errPublish).Int("outbox", outbox.ID).Msg("failed to relay the event")
+			outbox.LastError = errPublish.Error()
+			_, err = o.outboxStore.UpdateOutbox(ctx, tx, outbox)
+			if err != nil {
+				return sent, err
+			}
+			failed_keys[outbox.Key] = true
+			continue
+		}
+
+		sent_at := time.Now()
+		outbox.Status = model.OutboxSent
+		outbox.LastError = ""
+		outbox.SentAt = &sent_at
+		_, err = o.outboxStore.UpdateOutbox(ctx, tx, outbox)
+		if err != nil {
+			return sent, err
+		}
+		sent = sent + 1
+	}
+
+	return sent, nil
+}
+
+// About run the relay periodically, a full batch is followed at once by the next one
+func (o *OutboxRelay) OutboxRelayWorker(ctx context.Context) {
+	childLogger.Info().Str("func","OutboxRelayWorker").Send()
+
+	ticker := time.NewTicker(time.Duration(o.outboxConfig.RelayInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		sent, err := o.Relay(ctx)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("failed to relay the outbox")
+		}
+		if err == nil && sent == o.outboxConfig.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
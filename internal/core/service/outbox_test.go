@@ -0,0 +1,236 @@
+package service
+
+import (
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About the pending rows of the outbox
+func listOutboxPending(t *testing.T, ts *testService) []model.Outbox {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := ts.repository.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	res_list, err := ts.repository.ListOutboxPending(ctx, tx, 100)
+	if err != nil {
+		t.Fatalf("ListOutboxPending: %v", err)
+	}
+	return *res_list
+}
+
+// About a credit and a debit event stored in the outbox, in this order
+func addOutboxEvents(t *testing.T, ts *testService) {
+	t.Helper()
+
+	ctx := context.WithValue(context.Background(), "trace-request-id", "trace-1")
+	_, err := ts.service.CreditTransferEvent(ctx, newTestStatement(t, "ACC-1", 100))
+	if err != nil {
+		t.Fatalf("CreditTransferEvent: %v", err)
+	}
+	_, err = ts.service.DebitTransferEvent(ctx, newTestStatement(t, "ACC-1", -100))
+	if err != nil {
+		t.Fatalf("DebitTransferEvent: %v", err)
+	}
+}
+
+func TestOutboxStoresEvents(t *testing.T) {
+	ts := newTestService(t, true)
+	addOutboxEvents(t, ts)
+
+	// nothing is published in the transaction of the transfer
+	if len(ts.publisher.Published()) != 0 {
+		t.Errorf("published = %d, want 0", len(ts.publisher.Published()))
+	}
+
+	res_list := listOutboxPending(t, ts)
+	if len(res_list) != 2 {
+		t.Fatalf("pending = %d, want 2", len(res_list))
+	}
+	for i, eventType := range []string{ model.EventTypeCredit, model.EventTypeDebit } {
+		if res_list[i].EventType != eventType || res_list[i].Topic != testEventRouting[eventType] || res_list[i].TraceID != "trace-1" {
+			t.Errorf("outbox %d = %s %s %s, want %s %s trace-1", i, res_list[i].EventType, res_list[i].Topic, res_list[i].TraceID, eventType, testEventRouting[eventType])
+		}
+	}
+}
+
+func TestOutboxRelay(t *testing.T) {
+	ts := newTestService(t, true)
+	addOutboxEvents(t, ts)
+	outboxRelay := NewOutboxRelay(ts.repository, ts.repository, ts.publisher, ts.outboxConfig)
+	ctx := context.Background()
+
+	sent, err := outboxRelay.Relay(ctx)
+	if err != nil {
+		t.Fatalf("Relay: %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("sent = %d, want 2", sent)
+	}
+
+	// published in the order they were stored, and marked as sent
+	published := ts.publisher.Published()
+	if len(published) != 2 {
+		t.Fatalf("published = %d, want 2", len(published))
+	}
+	for i, eventType := range []string{ model.EventTypeCredit, model.EventTypeDebit } {
+		if published[i].EventType != eventType || published[i].Topic != testEventRouting[eventType] || published[i].TraceID != "trace-1" {
+			t.Errorf("message %d = %s %s %s, want %s %s trace-1", i, published[i].EventType, published[i].Topic, published[i].TraceID, eventType, testEventRouting[eventType])
+		}
+	}
+	if len(listOutboxPending(t, ts)) != 0 {
+		t.Errorf("pending = %d, want 0", len(listOutboxPending(t, ts)))
+	}
+
+	// a sent row is not published again
+	sent, err = outboxRelay.Relay(ctx)
+	if err != nil || sent != 0 {
+		t.Errorf("relay again = %d, %v, want 0 and no error", sent, err)
+	}
+	if len(ts.publisher.Published()) != 2 {
+		t.Errorf("published = %d, want 2", len(ts.publisher.Published()))
+	}
+}
+
+func TestOutboxRelayBatchSize(t *testing.T) {
+	ts := newTestService(t, true)
+	ts.outboxConfig.BatchSize = 1
+	addOutboxEvents(t, ts)
+	outboxRelay := NewOutboxRelay(ts.repository, ts.repository, ts.publisher, ts.outboxConfig)
+
+	sent, err := outboxRelay.Relay(context.Background())
+	if err != nil || sent != 1 {
+		t.Fatalf("relay = %d, %v, want 1 and no error", sent, err)
+	}
+
+	// the oldest first, the next one waits for the next batch
+	res_list := listOutboxPending(t, ts)
+	if len(res_list) != 1 || res_list[0].EventType != model.EventTypeDebit {
+		t.Errorf("pending = %+v, want the debit", res_list)
+	}
+	published := ts.publisher.Published()
+	if len(published) != 1 || published[0].EventType != model.EventTypeCredit {
+		t.Errorf("published = %+v, want the credit", published)
+	}
+}
+
+func TestOutboxRelayFailed(t *testing.T) {
+	ts := newTestService(t, true)
+	addOutboxEvents(t, ts)
+	outboxRelay := NewOutboxRelay(ts.repository, ts.repository, ts.publisher, ts.outboxConfig)
+	ctx := context.Background()
+
+	// each row keeps its attempt and its error
+	ts.publisher.Err = errors.New("broker unavailable")
+	sent, err := outboxRelay.Relay(ctx)
+	if err != nil || sent != 0 {
+		t.Fatalf("relay = %d, %v, want 0 and no error", sent, err)
+	}
+
+	res_list := listOutboxPending(t, ts)
+	if len(res_list) != 2 {
+		t.Fatalf("pending = %d, want 2", len(res_list))
+	}
+	for _, outbox := range res_list {
+		if outbox.Attempts != 1 || outbox.LastError != "broker unavailable" {
+			t.Errorf("outbox %d = attempts %d error %q, want 1 and the publish error", outbox.ID, outbox.Attempts, outbox.LastError)
+		}
+	}
+
+	// published by the next relay once the broker is back
+	ts.publisher.Err = nil
+	sent, err = outboxRelay.Relay(ctx)
+	if err != nil || sent != 2 {
+		t.Fatalf("relay = %d, %v, want 2 and no error", sent, err)
+	}
+	if len(listOutboxPending(t, ts)) != 0 || len(ts.publisher.Published()) != 2 {
+		t.Errorf("pending = %d, published = %d, want 0 and 2", len(listOutboxPending(t, ts)), len(ts.publisher.Published()))
+	}
+}
+
+func TestOutboxRelaySkipsFailed(t *testing.T) {
+	ts := newTestService(t, true)
+	outboxRelay := NewOutboxRelay(ts.repository, ts.repository, ts.publisher, ts.outboxConfig)
+	ctx := context.Background()
+
+	// two events of the key 1, then one of the key 2
+	tx, err := ts.repository.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	for _, key := range []string{ "1", "1", "2" } {
+		_, err = ts.repository.AddOutbox(ctx, tx, &model.Outbox{ Topic: "topic.credit", EventType: model.EventTypeCredit, Key: key, Payload: []byte(`{}`) })
+		if err != nil {
+			t.Fatalf("AddOutbox: %v", err)
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	// the failed event does not hold the other keys, the next event of its key waits
+	ts.publisher.KeyErr = map[string]error{ "1": errors.New("record too large") }
+	sent, err := outboxRelay.Relay(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("relay = %d, %v, want 1 and no error", sent, err)
+	}
+	published := ts.publisher.Published()
+	if len(published) != 1 || published[0].Key != "2" {
+		t.Fatalf("published = %+v, want the key 2", published)
+	}
+
+	res_list := listOutboxPending(t, ts)
+	if len(res_list) != 2 {
+		t.Fatalf("pending = %d, want 2", len(res_list))
+	}
+	for i, attempts := range []int{ 1, 0 } {
+		if res_list[i].Key != "1" || res_list[i].Attempts != attempts {
+			t.Errorf("outbox %d = key %s attempts %d, want key 1 attempts %d", i, res_list[i].Key, res_list[i].Attempts, attempts)
+		}
+	}
+
+	// published in their order once the key is accepted
+	ts.publisher.KeyErr = nil
+	sent, err = outboxRelay.Relay(ctx)
+	if err != nil || sent != 2 {
+		t.Fatalf("relay = %d, %v, want 2 and no error", sent, err)
+	}
+	published = ts.publisher.Published()
+	for i, key := range []string{ "2", "1", "1" } {
+		if published[i].Key != key {
+			t.Errorf("message %d key = %s, want %s", i, published[i].Key, key)
+		}
+	}
+}
+
+func TestOutboxRelayCommitFailed(t *testing.T) {
+	ts := newTestService(t, true)
+	addOutboxEvents(t, ts)
+	outboxRelay := NewOutboxRelay(ts.repository, ts.repository, ts.publisher, ts.outboxConfig)
+
+	// the rows are not marked sent, the events are published again by the next relay
+	ts.repository.CommitErr = errors.New("connection lost")
+	_, err := outboxRelay.Relay(context.Background())
+	if err == nil || err.Error() != "connection lost" {
+		t.Fatalf("err = %v, want the commit error", err)
+	}
+	ts.repository.CommitErr = nil
+
+	res_list := listOutboxPending(t, ts)
+	if len(res_list) != 2 {
+		t.Fatalf("pending = %d, want 2", len(res_list))
+	}
+	for _, outbox := range res_list {
+		if outbox.Attempts != 0 {
+			t.Errorf("outbox %d attempts = %d, want 0", outbox.ID, outbox.Attempts)
+		}
+	}
+}
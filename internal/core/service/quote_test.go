@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"context"
+	"testing"
+	"net/http"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/adapter/account/accounttest"
+)
+
+// About a quote of BRL 100.00 into USD
+func addTestQuote(t *testing.T, ts *testService) *model.Quote {
+	t.Helper()
+
+	res_quote, err := ts.service.AddQuote(context.Background(), &model.Quote{ Currency: "BRL", Amount: newTestMoney(t, 10000), CurrencyTo: "USD" })
+	if err != nil {
+		t.Fatalf("AddQuote: %v", err)
+	}
+	return res_quote
+}
+
+// About the stored state of a quote
+func getTestQuote(t *testing.T, ts *testService, id string) *model.Quote {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := ts.repository.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	res_quote, err := ts.repository.GetQuoteForUpdate(ctx, tx, &model.Quote{ID: id})
+	if err != nil {
+		t.Fatalf("GetQuoteForUpdate: %v", err)
+	}
+	return res_quote
+}
+
+// About a transfer of BRL 100.00 from ACC-1 to ACC-USD redeeming a quote
+func newTestQuoteTransfer(t *testing.T, quoteID string) *model.Transfer {
+	t.Helper()
+
+	transfer := newTestTransfer(t, "ACC-1", "ACC-USD", 10000)
+	transfer.QuoteID = quoteID
+	return transfer
+}
+
+func TestAddQuote(t *testing.T) {
+	ts := newTestFxService(t, 25)
+
+	res_quote := addTestQuote(t, ts)
+	if res_quote.ID == "" || res_quote.Status != model.QuoteOpen || res_quote.Rate != "0.185" {
+		t.Fatalf("quote = %+v, want an open quote at 0.185", res_quote)
+	}
+	if res_quote.Fee.Minor != 25 || res_quote.DestinationAmount.Minor != 1845 || res_quote.DestinationAmount.Currency != "USD" {
+		t.Errorf("quote = %+v, want a fee of 25 and 1845 USD", res_quote)
+	}
+	if res_quote.ExpiresAt.Sub(res_quote.CreatedAt).Seconds() != 60 {
+		t.Errorf("quote expires after %s, want the ttl of 60s", res_quote.ExpiresAt.Sub(res_quote.CreatedAt))
+	}
+}
+
+func TestAddQuoteError(t *testing.T) {
+	tests := []struct {
+		name	string
+		quote	model.Quote
+		err		error
+	}{
+		{ "same currency", model.Quote{ Currency: "BRL", CurrencyTo: "BRL" }, erro.ErrCurrencyInvalid },
+		{ "unknown currency", model.Quote{ Currency: "BRL", CurrencyTo: "XXX" }, erro.ErrCurrencyInvalid },
+		{ "zero amount", model.Quote{ Currency: "BRL", CurrencyTo: "USD" }, erro.ErrAmountInvalid },
+		{ "rate not found", model.Quote{ Currency: "BRL", CurrencyTo: "EUR" }, erro.ErrRateNotFound },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestFxService(t, 25)
+			if tt.name != "zero amount" {
+				tt.quote.Amount = newTestMoney(t, 10000)
+			}
+			_, err := ts.service.AddQuote(context.Background(), &tt.quote)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %s", err, erro.AsDomain(tt.err).Code)
+			}
+		})
+	}
+}
+
+func TestQuoteRedeemedOnce(t *testing.T) {
+	ts := newTestFxService(t, 25)
+	ctx := context.Background()
+	res_quote := addTestQuote(t, ts)
+
+	// the locked rate applies even when the rate moved
+	ts.service.rateProvider = testRateProvider{ "BRL/USD": "0.200" }
+	res_transfer, err := ts.service.AddTransfer(ctx, newTestQuoteTransfer(t, res_quote.ID))
+	if err != nil {
+		t.Fatalf("AddTransfer: %v", err)
+	}
+	if res_transfer.Fx == nil || res_transfer.Fx.QuoteID != res_quote.ID || res_transfer.Fx.DestinationAmount.Minor != 1845 {
+		t.Fatalf("fx = %+v, want the quote and 1845 USD", res_transfer.Fx)
+	}
+
+	res_used := getTestQuote(t, ts, res_quote.ID)
+	if res_used.Status != model.QuoteUsed || res_used.UsedAt == nil || *res_used.TransactionID != *res_transfer.TransactionID {
+		t.Errorf("quote = %+v, want used by the transfer %s", res_used, *res_transfer.TransactionID)
+	}
+
+	// a used quote is not redeemed again
+	_, err = ts.service.AddTransfer(ctx, newTestQuoteTransfer(t, res_quote.ID))
+	if !errors.Is(err, erro.ErrQuoteInvalid) {
+		t.Errorf("err = %v, want %s", err, erro.ErrQuoteInvalid.Code)
+	}
+	if len(ts.accounts.Credits()) != 1 {
+		t.Errorf("credits = %d, want 1", len(ts.accounts.Credits()))
+	}
+}
+
+func TestQuoteFreedByFailedTransfer(t *testing.T) {
+	ts := newTestFxService(t, 25)
+	ctx := context.Background()
+	res_quote := addTestQuote(t, ts)
+
+	// the redemption is rolled back with the transfer
+	ts.accounts.Respond(accounttest.ServiceDebit, http.StatusBadRequest, `{"msg":"rejected"}`)
+	_, err := ts.service.AddTransfer(ctx, newTestQuoteTransfer(t, res_quote.ID))
+	if !errors.Is(err, erro.ErrStatementRejected) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrStatementRejected.Code)
+	}
+	if getTestQuote(t, ts, res_quote.ID).Status != model.QuoteOpen {
+		t.Fatalf("quote = %+v, want open", getTestQuote(t, ts, res_quote.ID))
+	}
+
+	ts.accounts.Restore(accounttest.ServiceDebit)
+	_, err = ts.service.AddTransfer(ctx, newTestQuoteTransfer(t, res_quote.ID))
+	if err != nil {
+		t.Fatalf("AddTransfer: %v", err)
+	}
+}
+
+func TestQuoteExpired(t *testing.T) {
+	ts := newTestFxService(t, 25)
+
+	// a quote of no ttl expires at once
+	ts.service.fxConfig.QuoteTTL = 0
+	res_quote := addTestQuote(t, ts)
+
+	_, err := ts.service.AddTransfer(context.Background(), newTestQuoteTransfer(t, res_quote.ID))
+	if !errors.Is(err, erro.ErrQuoteExpired) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrQuoteExpired.Code)
+	}
+	assertNoTransfer(t, ts)
+	if getTestQuote(t, ts, res_quote.ID).Status != model.QuoteOpen {
+		t.Errorf("quote = %+v, want open", getTestQuote(t, ts, res_quote.ID))
+	}
+}
+
+func TestQuoteMismatch(t *testing.T) {
+	tests := []struct {
+		name		string
+		accountTo	string
+		minor		int64
+	}{
+		{ "other amount", "ACC-USD", 9999 },
+		{ "other currency", "ACC-EUR", 10000 },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestFxService(t, 25)
+			ts.accounts.AddAccount(model.Account{ AccountID: "ACC-EUR", Currency: "EUR", TenantID: "TENANT-1" })
+			res_quote := addTestQuote(t, ts)
+
+			transfer := newTestTransfer(t, "ACC-1", tt.accountTo, tt.minor)
+			transfer.QuoteID = res_quote.ID
+			_, err := ts.service.AddTransfer(context.Background(), transfer)
+			if !errors.Is(err, erro.ErrQuoteInvalid) {
+				t.Errorf("err = %v, want %s", err, erro.ErrQuoteInvalid.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,444 @@
+package service
+
+import(
+	"fmt"
+	"time"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+// About hold a transfer for a manual review instead of moving the money: it is stored as PENDING_REVIEW
+// with the flow to resume on approval, nothing is sent to go-debit/go-credit nor published
+func (s *WorkerService) holdForReview(ctx context.Context, tx port.Tx, transfer *model.Transfer, flow string, riskDecision *model.RiskDecision) (*model.Transfer, error){
+	childLogger.Info().Str("func","holdForReview").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("flow", flow).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.holdForReview")
+	defer span.End()
+
+	transfer.Status = model.StatusPendingReview
+
+	// Add transfer
+	res_transfer, err := s.transferStore.AddTransfer(ctx, tx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add review
+	transferReview := model.TransferReview{	FkTransferID: res_transfer.ID,
+											Flow: flow,
+											Transfer: res_transfer,
+											Reasons: riskDecision.Reasons,
+											Status: model.ReviewPending,
+											ExpiresAt: time.Now().Add(time.Duration(s.reviewConfig.TTL) * time.Second) }
+	if res_transfer.TransactionID != nil {
+		transferReview.TransactionID = *res_transfer.TransactionID
+	}
+	_, err = s.reviewStore.AddTransferReview(ctx, tx, &transferReview)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the response of the idempotency key
+	err = s.saveIdempotency(ctx, tx, res_transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	return res_transfer, nil
+}
+
+// About get a pending review locked, it must not be expired
+func (s *WorkerService) lockPendingReview(ctx context.Context, tx port.Tx, transferReview *model.TransferReview) (*model.TransferReview, error){
+	res_review, err := s.reviewStore.GetTransferReviewForUpdate(ctx, tx, transferReview)
+	if err != nil {
+		return nil, err
+	}
+	if res_review.Status != model.ReviewPending {
+		return nil, erro.ErrStatusTransition
+	}
+	if !time.Now().Before(res_review.ExpiresAt) {
+		return nil, erro.ErrReviewExpired
+	}
+	return res_review, nil
+}
+
+// About check the reviewer, an authenticated user with the reviewer role
+func (s *WorkerService) checkReviewer(transferReview *model.TransferReview) error {
+	if transferReview.Reviewer == "" {
+		return erro.ErrUnauthorized
+	}
+	for _, role := range transferReview.ReviewerRoles {
+		if role == s.reviewConfig.Role {
+			return nil
+		}
+	}
+	return erro.ErrHTTPForbiden
+}
+
+// About approve a held transfer, the flow it came from is resumed: the saga (debit and credit) of the REST
+// transfer or the event of the event flows. A transfer above the approval threshold moves to AWAITING_APPROVAL instead.
+// The reviewer and the decision are recorded with the transfer
+func (s *WorkerService) ApproveReview(ctx context.Context, transferReview *model.TransferReview) (_ *model.TransferReview, err error){
+	childLogger.Info().Str("func","ApproveReview").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferReview", transferReview).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ApproveReview")
+	ctx = s.withTransferDeadline(ctx)
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+
+	// Business rule
+	err = s.checkReviewer(transferReview)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Get the database connection
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Start Kafka transaction (the outbox mode only needs the database transaction)
+	err = s.beginEventTransaction(ctx)
+	if err != nil {
+		childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("failed to kafka BeginTransaction")
+		tx.Rollback(ctx)
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			childLogger.Info().Str("trace-resquest-id", trace_id ).Msg("ROLLBACK !!!!")
+			err :=  s.abortEventTransaction(ctx)
+			if err != nil {
+				childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Kafka AbortTransaction")
+			}
+			tx.Rollback(ctx)
+		} else {
+			err =  s.commitEventTransaction(ctx)
+			if err != nil {
+				childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Kafka CommitTransaction")
+				tx.Rollback(ctx)
+			} else {
+				err = tx.Commit(ctx)
+				if err != nil {
+					childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Commit, the events are already committed")
+				}
+			}
+		}
+		span.End()
+	}()
+
+	res_review, err := s.lockPendingReview(ctx, tx, transferReview)
+	if err != nil {
+		return nil, err
+	}
+	resume, ok := heldFlowResume[res_review.Flow]
+	if !ok || res_review.Transfer == nil {
+		err = erro.ErrTransInvalid
+		return nil, err
+	}
+
+	transfer := res_review.Transfer
+	transfer.ID = res_review.FkTransferID
+
+	decided_at := time.Now()
+	res_review.Status = model.ReviewApproved
+	res_review.Reviewer = transferReview.Reviewer
+	res_review.Reason = transferReview.Reason
+	res_review.DecidedAt = &decided_at
+	_, err = s.reviewStore.UpdateTransferReview(ctx, tx, res_review)
+	if err != nil {
+		return nil, err
+	}
+
+	// A transfer above the approval threshold still needs a checker
+	var approval bool
+	approval, err = s.requiresApproval(ctx, tx, res_review.Flow, transfer)
+	if err != nil {
+		return nil, err
+	}
+	if approval {
+		err = s.applyHeldTransition(ctx, tx, transfer.ID, model.StatusPendingReview, model.StatusAwaitingApproval, "approved by " + transferReview.Reviewer + ", awaiting approval")
+		if err != nil {
+			return nil, err
+		}
+		transfer.Status = model.StatusAwaitingApproval
+		_, err = s.addTransferApproval(ctx, tx, transfer, res_review.Flow)
+		if err != nil {
+			return nil, err
+		}
+		return res_review, nil
+	}
+
+	err = s.applyHeldTransition(ctx, tx, transfer.ID, model.StatusPendingReview, resume.status, "approved by " + transferReview.Reviewer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resume the flow
+	err = s.resumeHeldFlow(ctx, tx, res_review.Flow, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	return res_review, nil
+}
+
+// About reject a held transfer with a reason, it ends as REVIEW_REJECTED and no money is moved
+func (s *WorkerService) RejectReview(ctx context.Context, transferReview *model.TransferReview) (_ *model.TransferReview, err error){
+	childLogger.Info().Str("func","RejectReview").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferReview", transferReview).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.RejectReview")
+
+	// Business rule
+	err = s.checkReviewer(transferReview)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	if transferReview.Reason == "" {
+		span.End()
+		return nil, erro.ErrInvalid
+	}
+
+	// Get the database connection
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	res_review, err := s.lockPendingReview(ctx, tx, transferReview)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.applyHeldTransition(ctx, tx, res_review.FkTransferID, model.StatusPendingReview, model.StatusReviewRejected, "rejected by " + transferReview.Reviewer + ": " + transferReview.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	decided_at := time.Now()
+	res_review.Status = model.ReviewRejected
+	res_review.Reviewer = transferReview.Reviewer
+	res_review.Reason = transferReview.Reason
+	res_review.DecidedAt = &decided_at
+	_, err = s.reviewStore.UpdateTransferReview(ctx, tx, res_review)
+	if err != nil {
+		return nil, err
+	}
+
+	return res_review, nil
+}
+
+// About get a review with the held transfer and the reasons
+func (s *WorkerService) GetTransferReview(ctx context.Context, transferReview *model.TransferReview) (*model.TransferReview, error){
+	childLogger.Info().Str("func","GetTransferReview").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferReview", transferReview).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.GetTransferReview")
+	defer span.End()
+
+	// Business rule
+	err := s.checkReviewer(transferReview)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get review
+	res, err := s.reviewStore.GetTransferReview(ctx, transferReview)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About list the review queue by status (default PENDING), the oldest first
+func (s *WorkerService) ListTransferReview(ctx context.Context, transferReview *model.TransferReview, limit int) (*[]model.TransferReview, error){
+	childLogger.Info().Str("func","ListTransferReview").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferReview", transferReview).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListTransferReview")
+	defer span.End()
+
+	// Business rule
+	err := s.checkReviewer(transferReview)
+	if err != nil {
+		return nil, err
+	}
+	switch transferReview.Status {
+	case "":
+		transferReview.Status = model.ReviewPending
+	case model.ReviewPending, model.ReviewApproved, model.ReviewRejected, model.ReviewExpired:
+	default:
+		return nil, erro.ErrStatusInvalid
+	}
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	// List review
+	res, err := s.reviewStore.ListTransferReview(ctx, transferReview, limit)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About expire a batch of reviews left pending after the review ttl, the transfers end as REVIEW_EXPIRED.
+// Every review is expired in its own transaction, a failing one is logged and closed as EXPIRED with the failure
+// as reason (the transfer stays held for reconciliation) so it does not block the next ones. Returns the reviews handled
+func (s *WorkerService) ExpireReview(ctx context.Context, reviewConfig *model.ReviewConfig) (int, error){
+	childLogger.Debug().Str("func","ExpireReview").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ExpireReview")
+	defer span.End()
+
+	// Get the batch, each review is locked again by its own transaction
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	res_list, err := s.reviewStore.ListTransferReviewExpired(ctx, tx, reviewConfig.BatchSize)
+	if err != nil {
+		tx.Rollback(ctx)
+		return 0, err
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range *res_list {
+		transferReview := &(*res_list)[i]
+
+		err = s.expireOneReview(ctx, transferReview)
+		if err == nil {
+			continue
+		}
+		childLogger.Error().Int("review", transferReview.ID).Int("transfer", transferReview.FkTransferID).Err(err).Msg("failed to expire the review")
+
+		err = s.markReviewExpiryFailed(ctx, transferReview, err)
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(*res_list), nil
+}
+
+// About expire a review and its held transfer in a transaction, a review decided meanwhile is left as is
+func (s *WorkerService) expireOneReview(ctx context.Context, transferReview *model.TransferReview) (err error){
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	res_review, err := s.reviewStore.GetTransferReviewForUpdate(ctx, tx, transferReview)
+	if err != nil {
+		return err
+	}
+	if res_review.Status != model.ReviewPending {
+		return nil
+	}
+
+	err = s.applyHeldTransition(ctx, tx, res_review.FkTransferID, model.StatusPendingReview, model.StatusReviewExpired, "review expired")
+	if err != nil {
+		return err
+	}
+
+	decided_at := time.Now()
+	res_review.Status = model.ReviewExpired
+	res_review.DecidedAt = &decided_at
+	_, err = s.reviewStore.UpdateTransferReview(ctx, tx, res_review)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// About close a review whose expiry failed, the failure is kept as its reason
+func (s *WorkerService) markReviewExpiryFailed(ctx context.Context, transferReview *model.TransferReview, failure error) (err error){
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	decided_at := time.Now()
+	transferReview.Status = model.ReviewExpired
+	transferReview.Reason = "expiry failed: " + failure.Error()
+	transferReview.DecidedAt = &decided_at
+	_, err = s.reviewStore.UpdateTransferReview(ctx, tx, transferReview)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// About run the review expiry periodically, a full batch is followed at once by the next one
+func (s *WorkerService) ReviewExpiryWorker(ctx context.Context, reviewConfig *model.ReviewConfig) {
+	childLogger.Info().Str("func","ReviewExpiryWorker").Send()
+
+	ticker := time.NewTicker(time.Duration(reviewConfig.ExpiryInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		count, err := s.ExpireReview(ctx, reviewConfig)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("failed to expire the reviews")
+		}
+		if err == nil && count == reviewConfig.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import(
+	"time"
+	"strconv"
+	"context"
+	"strings"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+// About a rule type, it tells whether the transfer matches the rule and why
+type riskRuleFunc func(ctx context.Context, s *WorkerService, tx port.Tx, rule *model.RiskRule, transfer *model.Transfer) (bool, string, error)
+
+// About the rule types, a new type is a function plus its parameters in model.RiskRule.Validate
+var riskRuleFuncs = map[string]riskRuleFunc{
+	model.RiskRuleVelocity:			velocityRule,
+	model.RiskRuleNewDestination:	newDestinationRule,
+	model.RiskRuleRoundAmountBurst:	roundAmountBurstRule,
+	model.RiskRuleBlocklist:		blocklistRule,
+}
+
+// About the absolute amount of a transfer (a debit is negative)
+func outgoingAmount(transfer *model.Transfer) model.Money {
+	if transfer.Amount.IsNegative() {
+		return transfer.Amount.Neg()
+	}
+	return transfer.Amount
+}
+
+// About more than max_count movements from the account in window_minutes (this one included)
+func velocityRule(ctx context.Context, s *WorkerService, tx port.Tx, rule *model.RiskRule, transfer *model.Transfer) (bool, string, error) {
+	since := time.Now().Add(-time.Duration(rule.WindowMinutes) * time.Minute)
+	count, err := s.riskStore.CountTransferSince(ctx, tx, transfer.AccountFrom.AccountID, since, nil)
+	if err != nil {
+		return false, "", err
+	}
+	if count + 1 <= rule.MaxCount {
+		return false, "", nil
+	}
+	return true, strconv.Itoa(count + 1) + " transfers in " + strconv.Itoa(rule.WindowMinutes) + " minutes (max " + strconv.Itoa(rule.MaxCount) + ")", nil
+}
+
+// About an amount from min_amount to a destination never paid before by the account
+func newDestinationRule(ctx context.Context, s *WorkerService, tx port.Tx, rule *model.RiskRule, transfer *model.Transfer) (bool, string, error) {
+	if transfer.AccountTo == nil || transfer.AccountTo.AccountID == "" || transfer.AccountTo.AccountID == transfer.AccountFrom.AccountID {
+		return false, "", nil
+	}
+	below, err := outgoingAmount(transfer).Add(rule.MinAmount.Neg())
+	if err != nil {
+		return false, "", err
+	}
+	if below.IsNegative() {
+		return false, "", nil
+	}
+	exists, err := s.riskStore.HasTransferTo(ctx, tx, transfer.AccountFrom.AccountID, transfer.AccountTo.AccountID)
+	if err != nil {
+		return false, "", err
+	}
+	if exists {
+		return false, "", nil
+	}
+	return true, "first transfer to " + transfer.AccountTo.AccountID + " with amount " + outgoingAmount(transfer).String() + " (from " + rule.MinAmount.String() + ")", nil
+}
+
+// About a round amount (multiple of round_to) after max_count round amounts in window_minutes
+func roundAmountBurstRule(ctx context.Context, s *WorkerService, tx port.Tx, rule *model.RiskRule, transfer *model.Transfer) (bool, string, error) {
+	amount := outgoingAmount(transfer)
+	if amount.Currency != rule.RoundTo.Currency || amount.Minor % rule.RoundTo.Minor != 0 {
+		return false, "", nil
+	}
+	since := time.Now().Add(-time.Duration(rule.WindowMinutes) * time.Minute)
+	count, err := s.riskStore.CountTransferSince(ctx, tx, transfer.AccountFrom.AccountID, since, &rule.RoundTo)
+	if err != nil {
+		return false, "", err
+	}
+	if count + 1 <= rule.MaxCount {
+		return false, "", nil
+	}
+	return true, strconv.Itoa(count + 1) + " amounts multiple of " + rule.RoundTo.String() + " in " + strconv.Itoa(rule.WindowMinutes) + " minutes (max " + strconv.Itoa(rule.MaxCount) + ")", nil
+}
+
+// About the source or the destination account is blocklisted
+func blocklistRule(ctx context.Context, s *WorkerService, tx port.Tx, rule *model.RiskRule, transfer *model.Transfer) (bool, string, error) {
+	for _, account := range rule.Accounts {
+		if strings.EqualFold(account, transfer.AccountFrom.AccountID) {
+			return true, "account " + transfer.AccountFrom.AccountID + " is blocklisted", nil
+		}
+		if transfer.AccountTo != nil && strings.EqualFold(account, transfer.AccountTo.AccountID) {
+			return true, "account " + transfer.AccountTo.AccountID + " is blocklisted", nil
+		}
+	}
+	return false, "", nil
+}
+
+// About run the risk rules on a transfer in its transaction, the most severe decision of the matched rules wins.
+// The decision is stored by transaction_id (also when denied), a DENY returns a model.RiskDeniedError (erro.ErrRiskDenied)
+func (s *WorkerService) evaluateRisk(ctx context.Context, tx port.Tx, transfer *model.Transfer, operation string) (*model.RiskDecision, error) {
+	if len(s.riskRules) == 0 {
+		return &model.RiskDecision{Decision: model.RiskAllow}, nil
+	}
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.evaluateRisk")
+	defer span.End()
+
+	riskDecision := model.RiskDecision{	Operation: operation,
+										AccountFrom: transfer.AccountFrom.AccountID,
+										Currency: transfer.Currency,
+										Amount: transfer.Amount,
+										Decision: model.RiskAllow,
+										Reasons: []model.RiskReason{} }
+	if transfer.TransactionID != nil {
+		riskDecision.TransactionID = *transfer.TransactionID
+	}
+	if transfer.AccountTo != nil && transfer.AccountTo.AccountID != transfer.AccountFrom.AccountID {
+		riskDecision.AccountTo = transfer.AccountTo.AccountID
+	}
+
+	for i := range s.riskRules {
+		rule := &s.riskRules[i]
+		if !rule.Applies(operation, transfer.Currency) {
+			continue
+		}
+		ruleFunc, ok := riskRuleFuncs[rule.Type]
+		if !ok {
+			return nil, erro.ErrInvalid
+		}
+		matched, reason, err := ruleFunc(ctx, s, tx, rule, transfer)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+		riskDecision.Reasons = append(riskDecision.Reasons, model.RiskReason{	Rule: rule.Name,
+																				Type: rule.Type,
+																				Decision: rule.Decision,
+																				Reason: reason })
+		riskDecision.Decision = model.RiskSeverest(riskDecision.Decision, rule.Decision)
+	}
+
+	_, err := s.riskStore.AddRiskDecision(ctx, &riskDecision)
+	if err != nil {
+		return nil, err
+	}
+
+	if riskDecision.Decision != model.RiskAllow {
+		childLogger.Info().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("riskDecision", riskDecision).Msg("risk rules matched")
+	}
+	if riskDecision.Decision == model.RiskDeny {
+		return &riskDecision, &model.RiskDeniedError{Decision: &riskDecision}
+	}
+
+	return &riskDecision, nil
+}
+
+// About get the risk decision of a transfer by its transaction_id
+func (s *WorkerService) GetRiskDecision(ctx context.Context, riskDecision *model.RiskDecision) (*model.RiskDecision, error){
+	childLogger.Info().Str("func","GetRiskDecision").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("riskDecision", riskDecision).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.GetRiskDecision")
+	defer span.End()
+
+	// Get risk decision
+	res, err := s.riskStore.GetRiskDecision(ctx, riskDecision)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About list the latest risk decisions (default DENY) for the analysts
+func (s *WorkerService) ListRiskDecision(ctx context.Context, riskDecision *model.RiskDecision, limit int) (*[]model.RiskDecision, error){
+	childLogger.Info().Str("func","ListRiskDecision").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("riskDecision", riskDecision).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListRiskDecision")
+	defer span.End()
+
+	// Business rule
+	switch riskDecision.Decision {
+	case "":
+		riskDecision.Decision = model.RiskDeny
+	case model.RiskAllow, model.RiskReview, model.RiskDeny:
+	default:
+		return nil, erro.ErrInvalid
+	}
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	// List risk decision
+	res, err := s.riskStore.ListRiskDecision(ctx, riskDecision, limit)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
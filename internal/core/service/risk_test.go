@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/adapter/account/accounttest"
+)
+
+// About set the risk rules of the service, as loaded from RISK_RULE_FILE
+func setTestRiskRules(t *testing.T, ts *testService, rules ...model.RiskRule) {
+	t.Helper()
+
+	for i := range rules {
+		err := rules[i].Validate()
+		if err != nil {
+			t.Fatalf("Validate %s: %v", rules[i].Name, err)
+		}
+	}
+	ts.service.riskRules = rules
+}
+
+// About the decision stored for a denied transfer
+func assertRiskDenied(t *testing.T, ts *testService, err error, rules ...string) {
+	t.Helper()
+
+	var riskDenied *model.RiskDeniedError
+	if !errors.Is(err, erro.ErrRiskDenied) || !errors.As(err, &riskDenied) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrRiskDenied.Code)
+	}
+
+	// the decision is kept when the transfer rolls back
+	res_risk, err := ts.service.GetRiskDecision(context.Background(), &model.RiskDecision{TransactionID: riskDenied.Decision.TransactionID})
+	if err != nil {
+		t.Fatalf("GetRiskDecision: %v", err)
+	}
+	if res_risk.Decision != model.RiskDeny || len(res_risk.Reasons) != len(rules) {
+		t.Fatalf("decision = %+v, want DENY by %v", res_risk, rules)
+	}
+	for i, rule := range rules {
+		if res_risk.Reasons[i].Rule != rule {
+			t.Errorf("reason %d = %s, want %s", i, res_risk.Reasons[i].Rule, rule)
+		}
+	}
+}
+
+func TestRiskVelocity(t *testing.T) {
+	ts := newTestService(t, false)
+	setTestRiskRules(t, ts, model.RiskRule{ Name: "velocity", Type: model.RiskRuleVelocity, Decision: model.RiskDeny, MaxCount: 2, WindowMinutes: 60 })
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		res_transfer, err := ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 500))
+		if err != nil {
+			t.Fatalf("AddTransfer %d: %v", i, err)
+		}
+		res_risk, err := ts.service.GetRiskDecision(ctx, &model.RiskDecision{TransactionID: *res_transfer.TransactionID})
+		if err != nil || res_risk.Decision != model.RiskAllow {
+			t.Fatalf("decision %d = %+v, %v, want ALLOW", i, res_risk, err)
+		}
+	}
+
+	// the third one in the window is denied, the other account is not counted
+	_, err := ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 500))
+	assertRiskDenied(t, ts, err, "velocity")
+	if ts.accounts.Calls(accounttest.ServiceDebit) != 2 {
+		t.Errorf("debit calls = %d, want 2", ts.accounts.Calls(accounttest.ServiceDebit))
+	}
+
+	_, err = ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-2", "ACC-1", 500))
+	if err != nil {
+		t.Errorf("AddTransfer from ACC-2: %v", err)
+	}
+}
+
+func TestRiskNewDestination(t *testing.T) {
+	ts := newTestService(t, false)
+	setTestRiskRules(t, ts, model.RiskRule{ Name: "new-destination", Type: model.RiskRuleNewDestination, Decision: model.RiskReview, MinAmount: newTestMoney(t, 50000) })
+	ctx := context.Background()
+
+	// a large amount to a destination never paid is held for review
+	res_transfer, err := ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 50000))
+	if err != nil {
+		t.Fatalf("AddTransfer: %v", err)
+	}
+	if res_transfer.Status != model.StatusPendingReview || ts.accounts.Calls(accounttest.ServiceDebit) != 0 {
+		t.Fatalf("status = %s, debit calls = %d, want %s and 0", res_transfer.Status, ts.accounts.Calls(accounttest.ServiceDebit), model.StatusPendingReview)
+	}
+
+	// a held transfer does not make the destination known
+	res_transfer, err = ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 49999))
+	if err != nil || res_transfer.Status == model.StatusPendingReview {
+		t.Fatalf("transfer = %+v, %v, want it below the amount and sent", res_transfer, err)
+	}
+
+	// once paid, a large amount to the destination is allowed
+	res_transfer, err = ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 50000))
+	if err != nil || res_transfer.Status == model.StatusPendingReview {
+		t.Errorf("transfer = %+v, %v, want it sent", res_transfer, err)
+	}
+}
+
+func TestRiskRoundAmountBurst(t *testing.T) {
+	ts := newTestService(t, false)
+	setTestRiskRules(t, ts, model.RiskRule{ Name: "round-burst", Type: model.RiskRuleRoundAmountBurst, Decision: model.RiskDeny, RoundTo: newTestMoney(t, 10000), MaxCount: 1, WindowMinutes: 60 })
+	ctx := context.Background()
+
+	// an amount not round is not counted nor matched
+	for _, minor := range []int64{ 10000, 10050 } {
+		_, err := ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", minor))
+		if err != nil {
+			t.Fatalf("AddTransfer %d: %v", minor, err)
+		}
+	}
+
+	_, err := ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 20000))
+	assertRiskDenied(t, ts, err, "round-burst")
+	if ts.accounts.Calls(accounttest.ServiceDebit) != 2 {
+		t.Errorf("debit calls = %d, want 2", ts.accounts.Calls(accounttest.ServiceDebit))
+	}
+}
+
+func TestRiskBlocklist(t *testing.T) {
+	tests := []struct {
+		name	string
+		rule	model.RiskRule
+		denied	bool
+	}{
+		{ "destination", model.RiskRule{ Accounts: []string{ "acc-2" } }, true },
+		{ "source", model.RiskRule{ Accounts: []string{ "ACC-1" } }, true },
+		{ "other account", model.RiskRule{ Accounts: []string{ "ACC-3" } }, false },
+		{ "other operation", model.RiskRule{ Accounts: []string{ "ACC-2" }, Operations: []string{ model.RiskOperationCredit } }, false },
+		{ "other currency", model.RiskRule{ Accounts: []string{ "ACC-2" }, Currency: "USD" }, false },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, false)
+			tt.rule.Name = "blocklist"
+			tt.rule.Type = model.RiskRuleBlocklist
+			tt.rule.Decision = model.RiskDeny
+			setTestRiskRules(t, ts, tt.rule)
+
+			_, err := ts.service.AddTransfer(context.Background(), newTestTransfer(t, "ACC-1", "ACC-2", 500))
+			if tt.denied {
+				assertRiskDenied(t, ts, err, "blocklist")
+				assertNoTransfer(t, ts)
+				return
+			}
+			if err != nil {
+				t.Errorf("AddTransfer: %v", err)
+			}
+		})
+	}
+}
+
+func TestRiskSeverest(t *testing.T) {
+	ts := newTestService(t, false)
+	setTestRiskRules(t, ts,	model.RiskRule{ Name: "new-destination", Type: model.RiskRuleNewDestination, Decision: model.RiskReview, MinAmount: newTestMoney(t, 100) },
+							model.RiskRule{ Name: "blocklist", Type: model.RiskRuleBlocklist, Decision: model.RiskDeny, Accounts: []string{ "ACC-2" } })
+
+	// both rules are recorded, the DENY wins over the REVIEW
+	_, err := ts.service.AddTransfer(context.Background(), newTestTransfer(t, "ACC-1", "ACC-2", 500))
+	assertRiskDenied(t, ts, err, "new-destination", "blocklist")
+	assertNoTransfer(t, ts)
+}
+
+func TestRiskDebit(t *testing.T) {
+	ts := newTestService(t, false)
+	setTestRiskRules(t, ts, model.RiskRule{ Name: "velocity", Type: model.RiskRuleVelocity, Decision: model.RiskDeny, Operations: []string{ model.RiskOperationDebit }, MaxCount: 1, WindowMinutes: 60 })
+	ctx := context.Background()
+
+	_, err := ts.service.DebitTransferEvent(ctx, newTestStatement(t, "ACC-1", -100))
+	if err != nil {
+		t.Fatalf("DebitTransferEvent: %v", err)
+	}
+	_, err = ts.service.DebitTransferEvent(ctx, newTestStatement(t, "ACC-1", -100))
+	if !errors.Is(err, erro.ErrRiskDenied) {
+		t.Errorf("err = %v, want %s", err, erro.ErrRiskDenied.Code)
+	}
+}
@@ -0,0 +1,331 @@
+package service
+
+import(
+	"fmt"
+	"time"
+	"errors"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+const sagaTypeTransferRest = "TRANSFER-REST"
+
+// About a saga step, compensate undoes an executed step
+type sagaStep struct {
+	name		string
+	execute		func(ctx context.Context) error
+	compensate	func(ctx context.Context) error
+}
+
+// About the steps of the REST transfer (debit then credit), rebuilt from the persisted transfer on recovery
+func (s *WorkerService) transferSagaSteps(transfer *model.Transfer) []sagaStep {
+	return []sagaStep{
+		{	name: "DEBIT",
+			execute: func(ctx context.Context) error {
+				return s.postDebit(ctx, transfer.AccountFrom)
+			},
+			// a reversing credit on the source account
+			compensate: func(ctx context.Context) error {
+				return s.postCredit(ctx, reverseStatement(transfer.AccountFrom))
+			},
+		},
+		{	name: "CREDIT",
+			execute: func(ctx context.Context) error {
+				return s.postCredit(ctx, transfer.AccountTo)
+			},
+			// a reversing debit on the destination account
+			compensate: func(ctx context.Context) error {
+				return s.postDebit(ctx, reverseStatement(transfer.AccountTo))
+			},
+		},
+	}
+}
+
+// About get an account from go-account, bounded by the deadline of the transfer
+func (s *WorkerService) getAccount(ctx context.Context, accountID string) (*model.Account, error) {
+	ctx, cancel := accountContext(ctx)
+	defer cancel()
+
+	return s.accountClient.GetAccount(ctx, accountID)
+}
+
+// About post (add) a debit statement into go-debit, the answer is not used by the saga
+func (s *WorkerService) postDebit(ctx context.Context, accountStatement *model.AccountStatement) error {
+	ctx, cancel := accountContext(ctx)
+	defer cancel()
+
+	_, err := s.accountClient.PostDebit(ctx, withIdempotencyKey(accountStatement))
+	return err
+}
+
+// About post (add) a credit statement into go-credit
+func (s *WorkerService) postCredit(ctx context.Context, accountStatement *model.AccountStatement) error {
+	ctx, cancel := accountContext(ctx)
+	defer cancel()
+
+	_, err := s.accountClient.PostCredit(ctx, withIdempotencyKey(accountStatement))
+	return err
+}
+
+// About a copy of the statement keyed by its transaction, type, account and reversal. The same step of a
+// saga (recovery included) sends the same key, the client retries it only against an idempotent endpoint
+func withIdempotencyKey(accountStatement *model.AccountStatement) *model.AccountStatement {
+	keyed := *accountStatement
+	if keyed.TransactionID != nil && keyed.IdempotencyKey == "" {
+		keyed.IdempotencyKey = fmt.Sprintf("%s:%s:%s", *keyed.TransactionID, keyed.Type, keyed.AccountID)
+		if keyed.Obs == "REVERSAL" {
+			keyed.IdempotencyKey = keyed.IdempotencyKey + ":REVERSAL"
+		}
+	}
+	return &keyed
+}
+
+// About create the statement that undoes another one
+func reverseStatement(accountStatement *model.AccountStatement) *model.AccountStatement {
+	reverse := *accountStatement
+	reverse.ID = 0
+	reverse.Amount = accountStatement.Amount.Neg()
+	reverse.ChargeAt = time.Now()
+	reverse.Obs = "REVERSAL"
+	if accountStatement.Type == "DEBIT" {
+		reverse.Type = "CREDIT"
+	} else {
+		reverse.Type = "DEBIT"
+	}
+	return &reverse
+}
+
+// About an error of a step that does not tell if the statement was applied: a timeout, an unavailable service,
+// a 5xx after the retries, a canceled call or a non domain error. A call refused before it was sent (circuit open,
+// bulkhead full) was not applied
+func unknownOutcome(err error) bool {
+	if errors.Is(err, erro.ErrCircuitOpen) || errors.Is(err, erro.ErrBulkheadFull) {
+		return false
+	}
+	domainError := erro.AsDomain(err)
+	return domainError == nil || domainError.Retryable
+}
+
+// About run the saga steps in order, when a step fails the executed ones are compensated in reverse order.
+// A step with an unknown outcome stays PENDING and the saga RUNNING: the recovery replays the step with the same
+// idempotency key and compensates the saga. Each step touches the saga so the recovery does not claim it while
+// it runs, a saga already claimed is left to the recovery
+func (s *WorkerService) runSaga(ctx context.Context, saga *model.Saga, steps []sagaStep) error {
+	childLogger.Info().Str("func","runSaga").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("saga", saga.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.runSaga")
+	defer span.End()
+
+	for i, step := range steps {
+		_, err := s.sagaStore.TouchSaga(ctx, saga)
+		if err != nil {
+			childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("saga", saga.ID).Err(err).Msg("saga not touched, left to the recovery")
+			return err
+		}
+
+		sagaStep := model.SagaStep{	FkSagaID: saga.ID,
+									Name: step.name,
+									Action: model.SagaStepExecute,
+									Status: model.SagaStepPending }
+		_, err = s.sagaStore.AddSagaStep(ctx, &sagaStep)
+		if err != nil {
+			s.compensateSaga(ctx, saga, steps[:i])
+			return err
+		}
+
+		err = step.execute(ctx)
+		if err != nil && unknownOutcome(err) {
+			childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("saga", saga.ID).Str("step", step.name).Err(err).Msg("saga step outcome unknown, left to the recovery")
+			return err
+		}
+		if err != nil {
+			sagaStep.Status = model.SagaStepFailed
+			sagaStep.Error = err.Error()
+			if _, errStep := s.sagaStore.UpdateSagaStep(ctx, &sagaStep); errStep != nil {
+				childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Err(errStep).Msg("failed to record the saga step")
+			}
+			s.compensateSaga(ctx, saga, steps[:i])
+			return err
+		}
+
+		sagaStep.Status = model.SagaStepDone
+		_, err = s.sagaStore.UpdateSagaStep(ctx, &sagaStep)
+		if err != nil {
+			// the step was executed, so it is compensated as well
+			s.compensateSaga(ctx, saga, steps[:i+1])
+			return err
+		}
+	}
+
+	return nil
+}
+
+// About compensate the executed steps in reverse order, it keeps going when a compensation fails
+func (s *WorkerService) compensateSaga(ctx context.Context, saga *model.Saga, executed []sagaStep) {
+	childLogger.Info().Str("func","compensateSaga").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("saga", saga.ID).Send()
+
+	// the compensation must finish even when the client request is canceled or the transfer deadline passed
+	ctx = context.WithValue(context.WithoutCancel(ctx), "transfer-deadline", nil)
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.compensateSaga")
+	defer span.End()
+
+	saga.Status = model.SagaCompensating
+	if _, err := s.sagaStore.UpdateSaga(ctx, saga); err != nil {
+		childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Err(err).Msg("failed to update the saga")
+	}
+
+	saga.Status = model.SagaCompensated
+	for i := len(executed) - 1; i >= 0; i-- {
+		sagaStep := model.SagaStep{	FkSagaID: saga.ID,
+									Name: executed[i].name,
+									Action: model.SagaStepCompensate,
+									Status: model.SagaStepPending }
+		if _, err := s.sagaStore.AddSagaStep(ctx, &sagaStep); err != nil {
+			childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Err(err).Msg("failed to record the saga step")
+		}
+
+		sagaStep.Status = model.SagaStepDone
+		err := executed[i].compensate(ctx)
+		if err != nil {
+			childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Err(err).Str("step", executed[i].name).Msg("COMPENSATION FAILED !!!!")
+			sagaStep.Status = model.SagaStepFailed
+			sagaStep.Error = err.Error()
+			saga.Status = model.SagaCompensationFailed
+		}
+
+		if sagaStep.ID != 0 {
+			if _, err := s.sagaStore.UpdateSagaStep(ctx, &sagaStep); err != nil {
+				childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Err(err).Msg("failed to record the saga step")
+			}
+		}
+	}
+
+	if _, err := s.sagaStore.UpdateSaga(ctx, saga); err != nil {
+		childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Err(err).Msg("failed to update the saga")
+	}
+}
+
+// About resume the sagas left unfinished by a crash.
+// The transfer transaction was rolled back, so the confirmed steps are compensated. A step still PENDING
+// has an unknown outcome, it is replayed first with the same idempotency key: go-debit/go-credit answer the
+// stored statement when it was applied, or apply it now, then it is compensated like the others. A replay
+// failing with a retryable error leaves the saga to the next recovery, a non retryable one means the step was not applied
+func (s *WorkerService) RecoverSaga(ctx context.Context, sagaConfig *model.SagaConfig) error {
+	childLogger.Info().Str("func","RecoverSaga").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.RecoverSaga")
+	defer span.End()
+
+	olderThan := time.Now().Add(-time.Duration(sagaConfig.RecoveryAge) * time.Second)
+	res_list, err := s.sagaStore.ClaimSagaRecovery(ctx, olderThan, 10)
+	if err != nil {
+		return err
+	}
+
+	for i := range *res_list {
+		saga := &(*res_list)[i]
+		ctx_saga := context.WithValue(ctx, "trace-request-id", fmt.Sprintf("saga-%v", saga.ID))
+		if saga.TransactionID != nil {
+			ctx_saga = context.WithValue(ctx, "trace-request-id", *saga.TransactionID)
+		}
+		ctx_saga = s.withTransferDeadline(ctx_saga)
+
+		if saga.Type != sagaTypeTransferRest || saga.Transfer == nil {
+			childLogger.Error().Interface("trace-resquest-id", ctx_saga.Value("trace-request-id")).Int("saga", saga.ID).Msg("saga type not recoverable")
+			continue
+		}
+
+		res_steps, err := s.sagaStore.ListSagaStep(ctx_saga, saga)
+		if err != nil {
+			childLogger.Error().Interface("trace-resquest-id", ctx_saga.Value("trace-request-id")).Err(err).Send()
+			continue
+		}
+
+		// the executed steps not yet compensated, and the executions with an unknown outcome
+		executedName := map[string]bool{}
+		pendingStep := map[string]model.SagaStep{}
+		for _, step := range *res_steps {
+			switch {
+			case step.Action == model.SagaStepExecute && step.Status == model.SagaStepDone:
+				executedName[step.Name] = true
+				delete(pendingStep, step.Name)
+			case step.Action == model.SagaStepExecute && step.Status == model.SagaStepPending:
+				pendingStep[step.Name] = step
+			case step.Action == model.SagaStepCompensate && step.Status == model.SagaStepDone:
+				delete(executedName, step.Name)
+			}
+		}
+
+		executed := []sagaStep{}
+		resolved := true
+		for _, step := range s.transferSagaSteps(saga.Transfer) {
+			if pending, ok := pendingStep[step.name]; ok && !executedName[step.name] {
+				applied, err := s.replaySagaStep(ctx_saga, step, &pending)
+				if err != nil {
+					childLogger.Error().Interface("trace-resquest-id", ctx_saga.Value("trace-request-id")).Int("saga", saga.ID).Str("step", step.name).Err(err).Msg("saga step unresolved, left to the next recovery")
+					resolved = false
+					break
+				}
+				executedName[step.name] = applied
+			}
+			if executedName[step.name] {
+				executed = append(executed, step)
+			}
+		}
+		if !resolved {
+			continue
+		}
+
+		childLogger.Info().Interface("trace-resquest-id", ctx_saga.Value("trace-request-id")).Int("saga", saga.ID).Int("steps", len(executed)).Msg("recovering saga")
+		s.compensateSaga(ctx_saga, saga, executed)
+	}
+
+	return nil
+}
+
+// About replay a step with an unknown outcome (same idempotency key) and record it. Returns if it is applied,
+// an error when the outcome is still unknown (retryable failure)
+func (s *WorkerService) replaySagaStep(ctx context.Context, step sagaStep, record *model.SagaStep) (bool, error) {
+	err := step.execute(ctx)
+	if err != nil && unknownOutcome(err) {
+		return false, err
+	}
+
+	record.Status = model.SagaStepDone
+	if err != nil {
+		record.Status = model.SagaStepFailed
+		record.Error = err.Error()
+	}
+	if _, errStep := s.sagaStore.UpdateSagaStep(ctx, record); errStep != nil {
+		childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).Err(errStep).Msg("failed to record the saga step")
+	}
+
+	return err == nil, nil
+}
+
+// About run the saga recovery at start up and then periodically
+func (s *WorkerService) SagaRecoveryWorker(ctx context.Context, sagaConfig *model.SagaConfig) {
+	childLogger.Info().Str("func","SagaRecoveryWorker").Send()
+
+	ticker := time.NewTicker(time.Duration(sagaConfig.RecoveryInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		err := s.RecoverSaga(ctx, sagaConfig)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("failed to recover sagas")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"time"
+	"errors"
+	"context"
+	"testing"
+	"net/http"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/adapter/account/accounttest"
+)
+
+// About the steps of a saga by action and name, with their status
+func listSagaSteps(t *testing.T, ts *testService, sagaID int) map[string]string {
+	t.Helper()
+
+	res_list, err := ts.repository.ListSagaStep(context.Background(), &model.Saga{ID: sagaID})
+	if err != nil {
+		t.Fatalf("ListSagaStep: %v", err)
+	}
+	steps := map[string]string{}
+	for _, step := range *res_list {
+		steps[step.Action + ":" + step.Name] = step.Status
+	}
+	return steps
+}
+
+// About the net amount posted into an account by go-debit and go-credit
+func netAmount(ts *testService, accountID string) int64 {
+	var net int64
+	for _, statement := range append(ts.accounts.Debits(), ts.accounts.Credits()...) {
+		if statement.AccountID == accountID {
+			net = net + statement.Amount.Minor
+		}
+	}
+	return net
+}
+
+// About a transfer with both legs filled, as AddTransfer runs its saga
+func newTestSagaTransfer(t *testing.T) *model.Transfer {
+	t.Helper()
+
+	transactionID := "tx-saga-1"
+	transfer := newTestTransfer(t, "ACC-1", "ACC-2", 500)
+	transfer.TransactionID = &transactionID
+	transfer.AccountFrom = &model.AccountStatement{ AccountID: "ACC-1", Type: "DEBIT", Currency: "BRL", Amount: newTestMoney(t, -500), TransactionID: &transactionID }
+	transfer.AccountTo = &model.AccountStatement{ AccountID: "ACC-2", Type: "CREDIT", Currency: "BRL", Amount: newTestMoney(t, 500), TransactionID: &transactionID }
+	return transfer
+}
+
+func TestRunSagaUnknownOutcome(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	// the credit answers 5xx after the retries, it may have been applied
+	ts.accounts.Respond(accounttest.ServiceCredit, http.StatusGatewayTimeout, `{"msg":"timeout"}`)
+	_, err := ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 500))
+	if !errors.Is(err, erro.ErrServer) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrServer.Code)
+	}
+	assertNoTransfer(t, ts)
+
+	// the step stays PENDING and the debit is not compensated yet
+	steps := listSagaSteps(t, ts, 1)
+	if steps["EXECUTE:DEBIT"] != model.SagaStepDone || steps["EXECUTE:CREDIT"] != model.SagaStepPending {
+		t.Fatalf("steps = %v, want the debit DONE and the credit PENDING", steps)
+	}
+	if _, ok := steps["COMPENSATE:DEBIT"]; ok {
+		t.Fatalf("steps = %v, want no compensation", steps)
+	}
+
+	// the recovery replays the credit with the same key, then compensates both steps
+	ts.accounts.Restore(accounttest.ServiceCredit)
+	err = ts.service.RecoverSaga(ctx, &model.SagaConfig{ RecoveryInterval: 1, RecoveryAge: 0 })
+	if err != nil {
+		t.Fatalf("RecoverSaga: %v", err)
+	}
+
+	steps = listSagaSteps(t, ts, 1)
+	for _, name := range []string{ "EXECUTE:DEBIT", "EXECUTE:CREDIT", "COMPENSATE:DEBIT", "COMPENSATE:CREDIT" } {
+		if steps[name] != model.SagaStepDone {
+			t.Errorf("step %s = %s, want %s", name, steps[name], model.SagaStepDone)
+		}
+	}
+	if netAmount(ts, "ACC-1") != 0 || netAmount(ts, "ACC-2") != 0 {
+		t.Errorf("net ACC-1 = %d, ACC-2 = %d, want 0 and 0", netAmount(ts, "ACC-1"), netAmount(ts, "ACC-2"))
+	}
+}
+
+func TestRunSagaRejected(t *testing.T) {
+	ts := newTestService(t, false)
+
+	// a definite rejection of the first step, nothing to compensate
+	ts.accounts.Respond(accounttest.ServiceDebit, http.StatusBadRequest, `{"msg":"rejected"}`)
+	_, err := ts.service.AddTransfer(context.Background(), newTestTransfer(t, "ACC-1", "ACC-2", 500))
+	if !errors.Is(err, erro.ErrStatementRejected) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrStatementRejected.Code)
+	}
+
+	steps := listSagaSteps(t, ts, 1)
+	if steps["EXECUTE:DEBIT"] != model.SagaStepFailed || len(steps) != 1 {
+		t.Errorf("steps = %v, want only the debit FAILED", steps)
+	}
+
+	// the saga is compensated, the recovery has nothing to claim
+	res_list, err := ts.repository.ClaimSagaRecovery(context.Background(), time.Now().Add(time.Second), 10)
+	if err != nil || len(*res_list) != 0 {
+		t.Errorf("claimed = %v, %v, want none", res_list, err)
+	}
+}
+
+func TestRunSagaClaimed(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	transfer := newTestSagaTransfer(t)
+	saga := model.Saga{ Type: sagaTypeTransferRest, Status: model.SagaRunning, Transfer: transfer }
+	_, err := ts.repository.AddSaga(ctx, &saga)
+	if err != nil {
+		t.Fatalf("AddSaga: %v", err)
+	}
+
+	// a saga claimed by the recovery is not run any further
+	_, err = ts.repository.ClaimSagaRecovery(ctx, time.Now().Add(time.Second), 10)
+	if err != nil {
+		t.Fatalf("ClaimSagaRecovery: %v", err)
+	}
+	err = ts.service.runSaga(ctx, &saga, ts.service.transferSagaSteps(transfer))
+	if !errors.Is(err, erro.ErrUpdateRows) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrUpdateRows.Code)
+	}
+	if ts.accounts.Calls(accounttest.ServiceDebit) != 0 {
+		t.Errorf("debit calls = %d, want 0", ts.accounts.Calls(accounttest.ServiceDebit))
+	}
+}
+
+func TestRunSagaTouched(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	transfer := newTestSagaTransfer(t)
+	saga := model.Saga{ Type: sagaTypeTransferRest, Status: model.SagaRunning, Transfer: transfer }
+	_, err := ts.repository.AddSaga(ctx, &saga)
+	if err != nil {
+		t.Fatalf("AddSaga: %v", err)
+	}
+	created := saga.UpdatedAt
+
+	// the steps refresh the saga, a recovery looking for older sagas does not claim it
+	err = ts.service.runSaga(ctx, &saga, ts.service.transferSagaSteps(transfer))
+	if err != nil {
+		t.Fatalf("runSaga: %v", err)
+	}
+	res_list, err := ts.repository.ClaimSagaRecovery(ctx, created.Add(time.Nanosecond), 10)
+	if err != nil || len(*res_list) != 0 {
+		t.Errorf("claimed = %v, %v, want none", res_list, err)
+	}
+	if !saga.UpdatedAt.After(created) {
+		t.Errorf("updated_at = %s, want after %s", saga.UpdatedAt, created)
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import(
+	"time"
+	"errors"
+	"strconv"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About the request hash of the idempotency keys of the scheduler, the key is unique per execution
+const scheduleRequestHash = "schedule"
+
+// About add a scheduled credit, debit or transfer
+func (s *WorkerService) AddSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error){
+	childLogger.Info().Str("func","AddSchedule").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("schedule", schedule).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddSchedule")
+	defer span.End()
+
+	// Business rule
+	transfer := schedule.Transfer
+	if transfer == nil || transfer.AccountFrom == nil || transfer.AccountFrom.AccountID == "" {
+		return nil, erro.ErrInvalid
+	}
+	if schedule.ExecuteAt.IsZero() || schedule.ExecuteAt.Before(time.Now()) {
+		return nil, erro.ErrInvalid
+	}
+
+	switch schedule.Type {
+	case model.ScheduleCredit:
+		if !transfer.Amount.IsPositive() {
+			return nil, erro.ErrAmountInvalid
+		}
+	case model.ScheduleDebit:
+		if !transfer.Amount.IsNegative() {
+			return nil, erro.ErrAmountInvalid
+		}
+	case model.ScheduleTransfer:
+		if transfer.Type != "TRANSFER" || transfer.AccountTo == nil || transfer.AccountTo.AccountID == "" {
+			return nil, erro.ErrTransInvalid
+		}
+		if !transfer.Amount.IsPositive() {
+			return nil, erro.ErrAmountInvalid
+		}
+	default:
+		return nil, erro.ErrTransInvalid
+	}
+
+	schedule.Status = model.SchedulePending
+	schedule.Attempts = 0
+
+	// Add schedule
+	res, err := s.scheduleStore.AddSchedule(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About list the schedules by status
+func (s *WorkerService) ListSchedule(ctx context.Context, schedule *model.Schedule, limit int) (*[]model.Schedule, error){
+	childLogger.Info().Str("func","ListSchedule").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("schedule", schedule).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListSchedule")
+	defer span.End()
+
+	// Business rule
+	switch schedule.Status {
+	case "":
+		schedule.Status = model.SchedulePending
+	case model.SchedulePending, model.ScheduleDone, model.ScheduleFailed, model.ScheduleCanceled:
+	default:
+		return nil, erro.ErrStatusInvalid
+	}
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	// List schedule
+	res, err := s.scheduleStore.ListSchedule(ctx, schedule, limit)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About cancel a pending schedule
+func (s *WorkerService) CancelSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error){
+	childLogger.Info().Str("func","CancelSchedule").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("schedule", schedule).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.CancelSchedule")
+	defer span.End()
+
+	// Cancel schedule
+	_, err := s.scheduleStore.CancelSchedule(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.Status = model.ScheduleCanceled
+	return schedule, nil
+}
+
+// About execute a schedule through the use case of its type.
+// The schedule id is the idempotency key (internal prefix), so a schedule executed but not marked as done (crash) is a replay
+func (s *WorkerService) executeSchedule(ctx context.Context, schedule *model.Schedule) (*model.Transfer, error){
+	key := model.IdempotencyInternalPrefix + "schedule-" + strconv.Itoa(schedule.ID)
+
+	ctx_schedule := context.WithValue(ctx, "trace-request-id", key)
+	ctx_schedule = context.WithValue(ctx_schedule, "idempotency-key", &model.Idempotency{	Key: key,
+																							RequestHash: scheduleRequestHash })
+
+	switch schedule.Type {
+	case model.ScheduleCredit:
+		return s.CreditTransferEvent(ctx_schedule, schedule.Transfer)
+	case model.ScheduleDebit:
+		return s.DebitTransferEvent(ctx_schedule, schedule.Transfer)
+	case model.ScheduleTransfer:
+		return s.AddTransferEvent(ctx_schedule, schedule.Transfer)
+	default:
+		return nil, erro.ErrTransInvalid
+	}
+}
+
+// About execute a batch of due schedules. The schedules are claimed with a lease (committed) before the execution,
+// so no database transaction is held during the use cases. A schedule whose lease expired (crash) is claimed again.
+// A failed schedule is retried with backoff until the max attempts, then it is marked as failed
+func (s *WorkerService) RunSchedule(ctx context.Context, schedulerConfig *model.SchedulerConfig) (int, error){
+	childLogger.Debug().Str("func","RunSchedule").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.RunSchedule")
+	defer span.End()
+
+	lockedUntil := time.Now().Add(time.Duration(schedulerConfig.Lease) * time.Second)
+	res_list, err := s.scheduleStore.ClaimScheduleDue(ctx, lockedUntil, schedulerConfig.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range *res_list {
+		schedule := &(*res_list)[i]
+
+		res_transfer, errExecute := s.executeSchedule(ctx, schedule)
+		if errExecute != nil {
+			childLogger.Error().Err(errExecute).Int("schedule", schedule.ID).Int("attempts", schedule.Attempts).Msg("failed to execute the schedule")
+			schedule.LastError = errExecute.Error()
+			if schedule.Attempts >= schedulerConfig.MaxAttempts {
+				schedule.Status = model.ScheduleFailed
+			} else {
+				backoff := time.Duration(schedulerConfig.Interval) * time.Second * time.Duration(1 << schedule.Attempts)
+				schedule.ExecuteAt = time.Now().Add(backoff)
+			}
+		} else {
+			schedule.Status = model.ScheduleDone
+			schedule.LastError = ""
+			schedule.FkTransferID = &res_transfer.ID
+		}
+
+		_, err = s.scheduleStore.UpdateSchedule(ctx, schedule)
+		if errors.Is(err, erro.ErrUpdateRows) {
+			// the lease expired and the schedule was claimed again, its new owner replays it
+			childLogger.Error().Int("schedule", schedule.ID).Msg("lease of the schedule lost")
+			continue
+		}
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(*res_list), nil
+}
+
+// About run the scheduler (schedules and standing orders) periodically, a full batch is followed at once by the next one
+func (s *WorkerService) SchedulerWorker(ctx context.Context, schedulerConfig *model.SchedulerConfig) {
+	childLogger.Info().Str("func","SchedulerWorker").Send()
+
+	ticker := time.NewTicker(time.Duration(schedulerConfig.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		count, err := s.RunSchedule(ctx, schedulerConfig)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("failed to run the schedules")
+		}
+		countStandingOrder, errStandingOrder := s.RunStandingOrder(ctx, schedulerConfig)
+		if errStandingOrder != nil {
+			childLogger.Error().Err(errStandingOrder).Msg("failed to run the standing orders")
+		}
+		if (err == nil && count == schedulerConfig.BatchSize) ||
+			(errStandingOrder == nil && countStandingOrder == schedulerConfig.BatchSize) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
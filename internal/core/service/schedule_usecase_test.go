@@ -0,0 +1,99 @@
+package service
+
+import (
+	"time"
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About the settings of the scheduler of the tests
+var testSchedulerConfig = model.SchedulerConfig{ Interval: 1, BatchSize: 10, MaxAttempts: 3, Lease: 60 }
+
+// About a due credit of the account ACC-1 stored in transfer_schedule, as AddSchedule would once execute_at is reached
+func addTestSchedule(t *testing.T, ts *testService) *model.Schedule {
+	t.Helper()
+
+	schedule := model.Schedule{	Type: model.ScheduleCredit,
+								Transfer: newTestStatement(t, "ACC-1", 300),
+								ExecuteAt: time.Now().Add(-time.Second),
+								Status: model.SchedulePending }
+	_, err := ts.repository.AddSchedule(context.Background(), &schedule)
+	if err != nil {
+		t.Fatalf("AddSchedule: %v", err)
+	}
+	return &schedule
+}
+
+func TestRunScheduleLease(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+	schedule := addTestSchedule(t, ts)
+
+	// a schedule claimed by another scheduler is neither run nor canceled while its lease is held
+	res_claimed, err := ts.repository.ClaimScheduleDue(ctx, time.Now().Add(100 * time.Millisecond), 10)
+	if err != nil || len(*res_claimed) != 1 {
+		t.Fatalf("claimed = %v, %v, want the schedule", res_claimed, err)
+	}
+	count, err := ts.service.RunSchedule(ctx, &testSchedulerConfig)
+	if err != nil || count != 0 {
+		t.Fatalf("run = %d, %v, want 0 and no error", count, err)
+	}
+	_, err = ts.service.CancelSchedule(ctx, &model.Schedule{ID: schedule.ID})
+	if !errors.Is(err, erro.ErrStatusTransition) {
+		t.Fatalf("err = %v, want %s", err, erro.ErrStatusTransition.Code)
+	}
+
+	// the other scheduler crashed, its schedule is claimed and run once the lease expired
+	time.Sleep(150 * time.Millisecond)
+	count, err = ts.service.RunSchedule(ctx, &testSchedulerConfig)
+	if err != nil || count != 1 {
+		t.Fatalf("run = %d, %v, want 1 and no error", count, err)
+	}
+	res_list, err := ts.service.ListSchedule(ctx, &model.Schedule{ Status: model.ScheduleDone }, 10)
+	if err != nil || len(*res_list) != 1 {
+		t.Fatalf("done = %v, %v, want the schedule", res_list, err)
+	}
+	res_schedule := (*res_list)[0]
+	if res_schedule.Attempts != 2 || res_schedule.LockedUntil != nil || res_schedule.FkTransferID == nil {
+		t.Errorf("schedule = %+v, want 2 attempts, the lease released and the transfer", res_schedule)
+	}
+
+	// the expired lease can not update the schedule any more
+	crashed := (*res_claimed)[0]
+	crashed.Status = model.ScheduleFailed
+	_, err = ts.repository.UpdateSchedule(ctx, &crashed)
+	if !errors.Is(err, erro.ErrUpdateRows) {
+		t.Errorf("err = %v, want %s", err, erro.ErrUpdateRows.Code)
+	}
+}
+
+func TestRunScheduleClientKey(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+	schedule := addTestSchedule(t, ts)
+
+	// a client key equal to the former key of the scheduler does not collide with it
+	ctx_client := withTestIdempotencyKey(ctx, "schedule-1", "client-hash")
+	_, err := ts.service.CreditTransferEvent(ctx_client, newTestStatement(t, "ACC-1", 100))
+	if err != nil {
+		t.Fatalf("CreditTransferEvent: %v", err)
+	}
+
+	count, err := ts.service.RunSchedule(ctx, &testSchedulerConfig)
+	if err != nil || count != 1 {
+		t.Fatalf("run = %d, %v, want 1 and no error", count, err)
+	}
+	res_list, err := ts.service.ListSchedule(ctx, &model.Schedule{ Status: model.ScheduleDone }, 10)
+	if err != nil || len(*res_list) != 1 || (*res_list)[0].ID != schedule.ID {
+		t.Fatalf("done = %v, %v, want the schedule", res_list, err)
+	}
+
+	res_transfer, err := ts.service.GetTransfer(ctx, &model.Transfer{ID: *(*res_list)[0].FkTransferID})
+	if err != nil || res_transfer.Amount.Minor != 300 {
+		t.Errorf("transfer = %+v, %v, want the credit of 300", res_transfer, err)
+	}
+}
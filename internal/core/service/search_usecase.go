@@ -0,0 +1,85 @@
+package service
+
+import(
+	"context"
+	"encoding/json"
+	"encoding/base64"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+const (
+	defaultPageLimit	= 50
+	maxPageLimit		= 200
+)
+
+// About encode a keyset cursor, opaque for the client
+func encodeTransferCursor(transferCursor *model.TransferCursor) string {
+	cursor_bytes, _ := json.Marshal(transferCursor)
+	return base64.RawURLEncoding.EncodeToString(cursor_bytes)
+}
+
+// About decode a keyset cursor
+func decodeTransferCursor(cursor string) (*model.TransferCursor, error) {
+	cursor_bytes, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, erro.ErrInvalid
+	}
+	var transferCursor model.TransferCursor
+	err = json.Unmarshal(cursor_bytes, &transferCursor)
+	if err != nil || transferCursor.ID == 0 {
+		return nil, erro.ErrInvalid
+	}
+	return &transferCursor, nil
+}
+
+// About search the transfers by account, status, currency and date range, a page at a time
+func (s *WorkerService) ListTransfer(ctx context.Context, transferFilter *model.TransferFilter, cursor string) (*model.TransferPage, error){
+	childLogger.Info().Str("func","ListTransfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transferFilter", transferFilter).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListTransfer")
+	defer span.End()
+
+	// Business rule
+	if transferFilter.Status != "" && !transferFilter.Status.IsValid() {
+		return nil, erro.ErrStatusInvalid
+	}
+	if transferFilter.From != nil && transferFilter.To != nil && !transferFilter.From.Before(*transferFilter.To) {
+		return nil, erro.ErrInvalid
+	}
+	if transferFilter.Limit <= 0 {
+		transferFilter.Limit = defaultPageLimit
+	}
+	if transferFilter.Limit > maxPageLimit {
+		transferFilter.Limit = maxPageLimit
+	}
+	if cursor != "" {
+		transferCursor, err := decodeTransferCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		transferFilter.Cursor = transferCursor
+	}
+
+	// one more row tells if there is a next page
+	limit := transferFilter.Limit
+	transferFilter.Limit = limit + 1
+
+	res_list, err := s.transferStore.ListTransfer(ctx, transferFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	res_page := model.TransferPage{ Transfers: *res_list }
+	if len(res_page.Transfers) > limit {
+		res_page.Transfers = res_page.Transfers[:limit]
+		last := res_page.Transfers[limit - 1]
+		res_page.NextCursor = encodeTransferCursor(&model.TransferCursor{	TransferAt: last.TransferAt,
+																			ID: last.ID })
+	}
+	transferFilter.Limit = limit
+
+	return &res_page, nil
+}
@@ -1,29 +1,133 @@
 package service
 
 import(
+	"time"
+	"context"
+
 	"github.com/go-fund-transfer/internal/core/model"
-	"github.com/go-fund-transfer/internal/adapter/database"
-	"github.com/go-fund-transfer/internal/adapter/event"
+	"github.com/go-fund-transfer/internal/core/port"
 
 	"github.com/rs/zerolog/log"
 )
 
 var childLogger = log.With().Str("component","go-fund-transfer").Str("package","internal.core.service").Logger()
 
+// About the stores of the use cases, each use case uses only the stores of its subsystem
+type Repositories struct {
+	UnitOfWork			port.UnitOfWork
+	Transfer			port.TransferStore
+	Idempotency			port.IdempotencyStore
+	Saga				port.SagaStore
+	Outbox				port.OutboxStore
+	Schedule			port.ScheduleStore
+	StandingOrder		port.StandingOrderStore
+	Batch				port.BatchStore
+	Quote				port.QuoteStore
+	Limit				port.LimitStore
+	Risk				port.RiskStore
+	Review				port.ReviewStore
+	Approval			port.ApprovalStore
+}
+
+// About the stores of a repository implementing all of them (database.WorkerRepository)
+func NewRepositories(repository port.TransferRepository) Repositories {
+	return Repositories{	UnitOfWork: repository,
+							Transfer: repository,
+							Idempotency: repository,
+							Saga: repository,
+							Outbox: repository,
+							Schedule: repository,
+							StandingOrder: repository,
+							Batch: repository,
+							Quote: repository,
+							Limit: repository,
+							Risk: repository,
+							Review: repository,
+							Approval: repository }
+}
+
 type WorkerService struct {
-	workerRepository *database.WorkerRepository
-	apiService		[]model.ApiService
-	workerEvent		*event.WorkerEvent
+	unitOfWork			port.UnitOfWork
+	transferStore		port.TransferStore
+	idempotencyStore	port.IdempotencyStore
+	sagaStore			port.SagaStore
+	outboxStore			port.OutboxStore
+	scheduleStore		port.ScheduleStore
+	standingOrderStore	port.StandingOrderStore
+	batchStore			port.BatchStore
+	quoteStore			port.QuoteStore
+	limitStore			port.LimitStore
+	riskStore			port.RiskStore
+	reviewStore			port.ReviewStore
+	approvalStore		port.ApprovalStore
+	accountClient	port.AccountClient
+	eventPublisher	port.EventPublisher
+	eventRouting	model.EventRouting
+	outboxConfig	*model.OutboxConfig
+	batchConfig		*model.BatchConfig
+	rateProvider	RateProvider
+	fxConfig		*model.FxConfig
+	riskRules		[]model.RiskRule
+	reviewConfig	*model.ReviewConfig
+	approvalConfig	*model.ApprovalConfig
+	transferDeadline	time.Duration
 }
 
-func NewWorkerService(	workerRepository *database.WorkerRepository, 
-						apiService	[]model.ApiService,
-						workerEvent	*event.WorkerEvent) *WorkerService{
+func NewWorkerService(	repositories Repositories,
+						accountClient port.AccountClient,
+						eventPublisher port.EventPublisher,
+						eventRouting model.EventRouting,
+						outboxConfig *model.OutboxConfig,
+						batchConfig *model.BatchConfig,
+						rateProvider RateProvider,
+						fxConfig *model.FxConfig,
+						riskRules []model.RiskRule,
+						reviewConfig *model.ReviewConfig,
+						approvalConfig *model.ApprovalConfig,
+						transferDeadline time.Duration) *WorkerService{
 	childLogger.Info().Str("func","NewWorkerService").Send()
 
 	return &WorkerService{
-		workerRepository: workerRepository,
-		apiService: apiService,
-		workerEvent: workerEvent,
+		unitOfWork: repositories.UnitOfWork,
+		transferStore: repositories.Transfer,
+		idempotencyStore: repositories.Idempotency,
+		sagaStore: repositories.Saga,
+		outboxStore: repositories.Outbox,
+		scheduleStore: repositories.Schedule,
+		standingOrderStore: repositories.StandingOrder,
+		batchStore: repositories.Batch,
+		quoteStore: repositories.Quote,
+		limitStore: repositories.Limit,
+		riskStore: repositories.Risk,
+		reviewStore: repositories.Review,
+		approvalStore: repositories.Approval,
+		accountClient: accountClient,
+		eventPublisher: eventPublisher,
+		eventRouting: eventRouting,
+		outboxConfig: outboxConfig,
+		batchConfig: batchConfig,
+		rateProvider: rateProvider,
+		fxConfig: fxConfig,
+		riskRules: riskRules,
+		reviewConfig: reviewConfig,
+		approvalConfig: approvalConfig,
+		transferDeadline: transferDeadline,
 	}
-}
\ No newline at end of file
+}
+// About set the deadline of a transfer for the account services (lookups, debit, credit and their retries),
+// once at the start of the transfer: all its calls share it. 0 is no deadline, the timeout of each call still applies
+func (s *WorkerService) withTransferDeadline(ctx context.Context) context.Context {
+	if s.transferDeadline <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, "transfer-deadline", time.Now().Add(s.transferDeadline))
+}
+
+// About the context of a call to the account services, bounded by the deadline of its transfer
+func accountContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Value("transfer-deadline").(time.Time)
+	if !ok {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"time"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/adapter/event"
+	"github.com/go-fund-transfer/internal/adapter/database/memory"
+	"github.com/go-fund-transfer/internal/adapter/account/accounttest"
+)
+
+// About the topics of the events published by the tests
+var testEventRouting = model.EventRouting{	model.EventTypeCredit: "topic.credit",
+											model.EventTypeDebit: "topic.debit",
+											model.EventTypeTransfer: "topic.transfer" }
+
+// About a service on the in-memory repository and publisher and on the fake account services
+type testService struct {
+	service		*WorkerService
+	repository	*memory.TransferRepository
+	accounts	*accounttest.Server
+	publisher	*event.MemoryPublisher
+	outboxConfig	*model.OutboxConfig
+}
+
+// About create the service of a test, outbox selects the outbox mode. ACC-1 and ACC-2 are known accounts (BRL)
+func newTestService(t *testing.T, outbox bool) *testService {
+	t.Helper()
+
+	accounts := accounttest.NewServer()
+	t.Cleanup(accounts.Close)
+	accounts.AddAccount(model.Account{ AccountID: "ACC-1", Currency: "BRL", TenantID: "TENANT-1" })
+	accounts.AddAccount(model.Account{ AccountID: "ACC-2", Currency: "BRL", TenantID: "TENANT-1" })
+
+	repository := memory.NewTransferRepository()
+	publisher := event.NewMemoryPublisher()
+	outboxConfig := &model.OutboxConfig{ Enabled: outbox, RelayInterval: 1, BatchSize: 10, Publisher: "memory" }
+
+	workerService := NewWorkerService(	NewRepositories(repository),
+										accounts.Client(),
+										publisher,
+										testEventRouting,
+										outboxConfig,
+										&model.BatchConfig{ Concurrency: 1, MaxItems: 10 },
+										nil,
+										&model.FxConfig{},
+										nil,
+										&model.ReviewConfig{ TTL: 3600, ExpiryInterval: 60, BatchSize: 10, Role: "TRANSFER_REVIEWER" },
+										&model.ApprovalConfig{ Role: "TRANSFER_APPROVER" },
+										5 * time.Second)
+
+	return &testService{	service: workerService,
+							repository: repository,
+							accounts: accounts,
+							publisher: publisher,
+							outboxConfig: outboxConfig }
+}
+
+func newTestMoney(t *testing.T, minor int64) model.Money {
+	t.Helper()
+
+	amount, err := model.NewMoney(minor, "BRL")
+	if err != nil {
+		t.Fatalf("NewMoney: %v", err)
+	}
+	return amount
+}
+
+// About a transfer between two accounts, like the one decoded by the http adapter
+func newTestTransfer(t *testing.T, accountFrom string, accountTo string, minor int64) *model.Transfer {
+	t.Helper()
+
+	return &model.Transfer{	Type: "TRANSFER",
+							Currency: "BRL",
+							Amount: newTestMoney(t, minor),
+							AccountFrom: &model.AccountStatement{ AccountID: accountFrom },
+							AccountTo: &model.AccountStatement{ AccountID: accountTo } }
+}
+
+// About a credit or a debit of one account, like the one decoded by the http adapter
+func newTestStatement(t *testing.T, accountID string, minor int64) *model.Transfer {
+	t.Helper()
+
+	return &model.Transfer{	Currency: "BRL",
+							Amount: newTestMoney(t, minor),
+							AccountFrom: &model.AccountStatement{ AccountID: accountID } }
+}
+
+// About a request context carrying an idempotency key, as bound by the http adapter
+func withTestIdempotencyKey(ctx context.Context, key string, requestHash string) context.Context {
+	return context.WithValue(ctx, "idempotency-key", &model.Idempotency{ Key: key, RequestHash: requestHash })
+}
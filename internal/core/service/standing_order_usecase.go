@@ -0,0 +1,242 @@
+package service
+
+import(
+	"time"
+	"errors"
+	"strconv"
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+)
+
+// About add a standing order, the first occurrence is at start_at
+func (s *WorkerService) AddStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (*model.StandingOrder, error){
+	childLogger.Info().Str("func","AddStandingOrder").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("standingOrder", standingOrder).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddStandingOrder")
+	defer span.End()
+
+	// Business rule
+	transfer := standingOrder.Transfer
+	if transfer == nil || transfer.AccountFrom == nil || transfer.AccountFrom.AccountID == "" ||
+		transfer.AccountTo == nil || transfer.AccountTo.AccountID == "" || transfer.Type != "TRANSFER" {
+		return nil, erro.ErrTransInvalid
+	}
+	if !transfer.Amount.IsPositive() {
+		return nil, erro.ErrAmountInvalid
+	}
+	if !model.IsValidFrequency(standingOrder.Frequency) {
+		return nil, erro.ErrInvalid
+	}
+	if standingOrder.Interval == 0 {
+		standingOrder.Interval = 1
+	}
+	if standingOrder.Interval < 0 || standingOrder.MaxOccurrences < 0 {
+		return nil, erro.ErrInvalid
+	}
+	if standingOrder.StartAt.IsZero() || standingOrder.StartAt.Before(time.Now()) {
+		return nil, erro.ErrInvalid
+	}
+	if standingOrder.EndAt != nil && standingOrder.EndAt.Before(standingOrder.StartAt) {
+		return nil, erro.ErrInvalid
+	}
+	switch standingOrder.FailurePolicy {
+	case "":
+		standingOrder.FailurePolicy = model.FailurePolicyRetry
+	case model.FailurePolicyRetry, model.FailurePolicySkip:
+	default:
+		return nil, erro.ErrInvalid
+	}
+
+	standingOrder.Status = model.StandingOrderActive
+	standingOrder.Occurrences = 0
+	standingOrder.Attempts = 0
+	standingOrder.NextExecuteAt = standingOrder.OccurrenceAt(1)
+
+	// Add standing order
+	res, err := s.standingOrderStore.AddStandingOrder(ctx, standingOrder)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About get a standing order with its history
+func (s *WorkerService) GetStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (*model.StandingOrder, error){
+	childLogger.Info().Str("func","GetStandingOrder").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("standingOrder", standingOrder).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.GetStandingOrder")
+	defer span.End()
+
+	// Get standing order
+	res, err := s.standingOrderStore.GetStandingOrder(ctx, standingOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	res.History, err = s.standingOrderStore.ListStandingOrderOccurrence(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About list the standing orders by status
+func (s *WorkerService) ListStandingOrder(ctx context.Context, standingOrder *model.StandingOrder, limit int) (*[]model.StandingOrder, error){
+	childLogger.Info().Str("func","ListStandingOrder").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("standingOrder", standingOrder).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListStandingOrder")
+	defer span.End()
+
+	// Business rule
+	switch standingOrder.Status {
+	case "":
+		standingOrder.Status = model.StandingOrderActive
+	case model.StandingOrderActive, model.StandingOrderCompleted, model.StandingOrderCanceled:
+	default:
+		return nil, erro.ErrStatusInvalid
+	}
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	// List standing order
+	res, err := s.standingOrderStore.ListStandingOrder(ctx, standingOrder, limit)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About cancel an active standing order, the occurrences already done are kept
+func (s *WorkerService) CancelStandingOrder(ctx context.Context, standingOrder *model.StandingOrder) (*model.StandingOrder, error){
+	childLogger.Info().Str("func","CancelStandingOrder").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("standingOrder", standingOrder).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.CancelStandingOrder")
+	defer span.End()
+
+	// Cancel standing order
+	_, err := s.standingOrderStore.CancelStandingOrder(ctx, standingOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	standingOrder.Status = model.StandingOrderCanceled
+	return standingOrder, nil
+}
+
+// About materialize an occurrence into a transfer via event.
+// The standing order id and the sequence are the idempotency key (internal prefix), so an occurrence is never transferred twice
+func (s *WorkerService) executeStandingOrder(ctx context.Context, standingOrder *model.StandingOrder, sequence int) (*model.Transfer, error){
+	key := model.IdempotencyInternalPrefix + "standing-order-" + strconv.Itoa(standingOrder.ID) + "-" + strconv.Itoa(sequence)
+
+	ctx_standingOrder := context.WithValue(ctx, "trace-request-id", key)
+	ctx_standingOrder = context.WithValue(ctx_standingOrder, "idempotency-key", &model.Idempotency{	Key: key,
+																										RequestHash: scheduleRequestHash })
+
+	return s.AddTransferEvent(ctx_standingOrder, standingOrder.Transfer)
+}
+
+// About record the progress of a standing order and its occurrence (nil while retried) in a transaction, releasing its lease
+func (s *WorkerService) updateStandingOrder(ctx context.Context, standingOrder *model.StandingOrder, occurrence *model.StandingOrderOccurrence) (err error){
+	// Get the database connection
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	if occurrence != nil {
+		_, err = s.standingOrderStore.AddStandingOrderOccurrence(ctx, tx, occurrence)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.standingOrderStore.UpdateStandingOrder(ctx, tx, standingOrder)
+	return err
+}
+
+// About execute the due occurrence of a batch of standing orders. The standing orders are claimed with a lease (committed)
+// before the execution, so no database transaction is held during the transfers. A failed occurrence is retried with backoff
+// (policy RETRY, until the max attempts) or skipped, then the standing order moves to its next occurrence
+func (s *WorkerService) RunStandingOrder(ctx context.Context, schedulerConfig *model.SchedulerConfig) (int, error){
+	childLogger.Debug().Str("func","RunStandingOrder").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.RunStandingOrder")
+	defer span.End()
+
+	lockedUntil := time.Now().Add(time.Duration(schedulerConfig.Lease) * time.Second)
+	res_list, err := s.standingOrderStore.ClaimStandingOrderDue(ctx, lockedUntil, schedulerConfig.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range *res_list {
+		standingOrder := &(*res_list)[i]
+		sequence := standingOrder.Occurrences + 1
+
+		occurrence := model.StandingOrderOccurrence{	FkStandingOrderID: standingOrder.ID,
+														Sequence: sequence,
+														ScheduledAt: standingOrder.OccurrenceAt(sequence),
+														Attempts: standingOrder.Attempts }
+
+		res_transfer, errExecute := s.executeStandingOrder(ctx, standingOrder, sequence)
+		if errExecute != nil {
+			childLogger.Error().Err(errExecute).Int("standingOrder", standingOrder.ID).Int("sequence", sequence).Int("attempts", standingOrder.Attempts).Msg("failed to execute the standing order")
+			standingOrder.LastError = errExecute.Error()
+			occurrence.Status = model.OccurrenceSkipped
+			occurrence.LastError = errExecute.Error()
+		} else {
+			occurrence.Status = model.OccurrenceDone
+			if res_transfer.Status == model.StatusPendingReview || res_transfer.Status == model.StatusAwaitingApproval {
+				occurrence.Status = model.OccurrenceHeld
+			}
+			occurrence.FkTransferID = &res_transfer.ID
+			standingOrder.LastError = ""
+		}
+
+		if errExecute != nil && standingOrder.FailurePolicy == model.FailurePolicyRetry && standingOrder.Attempts < schedulerConfig.MaxAttempts {
+			backoff := time.Duration(schedulerConfig.Interval) * time.Second * time.Duration(1 << standingOrder.Attempts)
+			standingOrder.NextExecuteAt = time.Now().Add(backoff)
+			err = s.updateStandingOrder(ctx, standingOrder, nil)
+		} else {
+			// move to the next occurrence
+			standingOrder.Occurrences = sequence
+			standingOrder.Attempts = 0
+			if standingOrder.IsOver(sequence + 1) {
+				standingOrder.Status = model.StandingOrderCompleted
+			} else {
+				standingOrder.NextExecuteAt = standingOrder.OccurrenceAt(sequence + 1)
+			}
+			err = s.updateStandingOrder(ctx, standingOrder, &occurrence)
+		}
+		if errors.Is(err, erro.ErrUpdateRows) {
+			// the lease expired and the standing order was claimed again, its new owner replays the occurrence
+			childLogger.Error().Int("standingOrder", standingOrder.ID).Int("sequence", sequence).Msg("lease of the standing order lost")
+			continue
+		}
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(*res_list), nil
+}
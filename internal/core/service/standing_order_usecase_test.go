@@ -0,0 +1,91 @@
+package service
+
+import (
+	"time"
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About a daily standing order from ACC-1 to ACC-2 whose first occurrence is due, as AddStandingOrder would store it
+func addTestStandingOrder(t *testing.T, ts *testService) *model.StandingOrder {
+	t.Helper()
+
+	startAt := time.Now().Add(-time.Hour)
+	standingOrder := model.StandingOrder{	Transfer: newTestTransfer(t, "ACC-1", "ACC-2", 500),
+											Frequency: model.FrequencyDaily,
+											Interval: 1,
+											StartAt: startAt,
+											FailurePolicy: model.FailurePolicyRetry,
+											NextExecuteAt: startAt,
+											Status: model.StandingOrderActive }
+	_, err := ts.repository.AddStandingOrder(context.Background(), &standingOrder)
+	if err != nil {
+		t.Fatalf("AddStandingOrder: %v", err)
+	}
+	return &standingOrder
+}
+
+func TestRunStandingOrderClientKey(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+	standingOrder := addTestStandingOrder(t, ts)
+
+	// a client key equal to the former key of the occurrence does not collide with it
+	ctx_client := withTestIdempotencyKey(ctx, "standing-order-1-1", "client-hash")
+	_, err := ts.service.AddTransferEvent(ctx_client, newTestTransfer(t, "ACC-1", "ACC-2", 100))
+	if err != nil {
+		t.Fatalf("AddTransferEvent: %v", err)
+	}
+
+	count, err := ts.service.RunStandingOrder(ctx, &testSchedulerConfig)
+	if err != nil || count != 1 {
+		t.Fatalf("run = %d, %v, want 1 and no error", count, err)
+	}
+
+	res_standingOrder, err := ts.repository.GetStandingOrder(ctx, standingOrder)
+	if err != nil {
+		t.Fatalf("GetStandingOrder: %v", err)
+	}
+	if res_standingOrder.Occurrences != 1 || res_standingOrder.Attempts != 0 || res_standingOrder.LockedUntil != nil || !res_standingOrder.NextExecuteAt.After(time.Now()) {
+		t.Errorf("standing order = %+v, want the next occurrence and the lease released", res_standingOrder)
+	}
+
+	res_list, err := ts.repository.ListStandingOrderOccurrence(ctx, standingOrder)
+	if err != nil || len(*res_list) != 1 || (*res_list)[0].Status != model.OccurrenceDone {
+		t.Fatalf("occurrences = %v, %v, want the first one DONE", res_list, err)
+	}
+	res_transfer, err := ts.service.GetTransfer(ctx, &model.Transfer{ID: *(*res_list)[0].FkTransferID})
+	if err != nil || res_transfer.Amount.Minor != 500 {
+		t.Errorf("transfer = %+v, %v, want the transfer of 500", res_transfer, err)
+	}
+}
+
+func TestRunStandingOrderCommitFailed(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+	standingOrder := addTestStandingOrder(t, ts)
+
+	// the commit error is returned, nothing is recorded and the lease is kept until it expires
+	ts.repository.CommitErr = errors.New("connection lost")
+	_, err := ts.service.RunStandingOrder(ctx, &testSchedulerConfig)
+	if err == nil || err.Error() != "connection lost" {
+		t.Fatalf("err = %v, want the commit error", err)
+	}
+	ts.repository.CommitErr = nil
+
+	res_list, err := ts.repository.ListStandingOrderOccurrence(ctx, standingOrder)
+	if err != nil || len(*res_list) != 0 {
+		t.Fatalf("occurrences = %v, %v, want none", res_list, err)
+	}
+	res_standingOrder, err := ts.repository.GetStandingOrder(ctx, standingOrder)
+	if err != nil || res_standingOrder.Occurrences != 0 || res_standingOrder.LockedUntil == nil {
+		t.Fatalf("standing order = %+v, %v, want no occurrence and the lease held", res_standingOrder, err)
+	}
+	count, err := ts.service.RunStandingOrder(ctx, &testSchedulerConfig)
+	if err != nil || count != 0 {
+		t.Errorf("run = %d, %v, want 0 and no error", count, err)
+	}
+}
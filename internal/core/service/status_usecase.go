@@ -0,0 +1,98 @@
+package service
+
+import(
+	"context"
+
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/port"
+)
+
+// About move a transfer to a new status, only the transitions allowed by the state machine are applied
+func (s *WorkerService) UpdateTransferStatus(ctx context.Context, statusTransition *model.StatusTransition) (_ *model.StatusTransition, err error){
+	childLogger.Info().Str("func","UpdateTransferStatus").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("statusTransition", statusTransition).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.UpdateTransferStatus")
+
+	// Business rule
+	if !statusTransition.StatusTo.IsValid() {
+		span.End()
+		return nil, erro.ErrStatusInvalid
+	}
+
+	// Get the database connection
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	res_statusTransition, err := s.applyStatusTransition(ctx, tx, statusTransition)
+	if err != nil {
+		return nil, err
+	}
+
+	return res_statusTransition, nil
+}
+
+// About apply a status transition inside a transaction (SELECT ... FOR UPDATE) and record it.
+// The transitions depend on the type of the transfer, the last leg of a transfer records TRANSFER_DONE
+func (s *WorkerService) applyStatusTransition(ctx context.Context, tx port.Tx, statusTransition *model.StatusTransition) (*model.StatusTransition, error){
+	// Lock the transfer
+	res_transfer, err := s.transferStore.GetTransferForUpdate(ctx, tx, &model.Transfer{ID: statusTransition.FkTransferID})
+	if err != nil {
+		return nil, err
+	}
+
+	// Business rule
+	status_to, ok := res_transfer.Status.NextStatus(res_transfer.Type, statusTransition.StatusTo)
+	if !ok {
+		childLogger.Error().Interface("trace-resquest-id", ctx.Value("trace-request-id")).
+							Str("type", res_transfer.Type).
+							Interface("from", res_transfer.Status).
+							Interface("to", statusTransition.StatusTo).Msg("status transition not allowed")
+		return nil, erro.ErrStatusTransition
+	}
+
+	statusTransition.StatusFrom = res_transfer.Status
+	statusTransition.StatusTo = status_to
+	res_transfer.Status = status_to
+
+	_, err = s.transferStore.UpdateTransferStatus(ctx, tx, res_transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	res_statusTransition, err := s.transferStore.AddStatusTransition(ctx, tx, statusTransition)
+	if err != nil {
+		return nil, err
+	}
+
+	return res_statusTransition, nil
+}
+
+// About list the status transitions of a transfer
+func (s *WorkerService) ListTransferStatus(ctx context.Context, transfer *model.Transfer) (*[]model.StatusTransition, error){
+	childLogger.Info().Str("func","ListTransferStatus").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transfer", transfer).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListTransferStatus")
+	defer span.End()
+
+	res, err := s.transferStore.ListStatusTransition(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+func TestUpdateTransferStatusLastLeg(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	res_transfer, err := ts.service.AddTransferEvent(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 700))
+	if err != nil {
+		t.Fatalf("AddTransferEvent: %v", err)
+	}
+
+	// the legs complete in any order, the last one completes the transfer
+	for _, tt := range []struct {
+		to		model.TransferStatus
+		want	model.TransferStatus
+	}{
+		{ model.StatusDebitDone, model.StatusDebitDone },
+		{ model.StatusCreditDone, model.StatusTransferDone },
+	} {
+		res_statusTransition, err := ts.service.UpdateTransferStatus(ctx, &model.StatusTransition{ FkTransferID: res_transfer.ID, StatusTo: tt.to })
+		if err != nil {
+			t.Fatalf("UpdateTransferStatus %s: %v", tt.to, err)
+		}
+		if res_statusTransition.StatusTo != tt.want {
+			t.Errorf("status to = %s, want %s", res_statusTransition.StatusTo, tt.want)
+		}
+	}
+
+	res_get, err := ts.service.GetTransfer(ctx, &model.Transfer{ID: res_transfer.ID})
+	if err != nil || res_get.Status != model.StatusTransferDone {
+		t.Fatalf("stored transfer = %+v, %v, want %s", res_get, err, model.StatusTransferDone)
+	}
+
+	// the legs do not move a done transfer back
+	_, err = ts.service.UpdateTransferStatus(ctx, &model.StatusTransition{ FkTransferID: res_transfer.ID, StatusTo: model.StatusDebitDone })
+	if !errors.Is(err, erro.ErrStatusTransition) {
+		t.Errorf("err = %v, want %s", err, erro.ErrStatusTransition.Code)
+	}
+}
+
+func TestUpdateTransferStatusSingleLeg(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	res_transfer, err := ts.service.CreditTransferEvent(ctx, newTestStatement(t, "ACC-1", 300))
+	if err != nil {
+		t.Fatalf("CreditTransferEvent: %v", err)
+	}
+	_, err = ts.service.UpdateTransferStatus(ctx, &model.StatusTransition{ FkTransferID: res_transfer.ID, StatusTo: model.StatusCreditDone })
+	if err != nil {
+		t.Fatalf("UpdateTransferStatus: %v", err)
+	}
+
+	// a credit never reaches the debit leg nor the completion of a transfer
+	for _, to := range []model.TransferStatus{ model.StatusDebitDone, model.StatusTransferDone } {
+		_, err = ts.service.UpdateTransferStatus(ctx, &model.StatusTransition{ FkTransferID: res_transfer.ID, StatusTo: to })
+		if !errors.Is(err, erro.ErrStatusTransition) {
+			t.Errorf("%s err = %v, want %s", to, err, erro.ErrStatusTransition.Code)
+		}
+	}
+
+	res_list, err := ts.service.ListTransferStatus(ctx, &model.Transfer{ID: res_transfer.ID})
+	if err != nil {
+		t.Fatalf("ListTransferStatus: %v", err)
+	}
+	if len(*res_list) != 2 {
+		t.Errorf("transitions = %+v, want the creation and CREDIT_DONE", *res_list)
+	}
+}
+
+func TestUpdateTransferStatusCommitFailed(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	res_transfer, err := ts.service.CreditTransferEvent(ctx, newTestStatement(t, "ACC-1", 300))
+	if err != nil {
+		t.Fatalf("CreditTransferEvent: %v", err)
+	}
+
+	// the commit error is returned, the status is unchanged
+	ts.repository.CommitErr = errors.New("connection lost")
+	_, err = ts.service.UpdateTransferStatus(ctx, &model.StatusTransition{ FkTransferID: res_transfer.ID, StatusTo: model.StatusCreditDone })
+	if err == nil || err.Error() != "connection lost" {
+		t.Fatalf("err = %v, want the commit error", err)
+	}
+	ts.repository.CommitErr = nil
+
+	res_get, err := ts.service.GetTransfer(ctx, &model.Transfer{ID: res_transfer.ID})
+	if err != nil || res_get.Status != model.StatusCreditEventCreated {
+		t.Errorf("stored transfer = %+v, %v, want %s", res_get, err, model.StatusCreditEventCreated)
+	}
+}
+
+func TestUpdateTransferStatusStores(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	res_transfer, err := ts.service.CreditTransferEvent(ctx, newTestStatement(t, "ACC-1", 300))
+	if err != nil {
+		t.Fatalf("CreditTransferEvent: %v", err)
+	}
+
+	// the status use case needs only the unit of work and the transfers
+	workerService := NewWorkerService(Repositories{ UnitOfWork: ts.repository, Transfer: ts.repository }, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0)
+	res_statusTransition, err := workerService.UpdateTransferStatus(ctx, &model.StatusTransition{ FkTransferID: res_transfer.ID, StatusTo: model.StatusCreditDone })
+	if err != nil || res_statusTransition.StatusFrom != model.StatusCreditEventCreated {
+		t.Errorf("transition = %+v, %v, want from %s", res_statusTransition, err, model.StatusCreditEventCreated)
+	}
+}
@@ -5,62 +5,57 @@ import(
 	"time"
 	"strconv"
 	"context"
-	"net/http"
 	"encoding/json"
-	"errors"
 
 	"github.com/go-fund-transfer/internal/core/model"
 	"github.com/go-fund-transfer/internal/core/erro"
 	go_core_observ "github.com/eliezerraj/go-core/observability"
-	go_core_api "github.com/eliezerraj/go-core/api"
 )
 
 var tracerProvider go_core_observ.TracerProvider
-var apiService go_core_api.ApiService
-
-// About handle/conver the error from api call
-func errorStatusCode(statusCode int) error{
-	var err error
-	switch statusCode {
-	case http.StatusUnauthorized:
-		err = erro.ErrUnauthorized
-	case http.StatusForbidden:
-		err = erro.ErrHTTPForbiden
-	case http.StatusNotFound:
-		err = erro.ErrNotFound
-	default:
-		err = erro.ErrServer
-	}
-	return err
-}
 
-// About add a transfer transaction via REST
-func (s WorkerService) AddTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error){
+// About add a transfer transaction via REST. A failed commit after the saga compensates it, the error is returned
+func (s WorkerService) AddTransfer(ctx context.Context, transfer *model.Transfer) (_ *model.Transfer, err error){
 	childLogger.Info().Str("func","AddTransfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transfer", transfer).Send()
 
 	//Trace
 	span := tracerProvider.Span(ctx, "service.AddTransfer")
-	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+	ctx = s.withTransferDeadline(ctx)
 
 	// Get the database connection
-	tx, conn, err := s.workerRepository.DatabasePGServer.StartTx(ctx)
+	tx, err := s.unitOfWork.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
 	
+	// the saga, once its steps are executed
+	var saga *model.Saga
+	var steps []sagaStep
+
 	// Handle the transaction
 	defer func() {
 		if err != nil {
 			tx.Rollback(ctx)
 		} else {
-			tx.Commit(ctx)
+			err = tx.Commit(ctx)
+			if err != nil && saga != nil {
+				s.compensateSaga(ctx, saga, steps)
+			}
 		}
-		s.workerRepository.DatabasePGServer.ReleaseTx(conn)
 		span.End()
 	}()
 	
+	// Idempotency, a replay returns the original response
+	res_replay, err := s.checkIdempotency(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if res_replay != nil {
+		return res_replay, nil
+	}
+
 	// Get transaction UUID 
-	res_uuid, err := s.workerRepository.GetTransactionUUID(ctx)
+	res_uuid, err := s.transferStore.GetTransactionUUID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +69,7 @@ func (s WorkerService) AddTransfer(ctx context.Context, transfer *model.Transfer
 	time_chargeAt := time.Now()
 	transfer.AccountFrom.Currency = transfer.Currency
 	transfer.AccountFrom.TransactionID = res_uuid
-	transfer.AccountFrom.Amount = (transfer.Amount * -1)
+	transfer.AccountFrom.Amount = transfer.Amount.Neg()
 	transfer.AccountFrom.Type = "DEBIT"
 	transfer.AccountFrom.ChargeAt = time_chargeAt
 
@@ -85,80 +80,113 @@ func (s WorkerService) AddTransfer(ctx context.Context, transfer *model.Transfer
 	transfer.AccountTo.ChargeAt = time_chargeAt
 
 	transfer.TransactionID = res_uuid
-	transfer.Status = "TRANSFER-REST-DONE"
+	transfer.Status = model.StatusTransferRestDone
 	transfer.TransferAt = time_chargeAt
 
 	// Get the Account ID from Account-service
-	res_acc_from, statusCode, err := apiService.CallApi(ctx,
-														s.apiService[0].Url + "/" + transfer.AccountFrom.AccountID,
-														s.apiService[0].Method,
-														&s.apiService[0].Header_x_apigw_api_id,
-														nil,
-														&trace_id, 
-														nil)
-	if err != nil {
-		return nil, errorStatusCode(statusCode)
-	}
-	jsonString, err := json.Marshal(res_acc_from)
+	accountFrom, err := s.getAccount(ctx, transfer.AccountFrom.AccountID)
 	if err != nil {
-		return nil, errors.New(err.Error())
+		return nil, err
 	}
-	var account model.AccountStatement
-	json.Unmarshal(jsonString, &account)
 
-	transfer.AccountFrom.FkAccountID = account.ID
+	transfer.AccountFrom.FkAccountID = accountFrom.ID
+	transfer.AccountFrom.TenantID = accountFrom.TenantID
 	
 	// Get the Account ID from Account-service
-	res_acc_to, statusCode, err := apiService.CallApi(ctx,
-														s.apiService[0].Url + "/" + transfer.AccountTo.AccountID,
-														s.apiService[0].Method,
-														&s.apiService[0].Header_x_apigw_api_id,
-														nil,
-														&trace_id, 
-														nil)
+	accountTo, err := s.getAccount(ctx, transfer.AccountTo.AccountID)
 	if err != nil {
-		return nil, errorStatusCode(statusCode)
+		return nil, err
 	}
-	jsonString, err = json.Marshal(res_acc_to)
+
+	transfer.AccountTo.FkAccountID = accountTo.ID
+
+	// Convert the credit leg into the currency of the destination account
+	err = s.applyFx(ctx, tx, transfer, accountTo.Currency)
 	if err != nil {
-		return nil, errors.New(err.Error())
+		return nil, err
 	}
-	json.Unmarshal(jsonString, &account)
 
-	transfer.AccountTo.FkAccountID = account.ID
+	// Run the risk rules
+	res_risk, err := s.evaluateRisk(ctx, tx, transfer, model.RiskOperationTransfer)
+	if err != nil {
+		return nil, err
+	}
 
-	// Add (POST) the account statement Get the Account ID from Account-service
-	_, statusCode, err = apiService.CallApi(ctx,
-											s.apiService[1].Url,
-											s.apiService[1].Method,
-											&s.apiService[1].Header_x_apigw_api_id,
-											nil,
-											&trace_id, 
-											transfer.AccountFrom)
+	// Check the limits of the source account and its tenant
+	err = s.checkLimits(ctx, tx, transfer, accountFrom.TenantID)
 	if err != nil {
-		return nil, errorStatusCode(statusCode)
+		return nil, err
 	}
 
-	// Add (POST) the account statement Get the Account ID from Account-service
-	_, statusCode, err = apiService.CallApi(ctx,
-											s.apiService[2].Url,
-											s.apiService[2].Method,
-											&s.apiService[2].Header_x_apigw_api_id,
-											nil,
-											&trace_id,
-											transfer.AccountTo)
+	// Hold the transfer for a manual review, the saga runs on approval
+	if res_risk.Decision == model.RiskReview {
+		var res_transfer *model.Transfer
+		res_transfer, err = s.holdForReview(ctx, tx, transfer, model.FlowTransferRest, res_risk)
+		if err != nil {
+			return nil, err
+		}
+		return res_transfer, nil
+	}
+
+	// Hold the transfer for the approval of a second user (maker-checker), the saga runs on approval
+	var approval bool
+	approval, err = s.requiresApproval(ctx, tx, model.FlowTransferRest, transfer)
 	if err != nil {
-		return nil, errorStatusCode(statusCode)
+		return nil, err
 	}
+	if approval {
+		var res_transfer *model.Transfer
+		res_transfer, err = s.holdForApproval(ctx, tx, transfer, model.FlowTransferRest)
+		if err != nil {
+			return nil, err
+		}
+		return res_transfer, nil
+	}
+
+	// Run the saga (debit and credit), a failed step compensates the previous ones
+	res_saga := model.Saga{	TransactionID: res_uuid,
+							Type: sagaTypeTransferRest,
+							Status: model.SagaRunning,
+							Transfer: transfer }
+	_, err = s.sagaStore.AddSaga(ctx, &res_saga)
+	if err != nil {
+		return nil, err
+	}
+
+	steps = s.transferSagaSteps(transfer)
+	err = s.runSaga(ctx, &res_saga, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	// From here a failure (the commit included) compensates all the steps of the saga
+	saga = &res_saga
+	defer func() {
+		if err != nil {
+			s.compensateSaga(ctx, saga, steps)
+		}
+	}()
 
 	// Add transfer
-	res_transfer, err := s.workerRepository.AddTransfer(ctx, tx, transfer)
+	res_transfer, err := s.transferStore.AddTransfer(ctx, tx, transfer)
 	if err != nil {
 		return nil, err
 	}
 
 	transfer.ID = res_transfer.ID 
 
+	// Store the response of the idempotency key
+	err = s.saveIdempotency(ctx, tx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Complete the saga in the same transaction of the transfer
+	_, err = s.sagaStore.CompleteSaga(ctx, tx, saga)
+	if err != nil {
+		return nil, err
+	}
+
 	return transfer, nil
 }
 
@@ -171,31 +199,76 @@ func (s *WorkerService) GetTransfer(ctx context.Context, transfer *model.Transfe
 	defer span.End()
 	
 	// Get transfer
-	res, err := s.workerRepository.GetTransfer(ctx, transfer)
+	res, err := s.transferStore.GetTransfer(ctx, transfer)
 	if err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
+// About get a transfer transaction by its transaction_id with both legs resolved
+func (s *WorkerService) GetTransferByTransactionID(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error){
+	childLogger.Info().Str("func","GetTransferByTransactionID").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transfer", transfer).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.GetTransferByTransactionID")
+	defer span.End()
+
+	// Get transfer
+	res, err := s.transferStore.GetTransferByTransactionID(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	resolveTransferLegs(res)
+
+	return res, nil
+}
+
+// About fill both legs (account statement) as they were sent to go-debit and go-credit
+func resolveTransferLegs(transfer *model.Transfer) {
+	for _, leg := range []*model.AccountStatement{transfer.AccountFrom, transfer.AccountTo} {
+		leg.Currency = transfer.Currency
+		leg.TransactionID = transfer.TransactionID
+		leg.ChargeAt = transfer.TransferAt
+		leg.Amount = transfer.Amount
+		leg.Type = transfer.Type
+	}
+
+	// a transfer debits the source account and credits the destination account
+	if transfer.Type == "TRANSFER" {
+		transfer.AccountFrom.Type = "DEBIT"
+		transfer.AccountFrom.Amount = transfer.Amount.Neg()
+		transfer.AccountTo.Type = "CREDIT"
+	}
+
+	// the credit leg of a multi-currency transfer is in the currency of the destination account
+	if transfer.Fx != nil {
+		transfer.AccountTo.Currency = transfer.Fx.DestinationCurrency
+		transfer.AccountTo.Amount = transfer.Fx.DestinationAmount
+	}
+}
+
 // About add a credit transfer transaction event
-func (s *WorkerService) CreditTransferEvent(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error){
+func (s *WorkerService) CreditTransferEvent(ctx context.Context, transfer *model.Transfer) (_ *model.Transfer, err error){
 	childLogger.Info().Str("func","CreditTransferEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transfer", transfer).Send()
 
 	// Trace
 	span := tracerProvider.Span(ctx, "service.CreditTransferEvent")
+	ctx = s.withTransferDeadline(ctx)
 	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
 
 	// Get the database connection
-	tx, conn, err := s.workerRepository.DatabasePGServer.StartTx(ctx)
+	tx, err := s.unitOfWork.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
 	
-	// Start Kafka transaction
-	err = s.workerEvent.WorkerKafka.BeginTransaction()
+	// Start Kafka transaction (the outbox mode only needs the database transaction)
+	err = s.beginEventTransaction(ctx)
 	if err != nil {
 		childLogger.Error().Interface("trace-resquest-id", trace_id ).Err(err).Msg("failed to kafka BeginTransaction")
+		tx.Rollback(ctx)
 		return nil, err
 	}
 
@@ -203,65 +276,89 @@ func (s *WorkerService) CreditTransferEvent(ctx context.Context, transfer *model
 	defer func() {
 		if err != nil {
 			childLogger.Info().Interface("trace-resquest-id", trace_id ).Msg("ROLLBACK !!!!")
-			err :=  s.workerEvent.WorkerKafka.AbortTransaction(ctx)
+			err :=  s.abortEventTransaction(ctx)
 			if err != nil {
 				childLogger.Error().Interface("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Kafka AbortTransaction")
 			}		
 			tx.Rollback(ctx)
 		} else {
-			err =  s.workerEvent.WorkerKafka.CommitTransaction(ctx)
+			err =  s.commitEventTransaction(ctx)
 			if err != nil {
 				childLogger.Error().Interface("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Kafka CommitTransaction")
+				tx.Rollback(ctx)
+			} else {
+				err = tx.Commit(ctx)
+				if err != nil {
+					childLogger.Error().Interface("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Commit, the events are already committed")
+				}
 			}
-			tx.Commit(ctx)
 		}
-		s.workerRepository.DatabasePGServer.ReleaseTx(conn)
 		span.End()
 	}()
 	
-	// Get transaction UUID 
-	res_uuid, err := s.workerRepository.GetTransactionUUID(ctx)
+	// Idempotency, a replay returns the original response
+	res_replay, err := s.checkIdempotency(ctx, tx)
 	if err != nil {
 		return nil, err
 	}
+	if res_replay != nil {
+		return res_replay, nil
+	}
 
-	// Get the Account ID from Account-service
-	res_acc_from, statusCode, err := apiService.CallApi(ctx,
-														s.apiService[0].Url + "/" + transfer.AccountFrom.AccountID,
-														s.apiService[0].Method,
-														&s.apiService[0].Header_x_apigw_api_id,
-														nil,
-														&trace_id,
-														nil)
+	// Get transaction UUID 
+	res_uuid, err := s.transferStore.GetTransactionUUID(ctx)
 	if err != nil {
-		return nil, errorStatusCode(statusCode)
+		return nil, err
 	}
-	jsonString, err := json.Marshal(res_acc_from)
+
+	// Get the Account ID from Account-service
+	accountFrom, err := s.getAccount(ctx, transfer.AccountFrom.AccountID)
 	if err != nil {
-		return nil, errors.New(err.Error())
+		return nil, err
 	}
-	var accountStatement model.AccountStatement
-	json.Unmarshal(jsonString, &accountStatement)
 
 	// Businness rule
-	if transfer.Amount < 0 {
+	if transfer.Amount.IsNegative() {
 		return nil, erro.ErrAmountInvalid
 	}
 	time_chargeAt := time.Now()
-	transfer.AccountFrom.FkAccountID = accountStatement.ID
+	transfer.AccountFrom.FkAccountID = accountFrom.ID
 	transfer.AccountTo = transfer.AccountFrom // From and To are the same in case of Credit
 	transfer.AccountFrom.Currency = transfer.Currency
 	transfer.AccountFrom.Amount = transfer.Amount
 	transfer.AccountFrom.TransactionID = res_uuid
 	transfer.AccountFrom.ChargeAt = time_chargeAt
 	transfer.AccountFrom.Type = "CREDIT"
+	transfer.Type = "CREDIT" // the limits and the risk rules exclude the credits by type
 
-	transfer.Status			= "CREDIT_EVENT_CREATED"
+	transfer.Status			= model.StatusCreditEventCreated
 	transfer.TransactionID = res_uuid
 	transfer.TransferAt = time_chargeAt
 
+	// Run the risk rules
+	res_risk, err := s.evaluateRisk(ctx, tx, transfer, model.RiskOperationCredit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hold the credit for a manual review, the event is published on approval
+	if res_risk.Decision == model.RiskReview {
+		var res_transfer *model.Transfer
+		res_transfer, err = s.holdForReview(ctx, tx, transfer, model.FlowCreditEvent, res_risk)
+		if err != nil {
+			return nil, err
+		}
+		return res_transfer, nil
+	}
+
 	// Add transfer
-	res_transfer, err := s.workerRepository.AddTransfer(ctx, tx, transfer)
+	res_transfer, err := s.transferStore.AddTransfer(ctx, tx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the response of the idempotency key
+	err = s.saveIdempotency(ctx, tx, res_transfer)
 	if err != nil {
 		return nil, err
 	}
@@ -275,39 +372,35 @@ func (s *WorkerService) CreditTransferEvent(ctx context.Context, transfer *model
 
 	// publish event credit
 	childSpanKafka := tracerProvider.Span(ctx, "workerKafka.Producer")
-	err = s.workerEvent.WorkerKafka.Producer(ctx, s.workerEvent.Topics[0], key, &trace_id, payload_bytes)
+	err = s.publishEvent(ctx, tx, model.EventTypeCredit, key, &trace_id, payload_bytes)
 	if err != nil {
 		return nil, err
 	}
 	defer childSpanKafka.End()
 
-	// Just for testing (breaking) the transaction and testing kafka
-	if transfer.Currency == "USD" {
-		err =  erro.ErrCurrencyInvalid
-		return nil, err
-	}
-
 	return res_transfer, nil
 }
 
 // About add a debit transfer transaction event
-func (s *WorkerService) DebitTransferEvent(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error){
+func (s *WorkerService) DebitTransferEvent(ctx context.Context, transfer *model.Transfer) (_ *model.Transfer, err error){
 	childLogger.Info().Str("func","DebitTransferEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transfer", transfer).Send()
 
 	// Trace
 	span := tracerProvider.Span(ctx, "service.DebitTransferEvent")
+	ctx = s.withTransferDeadline(ctx)
 	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
 
 	// Get the database connection
-	tx, conn, err := s.workerRepository.DatabasePGServer.StartTx(ctx)
+	tx, err := s.unitOfWork.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Start Kafka transaction
-	err = s.workerEvent.WorkerKafka.BeginTransaction()
+	// Start Kafka transaction (the outbox mode only needs the database transaction)
+	err = s.beginEventTransaction(ctx)
 	if err != nil {
 		childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("failed to kafka BeginTransaction")
+		tx.Rollback(ctx)
 		return nil, err
 	}
 
@@ -315,66 +408,112 @@ func (s *WorkerService) DebitTransferEvent(ctx context.Context, transfer *model.
 	defer func() {
 		if err != nil {
 			childLogger.Info().Str("trace-resquest-id", trace_id ).Msg("ROLLBACK !!!!")
-			err :=  s.workerEvent.WorkerKafka.AbortTransaction(ctx)
+			err :=  s.abortEventTransaction(ctx)
 			if err != nil {
 				childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Kafka AbortTransaction")
 			}		
 			tx.Rollback(ctx)
 		} else {
-			err =  s.workerEvent.WorkerKafka.CommitTransaction(ctx)
+			err =  s.commitEventTransaction(ctx)
 			if err != nil {
 				childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Kafka CommitTransaction")
+				tx.Rollback(ctx)
+			} else {
+				err = tx.Commit(ctx)
+				if err != nil {
+					childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Commit, the events are already committed")
+				}
 			}
-			tx.Commit(ctx)
 		}
-		s.workerRepository.DatabasePGServer.ReleaseTx(conn)
 		span.End()
 	}()
 	
-	// Get transaction UUID 
-	res_uuid, err := s.workerRepository.GetTransactionUUID(ctx)
+	// Idempotency, a replay returns the original response
+	res_replay, err := s.checkIdempotency(ctx, tx)
 	if err != nil {
 		return nil, err
 	}
+	if res_replay != nil {
+		return res_replay, nil
+	}
 
-	// Get the Account ID from Account-service
-	res_acc_from, statusCode, err := apiService.CallApi(ctx,
-														s.apiService[0].Url + "/" + transfer.AccountFrom.AccountID,
-														s.apiService[0].Method,
-														&s.apiService[0].Header_x_apigw_api_id,
-														nil,
-														&trace_id,
-														nil)
+	// Get transaction UUID 
+	res_uuid, err := s.transferStore.GetTransactionUUID(ctx)
 	if err != nil {
-		return nil, errorStatusCode(statusCode)
+		return nil, err
 	}
-	jsonString, err := json.Marshal(res_acc_from)
+
+	// Get the Account ID from Account-service
+	accountFrom, err := s.getAccount(ctx, transfer.AccountFrom.AccountID)
 	if err != nil {
-		return nil, errors.New(err.Error())
+		return nil, err
 	}
-	var accountStatement model.AccountStatement
-	json.Unmarshal(jsonString, &accountStatement)
 
 	// Businness rule
-	if transfer.Amount > 0 {
+	if transfer.Amount.IsPositive() {
 		return nil, erro.ErrAmountInvalid
 	}
 
 	time_chargeAt := time.Now()
-	transfer.AccountFrom.FkAccountID = accountStatement.ID
+	transfer.AccountFrom.FkAccountID = accountFrom.ID
+	transfer.AccountFrom.TenantID = accountFrom.TenantID
 	transfer.AccountTo = transfer.AccountFrom // From and To are the same in case of Credit
 	transfer.AccountFrom.Currency = transfer.Currency
 	transfer.AccountFrom.Amount = transfer.Amount
 	transfer.AccountFrom.TransactionID = res_uuid
 	transfer.AccountFrom.ChargeAt = time_chargeAt
 	transfer.AccountFrom.Type = "DEBIT"
+	transfer.Type = "DEBIT"
 
-	transfer.Status			= "DEBIT_EVENT_CREATED"
+	transfer.Status			= model.StatusDebitEventCreated
 	transfer.TransactionID = res_uuid
 	transfer.TransferAt = time_chargeAt
 
+	// Run the risk rules
+	res_risk, err := s.evaluateRisk(ctx, tx, transfer, model.RiskOperationDebit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the limits of the account and its tenant
+	err = s.checkLimits(ctx, tx, transfer, accountFrom.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hold the debit for a manual review, the event is published on approval
+	if res_risk.Decision == model.RiskReview {
+		var res_transfer *model.Transfer
+		res_transfer, err = s.holdForReview(ctx, tx, transfer, model.FlowDebitEvent, res_risk)
+		if err != nil {
+			return nil, err
+		}
+		return res_transfer, nil
+	}
+
+	// Hold the debit for the approval of a second user (maker-checker), the event is published on approval
+	var approval bool
+	approval, err = s.requiresApproval(ctx, tx, model.FlowDebitEvent, transfer)
+	if err != nil {
+		return nil, err
+	}
+	if approval {
+		var res_transfer *model.Transfer
+		res_transfer, err = s.holdForApproval(ctx, tx, transfer, model.FlowDebitEvent)
+		if err != nil {
+			return nil, err
+		}
+		return res_transfer, nil
+	}
+
 	// Add transfer
-	res_transfer, err := s.workerRepository.AddTransfer(ctx, tx, transfer)
+	res_transfer, err := s.transferStore.AddTransfer(ctx, tx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the response of the idempotency key
+	err = s.saveIdempotency(ctx, tx, res_transfer)
 	if err != nil {
 		return nil, err
 	}
@@ -388,7 +527,7 @@ func (s *WorkerService) DebitTransferEvent(ctx context.Context, transfer *model.
 
 	// publish event debit
 	childSpanKafka := tracerProvider.Span(ctx, "workerKafka.Producer")
-	err = s.workerEvent.WorkerKafka.Producer(ctx, s.workerEvent.Topics[1], key, &trace_id, payload_bytes)
+	err = s.publishEvent(ctx, tx, model.EventTypeDebit, key, &trace_id, payload_bytes)
 	if err != nil {
 		return nil, err
 	}
@@ -398,24 +537,26 @@ func (s *WorkerService) DebitTransferEvent(ctx context.Context, transfer *model.
 }
 
 // About add a transfer transaction via event
-func (s *WorkerService) AddTransferEvent(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error){
+func (s *WorkerService) AddTransferEvent(ctx context.Context, transfer *model.Transfer) (_ *model.Transfer, err error){
 	childLogger.Info().Str("func","AddTransferEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transfer", transfer).Send()
 
 	// Trace
 	span := tracerProvider.Span(ctx, "service.AddTransferEvent")
+	ctx = s.withTransferDeadline(ctx)
 	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
 	defer span.End()
 
 	// Get the database connection
-	tx, conn, err := s.workerRepository.DatabasePGServer.StartTx(ctx)
+	tx, err := s.unitOfWork.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Start Kafka transaction
-	err = s.workerEvent.WorkerKafka.BeginTransaction()
+	// Start Kafka transaction (the outbox mode only needs the database transaction)
+	err = s.beginEventTransaction(ctx)
 	if err != nil {
 		childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("failed to kafka BeginTransaction")
+		tx.Rollback(ctx)
 		return nil, err
 	}
 
@@ -423,24 +564,37 @@ func (s *WorkerService) AddTransferEvent(ctx context.Context, transfer *model.Tr
 	defer func() {
 		if err != nil {
 			childLogger.Info().Str("trace-resquest-id", trace_id ).Msg("ROLLBACK !!!!")
-			err :=  s.workerEvent.WorkerKafka.AbortTransaction(ctx)
+			err :=  s.abortEventTransaction(ctx)
 			if err != nil {
 				childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Kafka AbortTransaction")
 			}		
 			tx.Rollback(ctx)
 		} else {
-			err =  s.workerEvent.WorkerKafka.CommitTransaction(ctx)
+			err =  s.commitEventTransaction(ctx)
 			if err != nil {
 				childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Kafka CommitTransaction")
+				tx.Rollback(ctx)
+			} else {
+				err = tx.Commit(ctx)
+				if err != nil {
+					childLogger.Error().Str("trace-resquest-id", trace_id ).Err(err).Msg("Failed to Commit, the events are already committed")
+				}
 			}
-			tx.Commit(ctx)
 		}
-		s.workerRepository.DatabasePGServer.ReleaseTx(conn)
 		span.End()
 	}()
 	
+	// Idempotency, a replay returns the original response
+	res_replay, err := s.checkIdempotency(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if res_replay != nil {
+		return res_replay, nil
+	}
+
 	// Get transaction UUID 
-	res_uuid, err := s.workerRepository.GetTransactionUUID(ctx)
+	res_uuid, err := s.transferStore.GetTransactionUUID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -454,7 +608,7 @@ func (s *WorkerService) AddTransferEvent(ctx context.Context, transfer *model.Tr
 	time_chargeAt := time.Now()
 	transfer.AccountFrom.Currency = transfer.Currency
 	transfer.AccountFrom.TransactionID = res_uuid
-	transfer.AccountFrom.Amount = (transfer.Amount * -1)
+	transfer.AccountFrom.Amount = transfer.Amount.Neg()
 	transfer.AccountFrom.Type = "DEBIT"
 	transfer.AccountFrom.ChargeAt = time_chargeAt
 
@@ -465,50 +619,77 @@ func (s *WorkerService) AddTransferEvent(ctx context.Context, transfer *model.Tr
 	transfer.AccountTo.ChargeAt = time_chargeAt
 
 	transfer.TransactionID = res_uuid
-	transfer.Status = "TRANSFER-EVENT-CREATED"
+	transfer.Status = model.StatusTransferEventCreated
 	transfer.TransferAt = time_chargeAt
 
 	// Get the Account ID from Account-service
-	res_acc_from, statusCode, err := apiService.CallApi(ctx,
-														s.apiService[0].Url + "/" + transfer.AccountFrom.AccountID,
-														s.apiService[0].Method,
-														&s.apiService[0].Header_x_apigw_api_id,
-														nil,
-														&trace_id,
-														nil)
+	accountFrom, err := s.getAccount(ctx, transfer.AccountFrom.AccountID)
 	if err != nil {
-		return nil, errorStatusCode(statusCode)
+		return nil, err
 	}
-	jsonString, err := json.Marshal(res_acc_from)
+
+	transfer.AccountFrom.FkAccountID = accountFrom.ID
+	transfer.AccountFrom.TenantID = accountFrom.TenantID
+
+	// Get the Account ID from Account-service
+	accountTo, err := s.getAccount(ctx, transfer.AccountTo.AccountID)
 	if err != nil {
-		return nil, errors.New(err.Error())
+		return nil, err
 	}
-	var accountStatement model.AccountStatement
-	json.Unmarshal(jsonString, &accountStatement)
 
-	transfer.AccountFrom.FkAccountID = accountStatement.ID
+	transfer.AccountTo.FkAccountID = accountTo.ID
 
-	// Get the Account ID from Account-service
-	res_acc_to, statusCode, err := apiService.CallApi(ctx,
-														s.apiService[0].Url + "/" + transfer.AccountTo.AccountID,
-														s.apiService[0].Method,
-														&s.apiService[0].Header_x_apigw_api_id,
-														nil,
-														&trace_id,
-														nil)
+	// Convert the credit leg into the currency of the destination account
+	err = s.applyFx(ctx, tx, transfer, accountTo.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run the risk rules
+	res_risk, err := s.evaluateRisk(ctx, tx, transfer, model.RiskOperationTransfer)
 	if err != nil {
-		return nil, errorStatusCode(statusCode)
+		return nil, err
 	}
-	jsonString, err = json.Marshal(res_acc_to)
+
+	// Check the limits of the source account and its tenant
+	err = s.checkLimits(ctx, tx, transfer, accountFrom.TenantID)
 	if err != nil {
-		return nil, errors.New(err.Error())
+		return nil, err
 	}
-	json.Unmarshal(jsonString, &accountStatement)
 
-	transfer.AccountTo.FkAccountID = accountStatement.ID
+	// Hold the transfer for a manual review, the event is published on approval
+	if res_risk.Decision == model.RiskReview {
+		var res_transfer *model.Transfer
+		res_transfer, err = s.holdForReview(ctx, tx, transfer, model.FlowTransferEvent, res_risk)
+		if err != nil {
+			return nil, err
+		}
+		return res_transfer, nil
+	}
+
+	// Hold the transfer for the approval of a second user (maker-checker), the event is published on approval
+	var approval bool
+	approval, err = s.requiresApproval(ctx, tx, model.FlowTransferEvent, transfer)
+	if err != nil {
+		return nil, err
+	}
+	if approval {
+		var res_transfer *model.Transfer
+		res_transfer, err = s.holdForApproval(ctx, tx, transfer, model.FlowTransferEvent)
+		if err != nil {
+			return nil, err
+		}
+		return res_transfer, nil
+	}
 
 	// Add transfer
-	res_transfer, err := s.workerRepository.AddTransfer(ctx, tx, transfer)
+	res_transfer, err := s.transferStore.AddTransfer(ctx, tx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the response of the idempotency key
+	err = s.saveIdempotency(ctx, tx, res_transfer)
 	if err != nil {
 		return nil, err
 	}
@@ -522,7 +703,7 @@ func (s *WorkerService) AddTransferEvent(ctx context.Context, transfer *model.Tr
 
 	// publish event transfer
 	childSpanKafka := tracerProvider.Span(ctx, "workerKafka.Producer")
-	err = s.workerEvent.WorkerKafka.Producer(ctx, s.workerEvent.Topics[2], key, &trace_id, payload_bytes)
+	err = s.publishEvent(ctx, tx, model.EventTypeTransfer, key, &trace_id, payload_bytes)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"context"
+	"testing"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-fund-transfer/internal/core/erro"
+	"github.com/go-fund-transfer/internal/core/model"
+	"github.com/go-fund-transfer/internal/adapter/account/accounttest"
+)
+
+// About check that a failed use case left no transfer behind (its transaction was rolled back)
+func assertNoTransfer(t *testing.T, ts *testService) {
+	t.Helper()
+
+	_, err := ts.service.GetTransfer(context.Background(), &model.Transfer{ID: 1})
+	if !errors.Is(err, erro.ErrNotFound) {
+		t.Errorf("GetTransfer err = %v, want %s", err, erro.ErrNotFound.Code)
+	}
+}
+
+func TestAddTransfer(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	res_transfer, err := ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 1500))
+	if err != nil {
+		t.Fatalf("AddTransfer: %v", err)
+	}
+	if res_transfer.ID <= 0 || res_transfer.Status != model.StatusTransferRestDone || res_transfer.TransactionID == nil {
+		t.Fatalf("transfer = %+v, want an id, a transaction_id and %s", res_transfer, model.StatusTransferRestDone)
+	}
+
+	// the saga posted both legs
+	debits, credits := ts.accounts.Debits(), ts.accounts.Credits()
+	if len(debits) != 1 || len(credits) != 1 {
+		t.Fatalf("debits = %d, credits = %d, want 1 and 1", len(debits), len(credits))
+	}
+	if debits[0].AccountID != "ACC-1" || debits[0].Amount.Minor != -1500 {
+		t.Errorf("debit = %s %d, want ACC-1 -1500", debits[0].AccountID, debits[0].Amount.Minor)
+	}
+	if credits[0].AccountID != "ACC-2" || credits[0].Amount.Minor != 1500 {
+		t.Errorf("credit = %s %d, want ACC-2 1500", credits[0].AccountID, credits[0].Amount.Minor)
+	}
+
+	res_get, err := ts.service.GetTransfer(ctx, &model.Transfer{ID: res_transfer.ID})
+	if err != nil {
+		t.Fatalf("GetTransfer: %v", err)
+	}
+	if res_get.Status != model.StatusTransferRestDone || res_get.AccountFrom.AccountID != "ACC-1" || res_get.AccountTo.AccountID != "ACC-2" {
+		t.Errorf("stored transfer = %+v, want ACC-1 to ACC-2 %s", res_get, model.StatusTransferRestDone)
+	}
+	if res_get.Amount.Minor != 1500 || *res_get.TransactionID != *res_transfer.TransactionID {
+		t.Errorf("stored transfer amount = %d transaction_id = %s, want 1500 %s", res_get.Amount.Minor, *res_get.TransactionID, *res_transfer.TransactionID)
+	}
+}
+
+func TestAddTransferError(t *testing.T) {
+	tests := []struct {
+		name		string
+		transfer	func(t *testing.T) *model.Transfer
+		debit		int
+		want		*erro.DomainError
+	}{
+		{ "type invalid", func(t *testing.T) *model.Transfer {
+				transfer := newTestTransfer(t, "ACC-1", "ACC-2", 100)
+				transfer.Type = "CREDIT"
+				return transfer
+			}, 0, erro.ErrTransInvalid },
+		{ "source account unknown", func(t *testing.T) *model.Transfer {
+				return newTestTransfer(t, "ACC-404", "ACC-2", 100)
+			}, 0, erro.ErrAccountNotFound },
+		{ "destination account unknown", func(t *testing.T) *model.Transfer {
+				return newTestTransfer(t, "ACC-1", "ACC-404", 100)
+			}, 0, erro.ErrAccountNotFound },
+		{ "debit rejected", func(t *testing.T) *model.Transfer {
+				return newTestTransfer(t, "ACC-1", "ACC-2", 100)
+			}, http.StatusBadRequest, erro.ErrStatementRejected },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, false)
+			if tt.debit != 0 {
+				ts.accounts.Respond(accounttest.ServiceDebit, tt.debit, `{"msg":"forced"}`)
+			}
+
+			_, err := ts.service.AddTransfer(context.Background(), tt.transfer(t))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %s", err, tt.want.Code)
+			}
+			if len(ts.accounts.Credits()) != 0 {
+				t.Errorf("credits = %d, want 0", len(ts.accounts.Credits()))
+			}
+			assertNoTransfer(t, ts)
+		})
+	}
+}
+
+func TestAddTransferIdempotency(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := withTestIdempotencyKey(context.Background(), "key-1", "hash-1")
+
+	first, err := ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 100))
+	if err != nil {
+		t.Fatalf("AddTransfer: %v", err)
+	}
+
+	// a replay returns the original response, the saga does not run again
+	again, err := ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 100))
+	if err != nil {
+		t.Fatalf("AddTransfer replay: %v", err)
+	}
+	if again.ID != first.ID || len(ts.accounts.Debits()) != 1 {
+		t.Errorf("replay id = %d, debits = %d, want %d and 1", again.ID, len(ts.accounts.Debits()), first.ID)
+	}
+
+	// the same key with another request is refused
+	ctx = withTestIdempotencyKey(context.Background(), "key-1", "hash-2")
+	_, err = ts.service.AddTransfer(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 200))
+	if !errors.Is(err, erro.ErrIdempotencyKey) {
+		t.Errorf("err = %v, want %s", err, erro.ErrIdempotencyKey.Code)
+	}
+}
+
+func TestCreditTransferEvent(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	res_transfer, err := ts.service.CreditTransferEvent(ctx, newTestStatement(t, "ACC-1", 300))
+	if err != nil {
+		t.Fatalf("CreditTransferEvent: %v", err)
+	}
+	if res_transfer.ID <= 0 || res_transfer.Status != model.StatusCreditEventCreated || res_transfer.AccountFrom.Type != "CREDIT" {
+		t.Fatalf("transfer = %+v, want a CREDIT %s", res_transfer, model.StatusCreditEventCreated)
+	}
+
+	res_get, err := ts.service.GetTransfer(ctx, &model.Transfer{ID: res_transfer.ID})
+	if err != nil {
+		t.Fatalf("GetTransfer: %v", err)
+	}
+	if res_get.Status != model.StatusCreditEventCreated || res_get.Amount.Minor != 300 {
+		t.Errorf("stored transfer = %+v, want 300 %s", res_get, model.StatusCreditEventCreated)
+	}
+
+	// the credit is posted by the consumer of the event, not here
+	if len(ts.accounts.Credits()) != 0 {
+		t.Errorf("credits = %d, want 0", len(ts.accounts.Credits()))
+	}
+}
+
+func TestDebitTransferEvent(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	res_transfer, err := ts.service.DebitTransferEvent(ctx, newTestStatement(t, "ACC-1", -300))
+	if err != nil {
+		t.Fatalf("DebitTransferEvent: %v", err)
+	}
+	if res_transfer.ID <= 0 || res_transfer.Status != model.StatusDebitEventCreated || res_transfer.AccountFrom.Type != "DEBIT" {
+		t.Fatalf("transfer = %+v, want a DEBIT %s", res_transfer, model.StatusDebitEventCreated)
+	}
+
+	res_get, err := ts.service.GetTransfer(ctx, &model.Transfer{ID: res_transfer.ID})
+	if err != nil {
+		t.Fatalf("GetTransfer: %v", err)
+	}
+	if res_get.Status != model.StatusDebitEventCreated || res_get.Amount.Minor != -300 {
+		t.Errorf("stored transfer = %+v, want -300 %s", res_get, model.StatusDebitEventCreated)
+	}
+	if len(ts.accounts.Debits()) != 0 {
+		t.Errorf("debits = %d, want 0", len(ts.accounts.Debits()))
+	}
+}
+
+func TestAddTransferEvent(t *testing.T) {
+	ts := newTestService(t, false)
+	ctx := context.Background()
+
+	res_transfer, err := ts.service.AddTransferEvent(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 700))
+	if err != nil {
+		t.Fatalf("AddTransferEvent: %v", err)
+	}
+	if res_transfer.ID <= 0 || res_transfer.Status != model.StatusTransferEventCreated {
+		t.Fatalf("transfer = %+v, want %s", res_transfer, model.StatusTransferEventCreated)
+	}
+
+	res_get, err := ts.service.GetTransfer(ctx, &model.Transfer{ID: res_transfer.ID})
+	if err != nil {
+		t.Fatalf("GetTransfer: %v", err)
+	}
+	if res_get.Status != model.StatusTransferEventCreated || res_get.AccountFrom.AccountID != "ACC-1" || res_get.AccountTo.AccountID != "ACC-2" {
+		t.Errorf("stored transfer = %+v, want ACC-1 to ACC-2 %s", res_get, model.StatusTransferEventCreated)
+	}
+	if len(ts.accounts.Debits()) != 0 || len(ts.accounts.Credits()) != 0 {
+		t.Errorf("debits = %d, credits = %d, want 0 and 0", len(ts.accounts.Debits()), len(ts.accounts.Credits()))
+	}
+}
+
+func TestTransferEventError(t *testing.T) {
+	tests := []struct {
+		name		string
+		call		func(ctx context.Context, t *testing.T, s *WorkerService) error
+		want		*erro.DomainError
+	}{
+		{ "credit negative", func(ctx context.Context, t *testing.T, s *WorkerService) error {
+				_, err := s.CreditTransferEvent(ctx, newTestStatement(t, "ACC-1", -100))
+				return err
+			}, erro.ErrAmountInvalid },
+		{ "credit account unknown", func(ctx context.Context, t *testing.T, s *WorkerService) error {
+				_, err := s.CreditTransferEvent(ctx, newTestStatement(t, "ACC-404", 100))
+				return err
+			}, erro.ErrAccountNotFound },
+		{ "debit positive", func(ctx context.Context, t *testing.T, s *WorkerService) error {
+				_, err := s.DebitTransferEvent(ctx, newTestStatement(t, "ACC-1", 100))
+				return err
+			}, erro.ErrAmountInvalid },
+		{ "debit account unknown", func(ctx context.Context, t *testing.T, s *WorkerService) error {
+				_, err := s.DebitTransferEvent(ctx, newTestStatement(t, "ACC-404", -100))
+				return err
+			}, erro.ErrAccountNotFound },
+		{ "transfer type invalid", func(ctx context.Context, t *testing.T, s *WorkerService) error {
+				transfer := newTestTransfer(t, "ACC-1", "ACC-2", 100)
+				transfer.Type = "DEBIT"
+				_, err := s.AddTransferEvent(ctx, transfer)
+				return err
+			}, erro.ErrTransInvalid },
+		{ "transfer account unknown", func(ctx context.Context, t *testing.T, s *WorkerService) error {
+				_, err := s.AddTransferEvent(ctx, newTestTransfer(t, "ACC-1", "ACC-404", 100))
+				return err
+			}, erro.ErrAccountNotFound },
+		{ "transfer account service down", func(ctx context.Context, t *testing.T, s *WorkerService) error {
+				_, err := s.AddTransferEvent(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 100))
+				return err
+			}, erro.ErrServer },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, false)
+			if tt.want == erro.ErrServer {
+				ts.accounts.Respond(accounttest.ServiceAccount, http.StatusInternalServerError, `{"msg":"forced"}`)
+			}
+
+			err := tt.call(context.Background(), t, ts.service)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %s", err, tt.want.Code)
+			}
+			assertNoTransfer(t, ts)
+			if len(ts.publisher.Published()) != 0 {
+				t.Errorf("published = %d, want 0", len(ts.publisher.Published()))
+			}
+		})
+	}
+}
+
+func TestTransferEventPublished(t *testing.T) {
+	tests := []struct {
+		name		string
+		call		func(ctx context.Context, t *testing.T, s *WorkerService) (*model.Transfer, error)
+		eventType	string
+	}{
+		{ "credit", func(ctx context.Context, t *testing.T, s *WorkerService) (*model.Transfer, error) {
+				return s.CreditTransferEvent(ctx, newTestStatement(t, "ACC-1", 100))
+			}, model.EventTypeCredit },
+		{ "debit", func(ctx context.Context, t *testing.T, s *WorkerService) (*model.Transfer, error) {
+				return s.DebitTransferEvent(ctx, newTestStatement(t, "ACC-1", -100))
+			}, model.EventTypeDebit },
+		{ "transfer", func(ctx context.Context, t *testing.T, s *WorkerService) (*model.Transfer, error) {
+				return s.AddTransferEvent(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 100))
+			}, model.EventTypeTransfer },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, false)
+			ctx := context.WithValue(context.Background(), "trace-request-id", "trace-1")
+
+			res_transfer, err := tt.call(ctx, t, ts.service)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+
+			// one message, on the topic of its type, keyed by the transfer id
+			published := ts.publisher.Published()
+			if len(published) != 1 {
+				t.Fatalf("published = %d, want 1", len(published))
+			}
+			message := published[0]
+			if message.Topic != testEventRouting[tt.eventType] || message.EventType != tt.eventType {
+				t.Errorf("message topic = %s type = %s, want %s %s", message.Topic, message.EventType, testEventRouting[tt.eventType], tt.eventType)
+			}
+			if message.Key != strconv.Itoa(res_transfer.ID) || message.TraceID != "trace-1" {
+				t.Errorf("message key = %s trace = %s, want %d trace-1", message.Key, message.TraceID, res_transfer.ID)
+			}
+
+			var payload model.Transfer
+			err = json.Unmarshal(message.Payload, &payload)
+			if err != nil {
+				t.Fatalf("payload: %v", err)
+			}
+			if payload.ID != res_transfer.ID || *payload.TransactionID != *res_transfer.TransactionID {
+				t.Errorf("payload = %+v, want the transfer %d", payload, res_transfer.ID)
+			}
+			if len(ts.publisher.Aborted()) != 0 {
+				t.Errorf("aborted = %d, want 0", len(ts.publisher.Aborted()))
+			}
+		})
+	}
+}
+
+func TestTransferEventPublishFailed(t *testing.T) {
+	tests := []struct {
+		name		string
+		call		func(ctx context.Context, t *testing.T, s *WorkerService) error
+	}{
+		{ "credit", func(ctx context.Context, t *testing.T, s *WorkerService) error {
+				_, err := s.CreditTransferEvent(ctx, newTestStatement(t, "ACC-1", 100))
+				return err
+			} },
+		{ "debit", func(ctx context.Context, t *testing.T, s *WorkerService) error {
+				_, err := s.DebitTransferEvent(ctx, newTestStatement(t, "ACC-1", -100))
+				return err
+			} },
+		{ "transfer", func(ctx context.Context, t *testing.T, s *WorkerService) error {
+				_, err := s.AddTransferEvent(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 100))
+				return err
+			} },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, false)
+			errBroker := errors.New("broker unavailable")
+			ts.publisher.Err = errBroker
+
+			// the event is not published, the transfer is rolled back with it
+			err := tt.call(context.Background(), t, ts.service)
+			if !errors.Is(err, errBroker) {
+				t.Fatalf("err = %v, want %v", err, errBroker)
+			}
+			assertNoTransfer(t, ts)
+			if len(ts.publisher.Published()) != 0 {
+				t.Errorf("published = %d, want 0", len(ts.publisher.Published()))
+			}
+		})
+	}
+}
+
+func TestTransferEventCommitFailed(t *testing.T) {
+	tests := []struct {
+		name	string
+		run		func(ctx context.Context, ts *testService) error
+	}{
+		{ "credit", func(ctx context.Context, ts *testService) error {
+			_, err := ts.service.CreditTransferEvent(ctx, newTestStatement(t, "ACC-1", 100))
+			return err
+		} },
+		{ "debit", func(ctx context.Context, ts *testService) error {
+			_, err := ts.service.DebitTransferEvent(ctx, newTestStatement(t, "ACC-1", -100))
+			return err
+		} },
+		{ "transfer", func(ctx context.Context, ts *testService) error {
+			_, err := ts.service.AddTransferEvent(ctx, newTestTransfer(t, "ACC-1", "ACC-2", 100))
+			return err
+		} },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, true)
+			ts.repository.CommitErr = errors.New("connection lost")
+
+			// the failed commit is returned, nothing is stored
+			err := tt.run(context.Background(), ts)
+			if err == nil || err.Error() != "connection lost" {
+				t.Fatalf("err = %v, want the commit error", err)
+			}
+			ts.repository.CommitErr = nil
+
+			assertNoTransfer(t, ts)
+			if len(listOutboxPending(t, ts)) != 0 {
+				t.Errorf("pending = %d, want 0", len(listOutboxPending(t, ts)))
+			}
+		})
+	}
+}
+
+func TestGetTransferNotFound(t *testing.T) {
+	ts := newTestService(t, false)
+
+	_, err := ts.service.GetTransfer(context.Background(), &model.Transfer{ID: 42})
+	if !errors.Is(err, erro.ErrNotFound) {
+		t.Errorf("err = %v, want %s", err, erro.ErrNotFound.Code)
+	}
+}
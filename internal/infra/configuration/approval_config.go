@@ -0,0 +1,27 @@
+package configuration
+
+import(
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About get approval env var
+func GetApprovalEnv() model.ApprovalConfig {
+	childLogger.Info().Str("func","GetApprovalEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var approvalConfig model.ApprovalConfig
+	approvalConfig.Role = "TRANSFER_APPROVER"
+
+	if os.Getenv("APPROVAL_ROLE") !=  "" {
+		approvalConfig.Role = os.Getenv("APPROVAL_ROLE")
+	}
+
+	return approvalConfig
+}
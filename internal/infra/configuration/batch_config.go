@@ -0,0 +1,34 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About get batch env var
+func GetBatchEnv() model.BatchConfig {
+	childLogger.Info().Str("func","GetBatchEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var batchConfig model.BatchConfig
+	batchConfig.Concurrency = 10
+	batchConfig.MaxItems = 500
+
+	if os.Getenv("BATCH_CONCURRENCY") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("BATCH_CONCURRENCY"))
+		batchConfig.Concurrency = intVar
+	}
+	if os.Getenv("BATCH_MAX_ITEMS") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("BATCH_MAX_ITEMS"))
+		batchConfig.MaxItems = intVar
+	}
+
+	return batchConfig
+}
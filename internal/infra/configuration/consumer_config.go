@@ -0,0 +1,73 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About get the completion events consumer env var, the consumer starts only when TOPIC_COMPLETION is set.
+// A number that does not parse is kept invalid, the config is refused at the boot
+func GetConsumerEnv() model.ConsumerConfig {
+	childLogger.Info().Str("func","GetConsumerEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var consumerConfig model.ConsumerConfig
+	consumerConfig.GroupID = "GROUP-GO-FUND-TRANSFER"
+	consumerConfig.MaxAttempts = 5
+	consumerConfig.RetryBackoff = 1000
+	consumerConfig.RetryBackoffMax = 30000
+	consumerConfig.MaxPollInterval = 300000
+
+	if os.Getenv("KAFKA_GROUP_ID") !=  "" {
+		consumerConfig.GroupID = os.Getenv("KAFKA_GROUP_ID")
+	}
+	// a comma separated list of topics
+	if os.Getenv("TOPIC_COMPLETION") !=  "" {
+		for _, topic := range strings.Split(os.Getenv("TOPIC_COMPLETION"), ",") {
+			if strings.TrimSpace(topic) != "" {
+				consumerConfig.Topics = append(consumerConfig.Topics, strings.TrimSpace(topic))
+			}
+		}
+	}
+	consumerConfig.Enabled = len(consumerConfig.Topics) > 0
+	consumerConfig.DeadLetterTopic = strings.TrimSpace(os.Getenv("TOPIC_COMPLETION_DEAD_LETTER"))
+
+	if os.Getenv("CONSUMER_MAX_ATTEMPTS") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("CONSUMER_MAX_ATTEMPTS"))
+		if err != nil {
+			intVar = 0
+		}
+		consumerConfig.MaxAttempts = intVar
+	}
+	if os.Getenv("CONSUMER_RETRY_BACKOFF_MS") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("CONSUMER_RETRY_BACKOFF_MS"))
+		if err != nil {
+			intVar = 0
+		}
+		consumerConfig.RetryBackoff = intVar
+	}
+	if os.Getenv("CONSUMER_RETRY_BACKOFF_MAX_MS") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("CONSUMER_RETRY_BACKOFF_MAX_MS"))
+		if err != nil {
+			intVar = 0
+		}
+		consumerConfig.RetryBackoffMax = intVar
+	}
+	if os.Getenv("CONSUMER_MAX_POLL_INTERVAL_MS") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("CONSUMER_MAX_POLL_INTERVAL_MS"))
+		if err != nil {
+			intVar = 0
+		}
+		consumerConfig.MaxPollInterval = intVar
+	}
+
+	return consumerConfig
+}
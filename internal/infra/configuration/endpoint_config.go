@@ -1,81 +1,155 @@
-package configuration
-
-import(
-	"os"
-	"github.com/joho/godotenv"
-	"github.com/go-fund-transfer/internal/core/model"
-)
-
-// About get service´s endpoint env var
-func GetEndpointEnv() []model.ApiService {
-	childLogger.Info().Str("func","GetEndpointEnv").Send()
-
-	err := godotenv.Load(".env")
-	if err != nil {
-		childLogger.Info().Err(err).Send()
-	}
-	
-	var apiService []model.ApiService
-
-	var apiService01 model.ApiService
-	if os.Getenv("URL_SERVICE_01") !=  "" {
-		apiService01.Url = os.Getenv("URL_SERVICE_01")
-	}
-	if os.Getenv("X_APIGW_API_ID_SERVICE_01") !=  "" {
-		apiService01.Header_x_apigw_api_id = os.Getenv("X_APIGW_API_ID_SERVICE_01")
-	}
-	if os.Getenv("METHOD_SERVICE_01") !=  "" {
-		apiService01.Method = os.Getenv("METHOD_SERVICE_01")
-	}
-	if os.Getenv("NAME_SERVICE_01") !=  "" {
-		apiService01.Name = os.Getenv("NAME_SERVICE_01")
-	}
-	apiService = append(apiService, apiService01)
-
-	var apiService02 model.ApiService
-	if os.Getenv("URL_SERVICE_02") !=  "" {
-		apiService02.Url = os.Getenv("URL_SERVICE_02")
-	}
-	if os.Getenv("X_APIGW_API_ID_SERVICE_02") !=  "" {
-		apiService02.Header_x_apigw_api_id = os.Getenv("X_APIGW_API_ID_SERVICE_02")
-	}
-	if os.Getenv("METHOD_SERVICE_02") !=  "" {
-		apiService02.Method = os.Getenv("METHOD_SERVICE_02")
-	}
-	if os.Getenv("NAME_SERVICE_02") !=  "" {
-		apiService02.Name = os.Getenv("NAME_SERVICE_02")
-	}
-	apiService = append(apiService, apiService02)
-
-	var apiService03 model.ApiService
-	if os.Getenv("URL_SERVICE_03") !=  "" {
-		apiService03.Url = os.Getenv("URL_SERVICE_03")
-	}
-	if os.Getenv("X_APIGW_API_ID_SERVICE_03") !=  "" {
-		apiService03.Header_x_apigw_api_id = os.Getenv("X_APIGW_API_ID_SERVICE_03")
-	}
-	if os.Getenv("METHOD_SERVICE_03") !=  "" {
-		apiService03.Method = os.Getenv("METHOD_SERVICE_03")
-	}
-	if os.Getenv("NAME_SERVICE_03") !=  "" {
-		apiService03.Name = os.Getenv("NAME_SERVICE_03")
-	}
-	apiService = append(apiService, apiService03)
-
-	var apiService04 model.ApiService
-	if os.Getenv("URL_SERVICE_04") !=  "" {
-		apiService04.Url = os.Getenv("URL_SERVICE_04")
-	}
-	if os.Getenv("X_APIGW_API_ID_SERVICE_04") !=  "" {
-		apiService04.Header_x_apigw_api_id = os.Getenv("X_APIGW_API_ID_SERVICE_04")
-	}
-	if os.Getenv("METHOD_SERVICE_04") !=  "" {
-		apiService04.Method = os.Getenv("METHOD_SERVICE_04")
-	}
-	if os.Getenv("NAME_SERVICE_04") !=  "" {
-		apiService04.Name = os.Getenv("NAME_SERVICE_04")
-	}
-	apiService = append(apiService, apiService04)
-
-	return apiService
-}
\ No newline at end of file
+package configuration
+
+import(
+	"os"
+	"strings"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About get service´s endpoint env var. An endpoint is declared by ENDPOINT_<NAME>_URL, its logical name
+// is <NAME> in lower case (ENDPOINT_ACCOUNT_URL is the endpoint account). The other settings are
+// ENDPOINT_<NAME>_SERVICE, _METHOD, _X_APIGW_API_ID, _TIMEOUT_MS and _HEADERS (Name:Value,Name:Value), _IDEMPOTENT
+// (true when the service honors the Idempotency-Key header, a POST is retried only then), the retries
+// _RETRY_MAX, _RETRY_BACKOFF_MS, _RETRY_BACKOFF_MAX_MS and _RETRY_STATUS (status,status),
+// the breaker _BREAKER_FAILURES (0 disables it), _BREAKER_OPEN_MS and _BREAKER_HALF_OPEN_CALLS, the bulkhead
+// _MAX_CONCURRENT (0 is unlimited) and _BULKHEAD_WAIT_MS.
+// A number that does not parse is kept invalid, the endpoint is refused at the boot
+func GetEndpointEnv() map[string]model.ApiService {
+	childLogger.Info().Str("func","GetEndpointEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	endpoints := map[string]model.ApiService{}
+
+	for _, env := range os.Environ() {
+		key, _, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(key, "ENDPOINT_") || !strings.HasSuffix(key, "_URL") {
+			continue
+		}
+		prefix := strings.TrimSuffix(key, "URL")
+		name := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(key, "ENDPOINT_"), "_URL"))
+		if name == "" {
+			continue
+		}
+
+		apiService := model.ApiService{	Name: name,
+										Url: os.Getenv(key),
+										Timeout: model.DefaultEndpointTimeout,
+										Retry: model.RetryConfig{	Max: model.DefaultRetryMax,
+																	Backoff: model.DefaultRetryBackoff,
+																	BackoffMax: model.DefaultRetryBackoffMax,
+																	Status: model.DefaultRetryStatus },
+										Breaker: model.BreakerConfig{	Failures: model.DefaultBreakerFailures,
+																		OpenTimeout: model.DefaultBreakerOpenTimeout,
+																		HalfOpenCalls: model.DefaultBreakerHalfOpenCalls },
+										MaxConcurrent: model.DefaultMaxConcurrent }
+
+		if os.Getenv(prefix + "SERVICE") !=  "" {
+			apiService.Name = os.Getenv(prefix + "SERVICE")
+		}
+		if os.Getenv(prefix + "METHOD") !=  "" {
+			apiService.Method = strings.ToUpper(os.Getenv(prefix + "METHOD"))
+		}
+		if os.Getenv(prefix + "X_APIGW_API_ID") !=  "" {
+			apiService.Header_x_apigw_api_id = os.Getenv(prefix + "X_APIGW_API_ID")
+		}
+		if os.Getenv(prefix + "TIMEOUT_MS") !=  "" {
+			intVar, err := strconv.Atoi(os.Getenv(prefix + "TIMEOUT_MS"))
+			if err != nil {
+				intVar = 0
+			}
+			apiService.Timeout = intVar
+		}
+		if os.Getenv(prefix + "IDEMPOTENT") !=  "" {
+			boolVar, err := strconv.ParseBool(os.Getenv(prefix + "IDEMPOTENT"))
+			if err != nil {
+				childLogger.Error().Err(err).Str("env", prefix + "IDEMPOTENT").Msg("not a boolean, the endpoint is not idempotent")
+				boolVar = false
+			}
+			apiService.Idempotent = boolVar
+		}
+		if os.Getenv(prefix + "RETRY_MAX") !=  "" {
+			intVar, err := strconv.Atoi(os.Getenv(prefix + "RETRY_MAX"))
+			if err != nil {
+				intVar = -1
+			}
+			apiService.Retry.Max = intVar
+		}
+		if os.Getenv(prefix + "RETRY_BACKOFF_MS") !=  "" {
+			intVar, err := strconv.Atoi(os.Getenv(prefix + "RETRY_BACKOFF_MS"))
+			if err != nil {
+				intVar = -1
+			}
+			apiService.Retry.Backoff = intVar
+		}
+		if os.Getenv(prefix + "RETRY_BACKOFF_MAX_MS") !=  "" {
+			intVar, err := strconv.Atoi(os.Getenv(prefix + "RETRY_BACKOFF_MAX_MS"))
+			if err != nil {
+				intVar = -1
+			}
+			apiService.Retry.BackoffMax = intVar
+		}
+		if os.Getenv(prefix + "RETRY_STATUS") !=  "" {
+			apiService.Retry.Status = []int{}
+			for _, status := range strings.Split(os.Getenv(prefix + "RETRY_STATUS"), ",") {
+				intVar, err := strconv.Atoi(strings.TrimSpace(status))
+				if err != nil {
+					intVar = 0
+				}
+				apiService.Retry.Status = append(apiService.Retry.Status, intVar)
+			}
+		}
+		if os.Getenv(prefix + "BREAKER_FAILURES") !=  "" {
+			intVar, err := strconv.Atoi(os.Getenv(prefix + "BREAKER_FAILURES"))
+			if err != nil {
+				intVar = -1
+			}
+			apiService.Breaker.Failures = intVar
+		}
+		if os.Getenv(prefix + "BREAKER_OPEN_MS") !=  "" {
+			intVar, err := strconv.Atoi(os.Getenv(prefix + "BREAKER_OPEN_MS"))
+			if err != nil {
+				intVar = 0
+			}
+			apiService.Breaker.OpenTimeout = intVar
+		}
+		if os.Getenv(prefix + "BREAKER_HALF_OPEN_CALLS") !=  "" {
+			intVar, err := strconv.Atoi(os.Getenv(prefix + "BREAKER_HALF_OPEN_CALLS"))
+			if err != nil {
+				intVar = 0
+			}
+			apiService.Breaker.HalfOpenCalls = intVar
+		}
+		if os.Getenv(prefix + "MAX_CONCURRENT") !=  "" {
+			intVar, err := strconv.Atoi(os.Getenv(prefix + "MAX_CONCURRENT"))
+			if err != nil {
+				intVar = -1
+			}
+			apiService.MaxConcurrent = intVar
+		}
+		if os.Getenv(prefix + "BULKHEAD_WAIT_MS") !=  "" {
+			intVar, err := strconv.Atoi(os.Getenv(prefix + "BULKHEAD_WAIT_MS"))
+			if err != nil {
+				intVar = -1
+			}
+			apiService.BulkheadWait = intVar
+		}
+		if os.Getenv(prefix + "HEADERS") !=  "" {
+			apiService.Headers = map[string]string{}
+			for _, header := range strings.Split(os.Getenv(prefix + "HEADERS"), ",") {
+				header_name, header_value, _ := strings.Cut(header, ":")
+				apiService.Headers[strings.TrimSpace(header_name)] = strings.TrimSpace(header_value)
+			}
+		}
+
+		endpoints[name] = apiService
+	}
+
+	return endpoints
+}
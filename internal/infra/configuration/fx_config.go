@@ -0,0 +1,37 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About get fx env var
+func GetFxEnv() model.FxConfig {
+	childLogger.Info().Str("func","GetFxEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var fxConfig model.FxConfig
+	fxConfig.QuoteTTL = 60
+	fxConfig.FeeBps = 0
+
+	if os.Getenv("FX_RATE_FILE") !=  "" {
+		fxConfig.RateFile = os.Getenv("FX_RATE_FILE")
+	}
+	if os.Getenv("FX_QUOTE_TTL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("FX_QUOTE_TTL"))
+		fxConfig.QuoteTTL = intVar
+	}
+	if os.Getenv("FX_FEE_BPS") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("FX_FEE_BPS"))
+		fxConfig.FeeBps = intVar
+	}
+
+	return fxConfig
+}
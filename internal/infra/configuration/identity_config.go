@@ -0,0 +1,32 @@
+package configuration
+
+import(
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About get identity env var. The secret shared with the api gateway is read from the pod secret
+// (/var/pod/secret/identity_secret), IDENTITY_SECRET is the fallback for a local run
+func GetIdentityEnv() model.IdentityConfig {
+	childLogger.Info().Str("func","GetIdentityEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var identityConfig model.IdentityConfig
+
+	file_secret, err := os.ReadFile("/var/pod/secret/identity_secret")
+	if err == nil {
+		identityConfig.Secret = strings.TrimSpace(string(file_secret))
+	}
+	if identityConfig.Secret == "" && os.Getenv("IDENTITY_SECRET") !=  "" {
+		identityConfig.Secret = os.Getenv("IDENTITY_SECRET")
+	}
+
+	return identityConfig
+}
@@ -1,70 +1,69 @@
-package configuration
-
-import(
-	"os"
-	"strconv"
-
-	"github.com/joho/godotenv"
-	go_core_event "github.com/eliezerraj/go-core/event/kafka" 
-)
-
-// About get kafka env var
-func GetKafkaEnv() (go_core_event.KafkaConfigurations, []string) {
-	childLogger.Info().Str("func","GetKafkaEnv").Send()
-
-	err := godotenv.Load(".env")
-	if err != nil {
-		childLogger.Info().Send()
-	}
-
-	var kafkaConfigurations go_core_event.KafkaConfigurations
-
-	if os.Getenv("KAFKA_USER") !=  "" {
-		kafkaConfigurations.Username = os.Getenv("KAFKA_USER")
-	}
-	if os.Getenv("KAFKA_PASSWORD") !=  "" {
-		kafkaConfigurations.Password = os.Getenv("KAFKA_PASSWORD")
-	}
-	if os.Getenv("KAFKA_PROTOCOL") !=  "" {
-		kafkaConfigurations.Protocol = os.Getenv("KAFKA_PROTOCOL")
-	}
-	if os.Getenv("KAFKA_MECHANISM") !=  "" {
-		kafkaConfigurations.Mechanisms = os.Getenv("KAFKA_MECHANISM")
-	}
-	if os.Getenv("KAFKA_CLIENT_ID") !=  "" {
-		kafkaConfigurations.Clientid = os.Getenv("KAFKA_CLIENT_ID")
-	}
-	if os.Getenv("KAFKA_BROKER_1") !=  "" {
-		kafkaConfigurations.Brokers1 = os.Getenv("KAFKA_BROKER_1")
-	}
-	if os.Getenv("KAFKA_BROKER_2") !=  "" {
-		kafkaConfigurations.Brokers2 = os.Getenv("KAFKA_BROKER_2")
-	}
-	if os.Getenv("KAFKA_BROKER_3") !=  "" {
-		kafkaConfigurations.Brokers3 = os.Getenv("KAFKA_BROKER_3")
-	}
-	if os.Getenv("KAFKA_PARTITION") !=  "" {
-		intVar, _ := strconv.Atoi(os.Getenv("KAFKA_PARTITION"))
-		kafkaConfigurations.Partition = intVar
-	}
-	if os.Getenv("KAFKA_REPLICATION") !=  "" {
-		intVar, _ := strconv.Atoi(os.Getenv("KAFKA_REPLICATION"))
-		kafkaConfigurations.ReplicationFactor = intVar
-	}
-
-	list_topics := []string{}
-
-	if os.Getenv("TOPIC_CREDIT") !=  "" {
-		list_topics = append(list_topics, os.Getenv("TOPIC_CREDIT"))
-	}
-
-	if os.Getenv("TOPIC_DEBIT") !=  "" {
-		list_topics = append(list_topics, os.Getenv("TOPIC_DEBIT"))
-	}
-
-	if os.Getenv("TOPIC_TRANSFER") !=  "" {
-		list_topics = append(list_topics, os.Getenv("TOPIC_TRANSFER"))
-	}
-
-	return kafkaConfigurations, list_topics
-}
\ No newline at end of file
+package configuration
+
+import(
+	"os"
+	"strings"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+	go_core_event "github.com/eliezerraj/go-core/event/kafka" 
+)
+
+// About get kafka env var and the topic of each event type routed by TOPIC_<TYPE>, checked at the boot
+// (a routed type the service does not publish is refused). TOPIC_COMPLETION is the consumed topic, not a route
+func GetKafkaEnv() (go_core_event.KafkaConfigurations, model.EventRouting) {
+	childLogger.Info().Str("func","GetKafkaEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Send()
+	}
+
+	var kafkaConfigurations go_core_event.KafkaConfigurations
+
+	if os.Getenv("KAFKA_USER") !=  "" {
+		kafkaConfigurations.Username = os.Getenv("KAFKA_USER")
+	}
+	if os.Getenv("KAFKA_PASSWORD") !=  "" {
+		kafkaConfigurations.Password = os.Getenv("KAFKA_PASSWORD")
+	}
+	if os.Getenv("KAFKA_PROTOCOL") !=  "" {
+		kafkaConfigurations.Protocol = os.Getenv("KAFKA_PROTOCOL")
+	}
+	if os.Getenv("KAFKA_MECHANISM") !=  "" {
+		kafkaConfigurations.Mechanisms = os.Getenv("KAFKA_MECHANISM")
+	}
+	if os.Getenv("KAFKA_CLIENT_ID") !=  "" {
+		kafkaConfigurations.Clientid = os.Getenv("KAFKA_CLIENT_ID")
+	}
+	if os.Getenv("KAFKA_BROKER_1") !=  "" {
+		kafkaConfigurations.Brokers1 = os.Getenv("KAFKA_BROKER_1")
+	}
+	if os.Getenv("KAFKA_BROKER_2") !=  "" {
+		kafkaConfigurations.Brokers2 = os.Getenv("KAFKA_BROKER_2")
+	}
+	if os.Getenv("KAFKA_BROKER_3") !=  "" {
+		kafkaConfigurations.Brokers3 = os.Getenv("KAFKA_BROKER_3")
+	}
+	if os.Getenv("KAFKA_PARTITION") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("KAFKA_PARTITION"))
+		kafkaConfigurations.Partition = intVar
+	}
+	if os.Getenv("KAFKA_REPLICATION") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("KAFKA_REPLICATION"))
+		kafkaConfigurations.ReplicationFactor = intVar
+	}
+
+	// the topic of each routed event type (TOPIC_CREDIT, TOPIC_DEBIT, TOPIC_TRANSFER ...)
+	eventRouting := model.EventRouting{}
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(key, "TOPIC_") || key == "TOPIC_COMPLETION" {
+			continue
+		}
+		eventRouting[strings.TrimPrefix(key, "TOPIC_")] = strings.TrimSpace(value)
+	}
+
+	return kafkaConfigurations, eventRouting
+}
@@ -0,0 +1,50 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About get outbox env var, EVENT_MODE=outbox replaces the kafka transactions by the outbox table.
+// EVENT_PUBLISHER=memory keeps the events in memory instead of kafka (local run).
+// A number that does not parse is kept invalid, the config is refused at the boot
+func GetOutboxEnv() model.OutboxConfig {
+	childLogger.Info().Str("func","GetOutboxEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var outboxConfig model.OutboxConfig
+	outboxConfig.Enabled = false
+	outboxConfig.RelayInterval = 1
+	outboxConfig.BatchSize = 100
+	outboxConfig.Publisher = "kafka"
+
+	if os.Getenv("EVENT_MODE") == "outbox" {
+		outboxConfig.Enabled = true
+	}
+	if os.Getenv("EVENT_PUBLISHER") == "memory" {
+		outboxConfig.Publisher = "memory"
+	}
+	if os.Getenv("OUTBOX_RELAY_INTERVAL") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("OUTBOX_RELAY_INTERVAL"))
+		if err != nil {
+			intVar = 0
+		}
+		outboxConfig.RelayInterval = intVar
+	}
+	if os.Getenv("OUTBOX_BATCH_SIZE") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("OUTBOX_BATCH_SIZE"))
+		if err != nil {
+			intVar = 0
+		}
+		outboxConfig.BatchSize = intVar
+	}
+
+	return outboxConfig
+}
@@ -0,0 +1,53 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About get review env var. A number that does not parse is kept invalid, the config is refused at the boot
+func GetReviewEnv() model.ReviewConfig {
+	childLogger.Info().Str("func","GetReviewEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var reviewConfig model.ReviewConfig
+	reviewConfig.TTL = 86400
+	reviewConfig.ExpiryInterval = 60
+	reviewConfig.BatchSize = 100
+	reviewConfig.Role = "TRANSFER_REVIEWER"
+
+	if os.Getenv("REVIEW_TTL") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("REVIEW_TTL"))
+		if err != nil {
+			intVar = 0
+		}
+		reviewConfig.TTL = intVar
+	}
+	if os.Getenv("REVIEW_EXPIRY_INTERVAL") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("REVIEW_EXPIRY_INTERVAL"))
+		if err != nil {
+			intVar = 0
+		}
+		reviewConfig.ExpiryInterval = intVar
+	}
+	if os.Getenv("REVIEW_EXPIRY_BATCH_SIZE") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("REVIEW_EXPIRY_BATCH_SIZE"))
+		if err != nil {
+			intVar = 0
+		}
+		reviewConfig.BatchSize = intVar
+	}
+
+	if os.Getenv("REVIEW_ROLE") !=  "" {
+		reviewConfig.Role = os.Getenv("REVIEW_ROLE")
+	}
+
+	return reviewConfig
+}
@@ -0,0 +1,26 @@
+package configuration
+
+import(
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About get risk env var
+func GetRiskEnv() model.RiskConfig {
+	childLogger.Info().Str("func","GetRiskEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var riskConfig model.RiskConfig
+
+	if os.Getenv("RISK_RULE_FILE") !=  "" {
+		riskConfig.RuleFile = os.Getenv("RISK_RULE_FILE")
+	}
+
+	return riskConfig
+}
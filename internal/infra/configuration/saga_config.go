@@ -0,0 +1,40 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About get saga env var. A number that does not parse is kept invalid, the config is refused at the boot
+func GetSagaEnv() model.SagaConfig {
+	childLogger.Info().Str("func","GetSagaEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var sagaConfig model.SagaConfig
+	sagaConfig.RecoveryInterval = 60
+	sagaConfig.RecoveryAge = 300
+
+	if os.Getenv("SAGA_RECOVERY_INTERVAL") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("SAGA_RECOVERY_INTERVAL"))
+		if err != nil {
+			intVar = 0
+		}
+		sagaConfig.RecoveryInterval = intVar
+	}
+	if os.Getenv("SAGA_RECOVERY_AGE") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("SAGA_RECOVERY_AGE"))
+		if err != nil {
+			intVar = 0
+		}
+		sagaConfig.RecoveryAge = intVar
+	}
+
+	return sagaConfig
+}
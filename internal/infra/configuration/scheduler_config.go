@@ -0,0 +1,56 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-fund-transfer/internal/core/model"
+)
+
+// About get scheduler env var. A number that does not parse is kept invalid, the config is refused at the boot
+func GetSchedulerEnv() model.SchedulerConfig {
+	childLogger.Info().Str("func","GetSchedulerEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var schedulerConfig model.SchedulerConfig
+	schedulerConfig.Interval = 5
+	schedulerConfig.BatchSize = 10
+	schedulerConfig.MaxAttempts = 5
+	schedulerConfig.Lease = 300
+
+	if os.Getenv("SCHEDULER_INTERVAL") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("SCHEDULER_INTERVAL"))
+		if err != nil {
+			intVar = 0
+		}
+		schedulerConfig.Interval = intVar
+	}
+	if os.Getenv("SCHEDULER_BATCH_SIZE") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("SCHEDULER_BATCH_SIZE"))
+		if err != nil {
+			intVar = 0
+		}
+		schedulerConfig.BatchSize = intVar
+	}
+	if os.Getenv("SCHEDULER_MAX_ATTEMPTS") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("SCHEDULER_MAX_ATTEMPTS"))
+		if err != nil {
+			intVar = 0
+		}
+		schedulerConfig.MaxAttempts = intVar
+	}
+	if os.Getenv("SCHEDULER_LEASE") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("SCHEDULER_LEASE"))
+		if err != nil {
+			intVar = 0
+		}
+		schedulerConfig.Lease = intVar
+	}
+
+	return schedulerConfig
+}
@@ -73,6 +73,7 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 	// router
 	myRouter := mux.NewRouter().StrictSlash(true)
 	myRouter.Use(core_middleware.MiddleWareHandlerHeader)
+	myRouter.Use(api.IdentityMiddleware(appServer.IdentityConfig))
 
 	myRouter.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
 		childLogger.Info().Str("HandleFunc","/").Send()
@@ -97,25 +98,141 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 	})
 	
 	getTransfer := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-	getTransfer.HandleFunc("/get/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.GetTransfer))		
+	getTransfer.HandleFunc("/get/{id}", api.ProblemHandler(httpRouters.GetTransfer))		
 	getTransfer.Use(otelmux.Middleware("go-fund-transfer"))
 
 	addTransfer := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
-	addTransfer.HandleFunc("/add/transfer", core_middleware.MiddleWareErrorHandler(httpRouters.AddTransfer))		
+	addTransfer.HandleFunc("/add/transfer", api.ProblemHandler(httpRouters.AddTransfer))		
 	addTransfer.Use(otelmux.Middleware("go-fund-transfer"))
 
 	addTransferEvent := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
-	addTransferEvent.HandleFunc("/add/transferEvent", core_middleware.MiddleWareErrorHandler(httpRouters.AddTransferEvent))		
+	addTransferEvent.HandleFunc("/add/transferEvent", api.ProblemHandler(httpRouters.AddTransferEvent))		
 	addTransferEvent.Use(otelmux.Middleware("go-fund-transfer"))
 
 	creditTransferEvent := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
-	creditTransferEvent.HandleFunc("/creditTransferEvent", core_middleware.MiddleWareErrorHandler(httpRouters.CreditTransferEvent))		
+	creditTransferEvent.HandleFunc("/creditTransferEvent", api.ProblemHandler(httpRouters.CreditTransferEvent))		
 	creditTransferEvent.Use(otelmux.Middleware("go-fund-transfer"))
 
 	debitTransferEvent := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
-	debitTransferEvent.HandleFunc("/debitTransferEvent", core_middleware.MiddleWareErrorHandler(httpRouters.DebitTransferEvent))		
+	debitTransferEvent.HandleFunc("/debitTransferEvent", api.ProblemHandler(httpRouters.DebitTransferEvent))		
 	debitTransferEvent.Use(otelmux.Middleware("go-fund-transfer"))
 
+	getTransferByTransactionID := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getTransferByTransactionID.HandleFunc("/transfer/transaction/{transaction_id}", api.ProblemHandler(httpRouters.GetTransferByTransactionID))		
+	getTransferByTransactionID.Use(otelmux.Middleware("go-fund-transfer"))
+
+	listTransfer := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listTransfer.HandleFunc("/transfers", api.ProblemHandler(httpRouters.ListTransfer))		
+	listTransfer.Use(otelmux.Middleware("go-fund-transfer"))
+
+	updateTransferStatus := myRouter.Methods(http.MethodPatch, http.MethodOptions).Subrouter()
+	updateTransferStatus.HandleFunc("/transfer/{id}/status", api.ProblemHandler(httpRouters.UpdateTransferStatus))		
+	updateTransferStatus.Use(otelmux.Middleware("go-fund-transfer"))
+
+	listTransferStatus := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listTransferStatus.HandleFunc("/transfer/{id}/status", api.ProblemHandler(httpRouters.ListTransferStatus))		
+	listTransferStatus.Use(otelmux.Middleware("go-fund-transfer"))
+
+	addCreditSchedule := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addCreditSchedule.HandleFunc("/creditFundSchedule", api.ProblemHandler(httpRouters.AddCreditSchedule))		
+	addCreditSchedule.Use(otelmux.Middleware("go-fund-transfer"))
+
+	addDebitSchedule := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addDebitSchedule.HandleFunc("/debitFundSchedule", api.ProblemHandler(httpRouters.AddDebitSchedule))		
+	addDebitSchedule.Use(otelmux.Middleware("go-fund-transfer"))
+
+	addTransferSchedule := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addTransferSchedule.HandleFunc("/transferSchedule", api.ProblemHandler(httpRouters.AddTransferSchedule))		
+	addTransferSchedule.Use(otelmux.Middleware("go-fund-transfer"))
+
+	listSchedule := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listSchedule.HandleFunc("/schedules", api.ProblemHandler(httpRouters.ListSchedule))		
+	listSchedule.Use(otelmux.Middleware("go-fund-transfer"))
+
+	cancelSchedule := myRouter.Methods(http.MethodDelete, http.MethodOptions).Subrouter()
+	cancelSchedule.HandleFunc("/schedule/{id}", api.ProblemHandler(httpRouters.CancelSchedule))		
+	cancelSchedule.Use(otelmux.Middleware("go-fund-transfer"))
+
+	addStandingOrder := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addStandingOrder.HandleFunc("/standingOrder", api.ProblemHandler(httpRouters.AddStandingOrder))		
+	addStandingOrder.Use(otelmux.Middleware("go-fund-transfer"))
+
+	getStandingOrder := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getStandingOrder.HandleFunc("/standingOrder/{id}", api.ProblemHandler(httpRouters.GetStandingOrder))		
+	getStandingOrder.Use(otelmux.Middleware("go-fund-transfer"))
+
+	listStandingOrder := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listStandingOrder.HandleFunc("/standingOrders", api.ProblemHandler(httpRouters.ListStandingOrder))		
+	listStandingOrder.Use(otelmux.Middleware("go-fund-transfer"))
+
+	cancelStandingOrder := myRouter.Methods(http.MethodDelete, http.MethodOptions).Subrouter()
+	cancelStandingOrder.HandleFunc("/standingOrder/{id}", api.ProblemHandler(httpRouters.CancelStandingOrder))		
+	cancelStandingOrder.Use(otelmux.Middleware("go-fund-transfer"))
+
+	addTransferBatch := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addTransferBatch.HandleFunc("/transfers/batch", api.ProblemHandler(httpRouters.AddTransferBatch))		
+	addTransferBatch.Use(otelmux.Middleware("go-fund-transfer"))
+
+	getTransferBatch := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getTransferBatch.HandleFunc("/transfers/batch/{id}", api.ProblemHandler(httpRouters.GetTransferBatch))		
+	getTransferBatch.Use(otelmux.Middleware("go-fund-transfer"))
+
+	addQuote := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addQuote.HandleFunc("/quotes", api.ProblemHandler(httpRouters.AddQuote))		
+	addQuote.Use(otelmux.Middleware("go-fund-transfer"))
+
+	setTransferLimit := myRouter.Methods(http.MethodPut, http.MethodOptions).Subrouter()
+	setTransferLimit.HandleFunc("/limit", api.ProblemHandler(httpRouters.SetTransferLimit))		
+	setTransferLimit.Use(otelmux.Middleware("go-fund-transfer"))
+
+	listTransferLimit := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listTransferLimit.HandleFunc("/limits/{scope}/{id}", api.ProblemHandler(httpRouters.ListTransferLimit))		
+	listTransferLimit.Use(otelmux.Middleware("go-fund-transfer"))
+
+	deleteTransferLimit := myRouter.Methods(http.MethodDelete, http.MethodOptions).Subrouter()
+	deleteTransferLimit.HandleFunc("/limit/{scope}/{id}/{currency}", api.ProblemHandler(httpRouters.DeleteTransferLimit))		
+	deleteTransferLimit.Use(otelmux.Middleware("go-fund-transfer"))
+
+	getRiskDecision := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getRiskDecision.HandleFunc("/risk/{id}", api.ProblemHandler(httpRouters.GetRiskDecision))		
+	getRiskDecision.Use(otelmux.Middleware("go-fund-transfer"))
+
+	listRiskDecision := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listRiskDecision.HandleFunc("/risks", api.ProblemHandler(httpRouters.ListRiskDecision))		
+	listRiskDecision.Use(otelmux.Middleware("go-fund-transfer"))
+
+	listTransferReview := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listTransferReview.HandleFunc("/reviews", api.ProblemHandler(httpRouters.ListTransferReview))		
+	listTransferReview.Use(otelmux.Middleware("go-fund-transfer"))
+
+	getTransferReview := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getTransferReview.HandleFunc("/review/{id}", api.ProblemHandler(httpRouters.GetTransferReview))		
+	getTransferReview.Use(otelmux.Middleware("go-fund-transfer"))
+
+	approveReview := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	approveReview.HandleFunc("/review/{id}/approve", api.ProblemHandler(httpRouters.ApproveReview))		
+	approveReview.Use(otelmux.Middleware("go-fund-transfer"))
+
+	rejectReview := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	rejectReview.HandleFunc("/review/{id}/reject", api.ProblemHandler(httpRouters.RejectReview))		
+	rejectReview.Use(otelmux.Middleware("go-fund-transfer"))
+
+	listTransferApproval := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listTransferApproval.HandleFunc("/approvals", api.ProblemHandler(httpRouters.ListTransferApproval))		
+	listTransferApproval.Use(otelmux.Middleware("go-fund-transfer"))
+
+	approveTransfer := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	approveTransfer.HandleFunc("/transfer/{id}/approve", api.ProblemHandler(httpRouters.ApproveTransfer))		
+	approveTransfer.Use(otelmux.Middleware("go-fund-transfer"))
+
+	rejectTransfer := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	rejectTransfer.HandleFunc("/transfer/{id}/reject", api.ProblemHandler(httpRouters.RejectTransfer))		
+	rejectTransfer.Use(otelmux.Middleware("go-fund-transfer"))
+
+	listDependencyStatus := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listDependencyStatus.HandleFunc("/status/dependencies", api.ProblemHandler(httpRouters.ListDependencyStatus))		
+	listDependencyStatus.Use(otelmux.Middleware("go-fund-transfer"))
+
 	// setup http server
 	srv := http.Server{
 		Addr:         ":" +  strconv.Itoa(h.httpServer.Port),      	